@@ -0,0 +1,270 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/panaroid/domain-gateway/internal/api"
+	"github.com/panaroid/domain-gateway/internal/auth"
+	"github.com/panaroid/domain-gateway/internal/caddy"
+	"github.com/panaroid/domain-gateway/internal/cluster"
+	"github.com/panaroid/domain-gateway/internal/config"
+	"github.com/panaroid/domain-gateway/internal/database"
+	"github.com/panaroid/domain-gateway/internal/dns"
+	"github.com/panaroid/domain-gateway/internal/events"
+	"github.com/panaroid/domain-gateway/internal/resolver"
+	"github.com/panaroid/domain-gateway/internal/webhooks"
+	"github.com/panaroid/domain-gateway/internal/worker"
+)
+
+const usage = `Usage:
+  gateway [serve]               run the gateway (applies pending migrations first)
+  gateway migrate up [VERSION]  apply pending migrations, up to VERSION if given
+  gateway migrate down [STEPS]  roll back the last STEPS migrations (default 1)
+  gateway migrate status        list migrations and when they were applied
+`
+
+func main() {
+	logger, err := zap.NewProduction()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to create logger: %v\n", err)
+		os.Exit(1)
+	}
+	defer logger.Sync()
+
+	cfg, err := config.Load(logger)
+	if err != nil {
+		logger.Fatal("Failed to load configuration", zap.Error(err))
+	}
+
+	args := os.Args[1:]
+	command := "serve"
+	if len(args) > 0 {
+		command, args = args[0], args[1:]
+	}
+
+	switch command {
+	case "serve":
+		err = serve(cfg, logger)
+	case "migrate":
+		err = migrate(cfg, logger, args)
+	case "help", "-h", "--help":
+		fmt.Print(usage)
+		return
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		logger.Fatal("Command failed", zap.String("command", command), zap.Error(err))
+	}
+}
+
+// migrate runs the migrate subcommand
+func migrate(cfg *config.Config, logger *zap.Logger, args []string) error {
+	if len(args) == 0 || len(args) > 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	number := 0
+	if len(args) == 2 {
+		n, err := strconv.Atoi(args[1])
+		if err != nil || n < 0 {
+			return fmt.Errorf("invalid number %q", args[1])
+		}
+		number = n
+	}
+
+	db, err := database.New(cfg.Database, logger)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	switch args[0] {
+	case "up":
+		return db.MigrateUp(ctx, number)
+	case "down":
+		if len(args) == 1 {
+			number = 1
+		}
+		return db.MigrateDown(ctx, number)
+	case "status":
+		statuses, err := db.MigrationStatus(ctx)
+		if err != nil {
+			return err
+		}
+		for _, s := range statuses {
+			applied := "pending"
+			if s.AppliedAt != nil {
+				applied = s.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Printf("%04d  %-30s  %s\n", s.Version, s.Name, applied)
+		}
+		return nil
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	return nil
+}
+
+// serve wires the gateway together and runs it until interrupted
+func serve(cfg *config.Config, logger *zap.Logger) error {
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	db, err := database.New(cfg.Database, logger)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if err := db.RunMigrations(); err != nil {
+		return err
+	}
+
+	repo := database.NewDomainRepository(db)
+	settingsRepo := database.NewSettingsRepository(db)
+	apiKeyRepo := database.NewAPIKeyRepository(db)
+	auditRepo := database.NewAccessAuditRepository(db)
+	eventRepo := database.NewEventRepository(db)
+	webhookRepo := database.NewWebhookRepository(db)
+	certRepo := database.NewCertificateRepository(db)
+	changeRepo := database.NewChangeRepository(db)
+	transferRepo := database.NewTransferRepository(db)
+	contestRepo := database.NewContestRepository(db)
+
+	broker := events.NewBroker(logger)
+	db.OnDomainEvent(broker.Publish)
+
+	keys, err := auth.NewKeySet(cfg.JWT, logger)
+	if err != nil {
+		return err
+	}
+	keys.Start(ctx)
+
+	verifier := dns.NewVerifier(logger)
+	caddyManager := caddy.NewManager(cfg.Caddy, cfg.DNS, logger)
+	hostResolver := resolver.NewResolver(repo, cfg.Resolver, logger)
+
+	// A SQLite database serves a single instance, so there are no changes
+	// to follow and no leader to elect
+	var leader worker.Leadership
+	if db.Dialect() == database.DialectPostgres {
+		// Load the current state before listening so no change is missed
+		listener := cluster.NewListener(db, changeRepo, repo, settingsRepo, caddyManager, hostResolver, broker, cfg.Database, logger)
+		if err := listener.Start(ctx); err != nil {
+			return fmt.Errorf("failed to start change listener: %w", err)
+		}
+		defer listener.Stop()
+
+		elector := cluster.NewElector(db, "domain-gateway-worker", cfg.Worker.LeaderRetryInterval, logger)
+		elector.Start(ctx)
+		defer elector.Stop()
+		leader = elector
+	}
+
+	if err := loadCaddy(ctx, repo, settingsRepo, caddyManager, hostResolver); err != nil {
+		logger.Error("Failed to load initial Caddy configuration", zap.Error(err))
+	}
+
+	verificationWorker := worker.NewVerificationWorker(repo, verifier, caddyManager, hostResolver, leader, cfg.Worker, logger)
+	verificationWorker.Start(ctx)
+	defer verificationWorker.Stop()
+
+	certMonitor := worker.NewCertificateMonitor(repo, certRepo, logger, cfg.Worker.CertCheckInterval, cfg.Worker.CertExpiryWarning, leader)
+	certMonitor.Start(ctx)
+	defer certMonitor.Stop()
+
+	trashPurger := worker.NewTrashPurger(repo, logger, cfg.Worker.TrashPurgeInterval, cfg.Worker.TrashRetention, leader)
+	trashPurger.Start(ctx)
+	defer trashPurger.Stop()
+
+	dispatcher := webhooks.NewDispatcher(webhookRepo, cfg.Webhook, logger)
+	dispatcher.Start(ctx)
+	defer dispatcher.Stop()
+
+	middleware := api.NewMiddleware(cfg.JWT, cfg.Server, keys, apiKeyRepo, auditRepo, logger)
+	handler := api.NewHandler(
+		repo, settingsRepo, apiKeyRepo, auditRepo, eventRepo, webhookRepo, transferRepo, contestRepo,
+		broker, verifier, caddyManager, verificationWorker, hostResolver, cfg.Caddy, logger,
+	)
+
+	server := &http.Server{
+		Addr:              fmt.Sprintf(":%d", cfg.Server.APIPort),
+		Handler:           api.NewRouter(handler, middleware, logger).Setup(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	// Event streams only end with their subscription; Shutdown waits for them
+	server.RegisterOnShutdown(broker.Close)
+
+	errCh := make(chan error, 1)
+	go func() {
+		logger.Info("API server listening", zap.String("addr", server.Addr))
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			errCh <- err
+		}
+	}()
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+
+	select {
+	case sig := <-sigCh:
+		logger.Info("Shutting down", zap.String("signal", sig.String()))
+	case err := <-errCh:
+		return err
+	}
+
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer shutdownCancel()
+	return server.Shutdown(shutdownCtx)
+}
+
+// loadCaddy pushes the full route configuration for all verified domains
+func loadCaddy(
+	ctx context.Context,
+	repo database.DomainStore,
+	settingsRepo database.SettingsStore,
+	caddyManager *caddy.Manager,
+	hostResolver *resolver.Resolver,
+) error {
+	domains, err := repo.GetAllVerified(ctx)
+	if err != nil {
+		return err
+	}
+
+	tenants, err := settingsRepo.ListTenants(ctx)
+	if err != nil {
+		return err
+	}
+
+	global, err := settingsRepo.GetGlobal(ctx)
+	if err != nil {
+		return err
+	}
+
+	caddyManager.LoadSettings(tenants, global)
+	if err := caddyManager.LoadConfig(ctx, caddyManager.BuildConfig(domains)); err != nil {
+		return err
+	}
+
+	hostResolver.Warm(domains)
+	return nil
+}
@@ -0,0 +1,316 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/panaroid/domain-gateway/internal/database"
+	"github.com/panaroid/domain-gateway/pkg/models"
+)
+
+// SuspendTenant handles POST /api/admin/tenants/{id}/suspend
+func (h *Handler) SuspendTenant(w http.ResponseWriter, r *http.Request) {
+	var req models.SuspendTenantRequest
+	if r.ContentLength > 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			h.sendError(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+			return
+		}
+	}
+
+	h.setTenantSuspended(w, r, true, req.Reason)
+}
+
+// UnsuspendTenant handles POST /api/admin/tenants/{id}/unsuspend
+func (h *Handler) UnsuspendTenant(w http.ResponseWriter, r *http.Request) {
+	h.setTenantSuspended(w, r, false, "")
+}
+
+func (h *Handler) setTenantSuspended(w http.ResponseWriter, r *http.Request, suspended bool, reason string) {
+	tenantID := r.PathValue("id")
+	if tenantID == "" {
+		h.sendError(w, http.StatusBadRequest, "invalid_id", "Tenant ID is required")
+		return
+	}
+
+	domains, err := h.repo.SetTenantSuspended(r.Context(), tenantID, suspended, reason)
+	if err != nil {
+		h.logger.Error("Failed to update tenant suspension", zap.Error(err))
+		h.sendError(w, http.StatusInternalServerError, "internal_error", "Failed to update tenant suspension")
+		return
+	}
+
+	// Verified domains keep their verification; only the route handler is swapped
+	if err := h.caddyManager.SetTenantSuspended(r.Context(), tenantID, suspended); err != nil {
+		h.logger.Warn("Failed to update tenant routes in Caddy", zap.Error(err))
+	}
+
+	h.resolver.InvalidateTenant(tenantID)
+
+	h.logger.Info("Tenant suspension updated",
+		zap.String("tenant_id", tenantID),
+		zap.Bool("suspended", suspended),
+		zap.Int("domains", len(domains)),
+		zap.String("admin_id", GetUserID(r.Context())),
+	)
+
+	h.sendJSON(w, http.StatusOK, models.SuspendTenantResponse{
+		TenantID:  tenantID,
+		Suspended: suspended,
+		Domains:   len(domains),
+	})
+}
+
+// ListAllDomains handles GET /api/admin/domains
+func (h *Handler) ListAllDomains(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	search := strings.ToLower(strings.TrimSpace(query.Get("q")))
+	tenantID := query.Get("tenant_id")
+	limit, offset := pagination(r)
+
+	domains, total, err := h.repo.Search(r.Context(), search, tenantID, limit, offset)
+	if err != nil {
+		h.logger.Error("Failed to search domains", zap.Error(err))
+		h.sendError(w, http.StatusInternalServerError, "internal_error", "Failed to list domains")
+		return
+	}
+
+	if domains == nil {
+		domains = []models.Domain{}
+	}
+
+	h.sendJSON(w, http.StatusOK, models.DomainListResponse{
+		Domains: domains,
+		Total:   total,
+	})
+}
+
+// ForceVerifyDomain handles POST /api/admin/domains/{id}/verify
+func (h *Handler) ForceVerifyDomain(w http.ResponseWriter, r *http.Request) {
+	domain, ok := h.adminLoadDomain(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.repo.MarkVerified(r.Context(), domain.ID); err != nil {
+		if errors.Is(err, database.ErrInvalidTransition) {
+			h.sendError(w, http.StatusConflict, "invalid_status", fmt.Sprintf("Domain cannot be verified while %s", domain.Status))
+			return
+		}
+		h.logger.Error("Failed to force-verify domain", zap.Error(err))
+		h.sendError(w, http.StatusInternalServerError, "internal_error", "Failed to verify domain")
+		return
+	}
+
+	domain.SetStatus(models.StatusCertPending, "")
+	if err := h.caddyManager.AddDomain(r.Context(), domain); err != nil {
+		h.logger.Warn("Failed to add domain to Caddy", zap.Error(err))
+	}
+	if err := h.repo.MarkSSLIssued(r.Context(), domain.ID); err != nil {
+		h.logger.Warn("Failed to mark SSL as issued", zap.Error(err))
+	}
+
+	h.resolver.Invalidate(domain.Domain)
+
+	h.logger.Info("Domain force-verified",
+		zap.String("domain", domain.Domain),
+		zap.String("admin_id", GetUserID(r.Context())),
+	)
+
+	h.sendJSON(w, http.StatusOK, models.VerifyDomainResponse{
+		Verified: true,
+		Message:  "Domain force-verified",
+	})
+}
+
+// ForceUnverifyDomain handles POST /api/admin/domains/{id}/unverify
+func (h *Handler) ForceUnverifyDomain(w http.ResponseWriter, r *http.Request) {
+	domain, ok := h.adminLoadDomain(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.repo.MarkUnverified(r.Context(), domain.ID); err != nil {
+		if errors.Is(err, database.ErrInvalidTransition) {
+			h.sendError(w, http.StatusConflict, "invalid_status", fmt.Sprintf("Domain cannot be unverified while %s", domain.Status))
+			return
+		}
+		h.logger.Error("Failed to force-unverify domain", zap.Error(err))
+		h.sendError(w, http.StatusInternalServerError, "internal_error", "Failed to unverify domain")
+		return
+	}
+
+	if domain.Verified {
+		if err := h.caddyManager.RemoveDomain(r.Context(), domain.ID); err != nil {
+			h.logger.Warn("Failed to remove domain from Caddy", zap.Error(err))
+		}
+	}
+
+	h.resolver.InvalidateTenant(domain.TenantID)
+	h.resolver.Invalidate(domain.Domain)
+
+	h.logger.Info("Domain force-unverified",
+		zap.String("domain", domain.Domain),
+		zap.String("admin_id", GetUserID(r.Context())),
+	)
+
+	h.sendJSON(w, http.StatusOK, models.VerifyDomainResponse{
+		Verified: false,
+		Message:  "Domain verification cleared",
+	})
+}
+
+// ReassignDomain handles POST /api/admin/domains/{id}/reassign
+func (h *Handler) ReassignDomain(w http.ResponseWriter, r *http.Request) {
+	var req models.ReassignDomainRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendError(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		return
+	}
+	if _, err := uuid.Parse(req.TenantID); err != nil {
+		h.sendError(w, http.StatusBadRequest, "invalid_tenant", "A valid tenant ID is required")
+		return
+	}
+
+	domain, ok := h.adminLoadDomain(w, r)
+	if !ok {
+		return
+	}
+
+	previousTenantID := domain.TenantID
+
+	if err := h.repo.Reassign(r.Context(), domain.ID, req.TenantID); err != nil {
+		h.logger.Error("Failed to reassign domain", zap.Error(err))
+		h.sendError(w, http.StatusInternalServerError, "internal_error", "Failed to reassign domain")
+		return
+	}
+
+	updated, err := h.repo.GetByID(r.Context(), domain.ID)
+	if err != nil || updated == nil {
+		h.logger.Error("Failed to reload domain", zap.Error(err))
+		h.sendError(w, http.StatusInternalServerError, "internal_error", "Failed to reassign domain")
+		return
+	}
+
+	// Re-render the route so the backend sees the new tenant identity
+	if updated.Verified {
+		if err := h.caddyManager.AddDomain(r.Context(), updated); err != nil {
+			h.logger.Warn("Failed to update domain route in Caddy", zap.Error(err))
+		}
+	}
+
+	if err := h.caddyManager.ClearPrimaryDomain(r.Context(), previousTenantID, updated.Domain); err != nil {
+		h.logger.Warn("Failed to clear primary domain in Caddy", zap.Error(err))
+	}
+
+	h.resolver.InvalidateTenant(previousTenantID)
+	h.resolver.InvalidateTenant(updated.TenantID)
+	h.resolver.Invalidate(updated.Domain)
+
+	h.logger.Info("Domain reassigned",
+		zap.String("domain", updated.Domain),
+		zap.String("from_tenant_id", previousTenantID),
+		zap.String("to_tenant_id", updated.TenantID),
+		zap.String("admin_id", GetUserID(r.Context())),
+	)
+
+	h.sendJSON(w, http.StatusOK, updated)
+}
+
+// ResyncCaddy handles POST /api/admin/caddy/resync
+func (h *Handler) ResyncCaddy(w http.ResponseWriter, r *http.Request) {
+	routes, err := h.resyncCaddy(r.Context())
+	if err != nil {
+		h.logger.Error("Caddy resync failed", zap.Error(err))
+		h.sendError(w, http.StatusBadGateway, "resync_failed", "Failed to resync Caddy configuration")
+		return
+	}
+
+	h.logger.Info("Caddy configuration resynced",
+		zap.Int("routes", routes),
+		zap.String("admin_id", GetUserID(r.Context())),
+	)
+
+	h.sendJSON(w, http.StatusOK, models.ResyncResponse{Routes: routes})
+}
+
+// WorkerStatus handles GET /api/admin/worker
+func (h *Handler) WorkerStatus(w http.ResponseWriter, r *http.Request) {
+	h.sendJSON(w, http.StatusOK, h.worker.Status())
+}
+
+// ListAccessAudit handles GET /api/admin/access-audit
+func (h *Handler) ListAccessAudit(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	limit, offset := pagination(r)
+
+	entries, err := h.auditRepo.List(r.Context(), query.Get("tenant_id"), query.Get("user_id"), limit, offset)
+	if err != nil {
+		h.logger.Error("Failed to list access audit entries", zap.Error(err))
+		h.sendError(w, http.StatusInternalServerError, "internal_error", "Failed to list access audit entries")
+		return
+	}
+
+	if entries == nil {
+		entries = []models.AccessAuditEntry{}
+	}
+
+	h.sendJSON(w, http.StatusOK, models.AccessAuditListResponse{Entries: entries})
+}
+
+// resyncCaddy rebuilds the full Caddy configuration from the database
+func (h *Handler) resyncCaddy(ctx context.Context) (int, error) {
+	domains, err := h.repo.GetAllVerified(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	tenants, err := h.settingsRepo.ListTenants(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	global, err := h.settingsRepo.GetGlobal(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	h.caddyManager.LoadSettings(tenants, global)
+	if err := h.caddyManager.LoadConfig(ctx, h.caddyManager.BuildConfig(domains)); err != nil {
+		return 0, err
+	}
+
+	h.resolver.Warm(domains)
+
+	return h.caddyManager.GetRouteCount(), nil
+}
+
+// adminLoadDomain loads the domain named by the {id} path value without tenant scoping
+func (h *Handler) adminLoadDomain(w http.ResponseWriter, r *http.Request) (*models.Domain, bool) {
+	id := r.PathValue("id")
+	if id == "" {
+		h.sendError(w, http.StatusBadRequest, "invalid_id", "Domain ID is required")
+		return nil, false
+	}
+
+	domain, err := h.repo.GetByID(r.Context(), id)
+	if err != nil {
+		h.logger.Error("Failed to get domain", zap.Error(err))
+		h.sendError(w, http.StatusInternalServerError, "internal_error", "Failed to get domain")
+		return nil, false
+	}
+
+	if domain == nil {
+		h.sendError(w, http.StatusNotFound, "not_found", "Domain not found")
+		return nil, false
+	}
+
+	return domain, true
+}
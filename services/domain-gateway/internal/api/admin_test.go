@@ -0,0 +1,24 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestReassignDomainRequiresValidTenant(t *testing.T) {
+	h := &Handler{logger: zap.NewNop()}
+
+	for _, body := range []string{`{}`, `{"tenant_id":""}`, `{"tenant_id":"not-a-uuid"}`} {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/api/admin/domains/1/reassign", strings.NewReader(body))
+		h.ReassignDomain(rec, req)
+
+		if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "invalid_tenant") {
+			t.Errorf("%s: status = %d, body = %s; want 400 invalid_tenant", body, rec.Code, rec.Body.String())
+		}
+	}
+}
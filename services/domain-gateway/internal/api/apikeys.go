@@ -0,0 +1,151 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/panaroid/domain-gateway/internal/auth"
+	"github.com/panaroid/domain-gateway/internal/database"
+	"github.com/panaroid/domain-gateway/pkg/models"
+)
+
+// CreateAPIKey handles POST /api/keys
+func (h *Handler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateAPIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendError(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		return
+	}
+
+	tenantID := GetTenantID(r.Context())
+	if tenantID == "" {
+		h.sendError(w, http.StatusUnauthorized, "unauthorized", "Tenant ID not found")
+		return
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		h.sendError(w, http.StatusBadRequest, "invalid_name", "Name is required")
+		return
+	}
+
+	if len(req.Scopes) == 0 {
+		h.sendError(w, http.StatusBadRequest, "invalid_scopes", "At least one scope is required")
+		return
+	}
+	for _, scope := range req.Scopes {
+		if !hasScope(models.APIKeyScopes, scope) {
+			h.sendError(w, http.StatusBadRequest, "invalid_scopes", "Unknown scope: "+scope)
+			return
+		}
+		// Keys cannot grant more than the creating user's role allows
+		for _, perm := range scopePermissions[scope] {
+			if !roleAllows(GetRole(r.Context()), perm) {
+				h.sendErrorDetails(w, http.StatusForbidden, "forbidden", "Missing permission: "+string(perm), string(perm))
+				return
+			}
+		}
+	}
+
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		h.sendError(w, http.StatusBadRequest, "invalid_expiry", "Expiry must be in the future")
+		return
+	}
+
+	plaintext, prefix, hash, err := auth.GenerateAPIKey()
+	if err != nil {
+		h.logger.Error("Failed to generate API key", zap.Error(err))
+		h.sendError(w, http.StatusInternalServerError, "internal_error", "Failed to create API key")
+		return
+	}
+
+	key := &models.APIKey{
+		TenantID:  tenantID,
+		Name:      req.Name,
+		Prefix:    prefix,
+		KeyHash:   hash,
+		Scopes:    req.Scopes,
+		CreatedBy: GetUserID(r.Context()),
+		ExpiresAt: req.ExpiresAt,
+	}
+
+	if err := h.apiKeyRepo.Create(r.Context(), key); err != nil {
+		h.logger.Error("Failed to create API key", zap.Error(err))
+		h.sendError(w, http.StatusInternalServerError, "internal_error", "Failed to create API key")
+		return
+	}
+
+	h.logger.Info("API key created",
+		zap.String("key_id", key.ID),
+		zap.String("prefix", key.Prefix),
+		zap.String("tenant_id", tenantID),
+		zap.Strings("scopes", key.Scopes),
+	)
+
+	h.sendJSON(w, http.StatusCreated, models.CreateAPIKeyResponse{
+		APIKey: key,
+		Key:    plaintext,
+	})
+}
+
+// ListAPIKeys handles GET /api/keys
+func (h *Handler) ListAPIKeys(w http.ResponseWriter, r *http.Request) {
+	tenantID := GetTenantID(r.Context())
+	if tenantID == "" {
+		h.sendError(w, http.StatusUnauthorized, "unauthorized", "Tenant ID not found")
+		return
+	}
+
+	keys, err := h.apiKeyRepo.ListByTenant(r.Context(), tenantID)
+	if err != nil {
+		h.logger.Error("Failed to list API keys", zap.Error(err))
+		h.sendError(w, http.StatusInternalServerError, "internal_error", "Failed to list API keys")
+		return
+	}
+
+	if keys == nil {
+		keys = []models.APIKey{}
+	}
+
+	h.sendJSON(w, http.StatusOK, models.APIKeyListResponse{
+		Keys:  keys,
+		Total: len(keys),
+	})
+}
+
+// RevokeAPIKey handles DELETE /api/keys/{id}
+func (h *Handler) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if id == "" {
+		h.sendError(w, http.StatusBadRequest, "invalid_id", "API key ID is required")
+		return
+	}
+
+	tenantID := GetTenantID(r.Context())
+	if tenantID == "" {
+		h.sendError(w, http.StatusUnauthorized, "unauthorized", "Tenant ID not found")
+		return
+	}
+
+	if err := h.apiKeyRepo.Revoke(r.Context(), tenantID, id); err != nil {
+		if errors.Is(err, database.ErrAPIKeyNotFound) {
+			h.sendError(w, http.StatusNotFound, "not_found", "API key not found")
+			return
+		}
+		h.logger.Error("Failed to revoke API key", zap.Error(err))
+		h.sendError(w, http.StatusInternalServerError, "internal_error", "Failed to revoke API key")
+		return
+	}
+
+	h.logger.Info("API key revoked",
+		zap.String("key_id", id),
+		zap.String("tenant_id", tenantID),
+	)
+
+	w.WriteHeader(http.StatusNoContent)
+}
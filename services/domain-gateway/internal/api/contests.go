@@ -0,0 +1,275 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/panaroid/domain-gateway/internal/database"
+	"github.com/panaroid/domain-gateway/pkg/models"
+)
+
+// CreateContest handles POST /api/contests. It challenges another tenant's
+// unverified claim on a hostname.
+func (h *Handler) CreateContest(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateContestRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendError(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		return
+	}
+
+	req.Domain = strings.ToLower(strings.TrimSpace(req.Domain))
+	if req.Domain == "" {
+		h.sendError(w, http.StatusBadRequest, "invalid_domain", "Domain is required")
+		return
+	}
+
+	tenantID := GetTenantID(r.Context())
+	if !h.tenantActive(w, r, tenantID, "Failed to create contest") {
+		return
+	}
+
+	existing, err := h.repo.GetByDomain(r.Context(), req.Domain)
+	if err != nil {
+		h.logger.Error("Failed to get domain", zap.Error(err))
+		h.sendError(w, http.StatusInternalServerError, "internal_error", "Failed to create contest")
+		return
+	}
+	if existing == nil {
+		h.sendError(w, http.StatusNotFound, "not_found", "Domain is not claimed; add it instead")
+		return
+	}
+	if existing.TenantID == tenantID {
+		h.sendError(w, http.StatusConflict, "domain_exists", "Domain already belongs to this tenant")
+		return
+	}
+	if !existing.Contestable() {
+		h.sendError(w, http.StatusConflict, "domain_locked", "Domain is verified by another tenant")
+		return
+	}
+
+	token, err := h.verifier.GenerateChallenge()
+	if err != nil {
+		h.logger.Error("Failed to generate challenge", zap.Error(err))
+		h.sendError(w, http.StatusInternalServerError, "internal_error", "Failed to create contest")
+		return
+	}
+
+	contest := &models.DomainContest{
+		Domain:         req.Domain,
+		TenantID:       tenantID,
+		ChallengeToken: token,
+	}
+	err = h.contestRepo.Create(r.Context(), contest)
+	if errors.Is(err, database.ErrDomainNotFound) {
+		h.sendError(w, http.StatusNotFound, "not_found", "Domain is not claimed; add it instead")
+		return
+	}
+	if errors.Is(err, database.ErrDomainLocked) {
+		h.sendError(w, http.StatusConflict, "domain_locked", "Domain is verified by another tenant")
+		return
+	}
+	if errors.Is(err, database.ErrContestExists) {
+		h.sendError(w, http.StatusConflict, "contest_exists", "Tenant already contests this domain")
+		return
+	}
+	if err != nil {
+		h.logger.Error("Failed to create contest", zap.Error(err))
+		h.sendError(w, http.StatusInternalServerError, "internal_error", "Failed to create contest")
+		return
+	}
+
+	h.logger.Info("Domain claim contested",
+		zap.String("domain", contest.Domain),
+		zap.String("tenant_id", contest.TenantID),
+		zap.String("holder_tenant_id", contest.HolderTenantID),
+	)
+
+	h.sendJSON(w, http.StatusCreated, h.contestResponse(r, contest))
+}
+
+// ListContests handles GET /api/contests
+func (h *Handler) ListContests(w http.ResponseWriter, r *http.Request) {
+	tenantID := GetTenantID(r.Context())
+	if tenantID == "" {
+		h.sendError(w, http.StatusUnauthorized, "unauthorized", "Tenant ID not found")
+		return
+	}
+
+	contests, err := h.contestRepo.ListByTenant(r.Context(), tenantID)
+	if err != nil {
+		h.logger.Error("Failed to list contests", zap.Error(err))
+		h.sendError(w, http.StatusInternalServerError, "internal_error", "Failed to list contests")
+		return
+	}
+	if contests == nil {
+		contests = []models.DomainContest{}
+	}
+
+	h.sendJSON(w, http.StatusOK, models.ContestListResponse{Contests: contests})
+}
+
+// GetContest handles GET /api/contests/{id}. The challenging tenant also gets
+// the DNS challenge while the contest is open.
+func (h *Handler) GetContest(w http.ResponseWriter, r *http.Request) {
+	contest, ok := h.loadContest(w, r)
+	if !ok {
+		return
+	}
+
+	h.sendJSON(w, http.StatusOK, h.contestResponse(r, contest))
+}
+
+// ResolveContest handles POST /api/contests/{id}/resolve
+func (h *Handler) ResolveContest(w http.ResponseWriter, r *http.Request) {
+	contest, ok := h.loadContest(w, r)
+	if !ok {
+		return
+	}
+
+	tenantID := GetTenantID(r.Context())
+	if contest.TenantID != tenantID {
+		h.sendError(w, http.StatusForbidden, "forbidden", "Only the challenging tenant can resolve a contest")
+		return
+	}
+	if !contest.IsOpen() {
+		h.sendError(w, http.StatusConflict, "contest_not_open", "Contest is "+contest.Status)
+		return
+	}
+	if !h.tenantActive(w, r, tenantID, "Failed to resolve contest") {
+		return
+	}
+
+	proven, err := h.verifier.VerifyChallenge(r.Context(), contest.Domain, contest.ChallengeToken)
+	if err != nil {
+		h.logger.Error("Challenge lookup failed", zap.Error(err))
+		h.sendError(w, http.StatusInternalServerError, "verification_failed", "Failed to check the DNS challenge")
+		return
+	}
+	if !proven {
+		h.sendJSON(w, http.StatusOK, models.ResolveContestResponse{
+			Won:     false,
+			Message: "TXT record not found. Please publish the challenge and try again.",
+		})
+		return
+	}
+
+	resolved, domain, err := h.contestRepo.Resolve(r.Context(), contest.ID)
+	if errors.Is(err, database.ErrContestNotOpen) {
+		h.sendError(w, http.StatusConflict, "contest_not_open", "Contest can no longer be resolved")
+		return
+	}
+	if err != nil {
+		h.logger.Error("Failed to resolve contest", zap.Error(err))
+		h.sendError(w, http.StatusInternalServerError, "internal_error", "Failed to resolve contest")
+		return
+	}
+
+	if domain == nil {
+		h.sendJSON(w, http.StatusOK, models.ResolveContestResponse{
+			Won:     false,
+			Message: "Claim can no longer be contested; the contest is lost",
+		})
+		return
+	}
+
+	h.resolver.InvalidateTenant(resolved.HolderTenantID)
+	h.resolver.Invalidate(domain.Domain)
+
+	h.logger.Info("Domain claim displaced",
+		zap.String("domain", domain.Domain),
+		zap.String("from_tenant_id", resolved.HolderTenantID),
+		zap.String("to_tenant_id", domain.TenantID),
+	)
+
+	h.sendJSON(w, http.StatusOK, models.ResolveContestResponse{
+		Won:              true,
+		Message:          "Domain claimed successfully; complete DNS verification to route it",
+		Domain:           domain,
+		VerificationInfo: h.verifier.GetVerificationInstructions(domain.Domain, domain.VerificationToken),
+	})
+}
+
+// CancelContest handles DELETE /api/contests/{id}
+func (h *Handler) CancelContest(w http.ResponseWriter, r *http.Request) {
+	contest, ok := h.loadContest(w, r)
+	if !ok {
+		return
+	}
+
+	if contest.TenantID != GetTenantID(r.Context()) {
+		h.sendError(w, http.StatusForbidden, "forbidden", "Only the challenging tenant can cancel a contest")
+		return
+	}
+
+	cancelled, err := h.contestRepo.Cancel(r.Context(), contest.ID)
+	if errors.Is(err, database.ErrContestNotOpen) {
+		h.sendError(w, http.StatusConflict, "contest_not_open", "Contest is "+contest.Status)
+		return
+	}
+	if err != nil {
+		h.logger.Error("Failed to cancel contest", zap.Error(err))
+		h.sendError(w, http.StatusInternalServerError, "internal_error", "Failed to cancel contest")
+		return
+	}
+
+	h.logger.Info("Domain contest cancelled",
+		zap.String("domain", cancelled.Domain),
+		zap.String("tenant_id", cancelled.TenantID),
+	)
+
+	h.sendJSON(w, http.StatusOK, models.ContestResponse{Contest: cancelled})
+}
+
+// loadContest loads the contest named by the {id} path value. Contests are
+// only visible to the challenging tenant and the tenant holding the claim.
+func (h *Handler) loadContest(w http.ResponseWriter, r *http.Request) (*models.DomainContest, bool) {
+	contest, err := h.contestRepo.Get(r.Context(), r.PathValue("id"))
+	if err != nil {
+		h.logger.Error("Failed to get contest", zap.Error(err))
+		h.sendError(w, http.StatusInternalServerError, "internal_error", "Failed to get contest")
+		return nil, false
+	}
+
+	tenantID := GetTenantID(r.Context())
+	if contest == nil || (contest.TenantID != tenantID && contest.HolderTenantID != tenantID) {
+		h.sendError(w, http.StatusNotFound, "not_found", "Contest not found")
+		return nil, false
+	}
+
+	return contest, true
+}
+
+// contestResponse adds the DNS challenge to an open contest when the
+// challenging tenant is asking
+func (h *Handler) contestResponse(r *http.Request, contest *models.DomainContest) models.ContestResponse {
+	resp := models.ContestResponse{Contest: contest}
+	if contest.IsOpen() && contest.TenantID == GetTenantID(r.Context()) {
+		resp.Challenge = h.verifier.GetChallengeInstructions(contest.Domain, contest.ChallengeToken)
+	}
+	return resp
+}
+
+// tenantActive reports whether a tenant may claim domains, sending the error
+// response if not. Suspended tenants cannot.
+func (h *Handler) tenantActive(w http.ResponseWriter, r *http.Request, tenantID, failure string) bool {
+	if tenantID == "" {
+		h.sendError(w, http.StatusUnauthorized, "unauthorized", "Tenant ID not found")
+		return false
+	}
+
+	settings, err := h.settingsRepo.GetTenant(r.Context(), tenantID)
+	if err != nil {
+		h.logger.Error("Failed to get tenant settings", zap.Error(err))
+		h.sendError(w, http.StatusInternalServerError, "internal_error", failure)
+		return false
+	}
+	if settings.Suspended {
+		h.sendError(w, http.StatusForbidden, "tenant_suspended", "Tenant is suspended")
+		return false
+	}
+	return true
+}
@@ -2,7 +2,10 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"go.uber.org/zap"
@@ -11,34 +14,63 @@ import (
 	"github.com/panaroid/domain-gateway/internal/config"
 	"github.com/panaroid/domain-gateway/internal/database"
 	"github.com/panaroid/domain-gateway/internal/dns"
+	"github.com/panaroid/domain-gateway/internal/events"
+	"github.com/panaroid/domain-gateway/internal/resolver"
 	"github.com/panaroid/domain-gateway/internal/worker"
 	"github.com/panaroid/domain-gateway/pkg/models"
 )
 
 // Handler handles HTTP requests
 type Handler struct {
-	repo         *database.DomainRepository
+	repo         database.DomainStore
+	settingsRepo database.SettingsStore
+	apiKeyRepo   *database.APIKeyRepository
+	auditRepo    *database.AccessAuditRepository
+	eventRepo    *database.EventRepository
+	webhookRepo  *database.WebhookRepository
+	transferRepo *database.TransferRepository
+	contestRepo  *database.ContestRepository
+	broker       *events.Broker
 	verifier     *dns.Verifier
 	caddyManager *caddy.Manager
 	worker       *worker.VerificationWorker
+	resolver     *resolver.Resolver
 	cfg          config.CaddyConfig
 	logger       *zap.Logger
 }
 
 // NewHandler creates a new API handler
 func NewHandler(
-	repo *database.DomainRepository,
+	repo database.DomainStore,
+	settingsRepo database.SettingsStore,
+	apiKeyRepo *database.APIKeyRepository,
+	auditRepo *database.AccessAuditRepository,
+	eventRepo *database.EventRepository,
+	webhookRepo *database.WebhookRepository,
+	transferRepo *database.TransferRepository,
+	contestRepo *database.ContestRepository,
+	broker *events.Broker,
 	verifier *dns.Verifier,
 	caddyManager *caddy.Manager,
 	worker *worker.VerificationWorker,
+	resolver *resolver.Resolver,
 	cfg config.CaddyConfig,
 	logger *zap.Logger,
 ) *Handler {
 	return &Handler{
 		repo:         repo,
+		settingsRepo: settingsRepo,
+		apiKeyRepo:   apiKeyRepo,
+		auditRepo:    auditRepo,
+		eventRepo:    eventRepo,
+		webhookRepo:  webhookRepo,
+		transferRepo: transferRepo,
+		contestRepo:  contestRepo,
+		broker:       broker,
 		verifier:     verifier,
 		caddyManager: caddyManager,
 		worker:       worker,
+		resolver:     resolver,
 		cfg:          cfg,
 		logger:       logger,
 	}
@@ -69,6 +101,11 @@ func (h *Handler) CreateDomain(w http.ResponseWriter, r *http.Request) {
 	// Normalize domain
 	req.Domain = strings.ToLower(strings.TrimSpace(req.Domain))
 
+	// Suspended tenants cannot add domains
+	if !h.tenantActive(w, r, tenantID, "Failed to create domain") {
+		return
+	}
+
 	// Check if domain already exists
 	existing, err := h.repo.GetByDomain(r.Context(), req.Domain)
 	if err != nil {
@@ -77,19 +114,26 @@ func (h *Handler) CreateDomain(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	if existing != nil {
+		// A deleted domain stays reserved to its tenant until it is purged
+		if existing.Deleted && existing.TenantID == req.TenantID {
+			h.sendError(w, http.StatusConflict, "domain_deleted", "Domain is in the trash; restore it instead")
+			return
+		}
+		// Another tenant's unverified claim can be displaced by proving DNS control
+		if existing.TenantID != req.TenantID && existing.Contestable() {
+			h.sendError(w, http.StatusConflict, "domain_contestable", "Domain is claimed by another tenant but not verified; contest the claim instead")
+			return
+		}
 		h.sendError(w, http.StatusConflict, "domain_exists", "Domain already exists")
 		return
 	}
 
-	// Determine domain type
-	domainType := req.Type
-	if domainType == "" {
-		// Auto-detect type based on base domain
-		if strings.HasSuffix(req.Domain, "."+h.cfg.BaseDomain) {
-			domainType = models.DomainTypeSubdomain
-		} else {
-			domainType = models.DomainTypeCustom
-		}
+	// The type follows from the hostname; subdomains skip DNS proof, so the
+	// request cannot choose it
+	domainType := domainTypeFor(req.Domain, h.cfg.BaseDomain)
+	if req.Type != "" && req.Type != domainType {
+		h.sendError(w, http.StatusBadRequest, "invalid_type", "Domain type must be "+string(domainType)+" for this hostname")
+		return
 	}
 
 	// Create domain model
@@ -102,23 +146,28 @@ func (h *Handler) CreateDomain(w http.ResponseWriter, r *http.Request) {
 	var verificationInfo *models.VerificationInfo
 
 	if domainType == models.DomainTypeSubdomain {
-		// Subdomain: auto-verify (we own the parent domain)
-		domain.Verified = true
-		domain.SSLIssued = true // Wildcard cert handles this
+		// Subdomain: auto-verify (we own the parent domain); the wildcard cert covers it
+		domain.SetStatus(models.StatusActive, "")
 	} else {
 		// Custom domain: generate verification token
-		domain.Verified = false
+		domain.SetStatus(models.StatusPendingDNS, "")
 		domain.VerificationToken = h.verifier.GenerateToken()
 		verificationInfo = h.verifier.GetVerificationInstructions(domain.Domain, domain.VerificationToken)
 	}
 
 	// Save to database
 	if err := h.repo.Create(r.Context(), domain); err != nil {
+		if errors.Is(err, database.ErrDomainExists) {
+			h.sendError(w, http.StatusConflict, "domain_exists", "Domain already exists")
+			return
+		}
 		h.logger.Error("Failed to create domain", zap.Error(err))
 		h.sendError(w, http.StatusInternalServerError, "internal_error", "Failed to create domain")
 		return
 	}
 
+	h.resolver.Invalidate(domain.Domain)
+
 	// If subdomain, add to Caddy immediately
 	if domain.Verified {
 		if err := h.caddyManager.AddDomain(r.Context(), domain); err != nil {
@@ -138,6 +187,15 @@ func (h *Handler) CreateDomain(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// domainTypeFor returns the type of a hostname: subdomain under the base
+// domain, custom otherwise
+func domainTypeFor(name, baseDomain string) models.DomainType {
+	if baseDomain != "" && strings.HasSuffix(name, "."+baseDomain) {
+		return models.DomainTypeSubdomain
+	}
+	return models.DomainTypeCustom
+}
+
 // ListDomains handles GET /api/domains
 func (h *Handler) ListDomains(w http.ResponseWriter, r *http.Request) {
 	tenantID := GetTenantID(r.Context())
@@ -146,19 +204,90 @@ func (h *Handler) ListDomains(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	domains, err := h.repo.ListByTenant(r.Context(), tenantID)
+	filter, err := domainFilter(r)
+	if err != nil {
+		h.sendError(w, http.StatusBadRequest, "invalid_filter", err.Error())
+		return
+	}
+	filter.TenantID = tenantID
+
+	page, err := h.repo.List(r.Context(), filter)
+	if errors.Is(err, database.ErrInvalidCursor) {
+		h.sendError(w, http.StatusBadRequest, "invalid_cursor", "Invalid cursor")
+		return
+	}
 	if err != nil {
 		h.logger.Error("Failed to list domains", zap.Error(err))
 		h.sendError(w, http.StatusInternalServerError, "internal_error", "Failed to list domains")
 		return
 	}
 
+	domains := page.Domains
+	if domains == nil {
+		domains = []models.Domain{}
+	}
+
 	h.sendJSON(w, http.StatusOK, models.DomainListResponse{
-		Domains: domains,
-		Total:   len(domains),
+		Domains:    domains,
+		Total:      page.Total,
+		NextCursor: page.NextCursor,
 	})
 }
 
+// domainFilter parses the listing filters of GET /api/domains
+func domainFilter(r *http.Request) (models.DomainFilter, error) {
+	query := r.URL.Query()
+	filter := models.DomainFilter{
+		Type:   models.DomainType(query.Get("type")),
+		Status: models.DomainStatus(query.Get("status")),
+		Search: query.Get("search"),
+		Cursor: query.Get("cursor"),
+	}
+
+	if v := query.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 {
+			return filter, errors.New("limit must be a positive integer")
+		}
+		filter.Limit = limit
+	}
+
+	switch filter.Type {
+	case "", models.DomainTypeSubdomain, models.DomainTypeCustom:
+	default:
+		return filter, fmt.Errorf("unknown type: %s", filter.Type)
+	}
+	if filter.Status != "" && !filter.Status.IsKnown() {
+		return filter, fmt.Errorf("unknown status: %s", filter.Status)
+	}
+
+	for name, dst := range map[string]**bool{
+		"verified": &filter.Verified,
+		"primary":  &filter.Primary,
+		"archived": &filter.Archived,
+	} {
+		v := query.Get(name)
+		if v == "" {
+			continue
+		}
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return filter, fmt.Errorf("%s must be true or false", name)
+		}
+		*dst = &b
+	}
+
+	if v := query.Get("deleted"); v != "" {
+		deleted, err := strconv.ParseBool(v)
+		if err != nil {
+			return filter, errors.New("deleted must be true or false")
+		}
+		filter.Deleted = deleted
+	}
+
+	return filter, nil
+}
+
 // GetDomain handles GET /api/domains/{id}
 func (h *Handler) GetDomain(w http.ResponseWriter, r *http.Request) {
 	id := r.PathValue("id")
@@ -224,18 +353,78 @@ func (h *Handler) DeleteDomain(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	// Delete from database
+	// Move to the trash; the purge job deletes it once retention expires
 	if err := h.repo.Delete(r.Context(), id); err != nil {
 		h.logger.Error("Failed to delete domain", zap.Error(err))
 		h.sendError(w, http.StatusInternalServerError, "internal_error", "Failed to delete domain")
 		return
 	}
 
-	h.logger.Info("Domain deleted", zap.String("domain", domain.Domain))
+	h.resolver.Invalidate(domain.Domain)
+	if domain.IsPrimary {
+		h.resolver.InvalidateTenant(tenantID)
+	}
+
+	h.logger.Info("Domain moved to trash", zap.String("domain", domain.Domain))
 
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// RestoreDomain handles POST /api/domains/{id}/restore
+func (h *Handler) RestoreDomain(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if id == "" {
+		h.sendError(w, http.StatusBadRequest, "invalid_id", "Domain ID is required")
+		return
+	}
+
+	domain, err := h.repo.GetByID(r.Context(), id)
+	if err != nil {
+		h.logger.Error("Failed to get domain", zap.Error(err))
+		h.sendError(w, http.StatusInternalServerError, "internal_error", "Failed to restore domain")
+		return
+	}
+
+	if domain == nil {
+		h.sendError(w, http.StatusNotFound, "not_found", "Domain not found")
+		return
+	}
+
+	// Verify tenant access
+	tenantID := GetTenantID(r.Context())
+	if domain.TenantID != tenantID {
+		h.sendError(w, http.StatusForbidden, "forbidden", "Access denied")
+		return
+	}
+
+	restored, err := h.repo.Restore(r.Context(), id)
+	if errors.Is(err, database.ErrInvalidTransition) {
+		h.sendError(w, http.StatusConflict, "not_deleted", "Domain is not in the trash")
+		return
+	}
+	if err != nil {
+		h.logger.Error("Failed to restore domain", zap.Error(err))
+		h.sendError(w, http.StatusInternalServerError, "internal_error", "Failed to restore domain")
+		return
+	}
+
+	// Route the domain again if it was verified when deleted
+	if restored.Verified {
+		if err := h.caddyManager.AddDomain(r.Context(), restored); err != nil {
+			h.logger.Warn("Failed to add domain to Caddy", zap.Error(err))
+		}
+	}
+
+	h.resolver.Invalidate(restored.Domain)
+
+	h.logger.Info("Domain restored from trash",
+		zap.String("domain", restored.Domain),
+		zap.String("status", string(restored.Status)),
+	)
+
+	h.sendJSON(w, http.StatusOK, restored)
+}
+
 // VerifyDomain handles POST /api/domains/{id}/verify
 func (h *Handler) VerifyDomain(w http.ResponseWriter, r *http.Request) {
 	id := r.PathValue("id")
@@ -272,6 +461,11 @@ func (h *Handler) VerifyDomain(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if domain.Deleted {
+		h.sendError(w, http.StatusConflict, "domain_deleted", "Domain is in the trash; restore it before verifying")
+		return
+	}
+
 	// Trigger verification
 	verified, err := h.worker.VerifyNow(r.Context(), id)
 	if err != nil {
@@ -336,6 +530,13 @@ func (h *Handler) SetPrimaryDomain(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	h.resolver.InvalidateTenant(tenantID)
+
+	// Refresh the X-Primary-Domain header on the tenant's routes
+	if err := h.caddyManager.SetPrimaryDomain(r.Context(), tenantID, domain.Domain); err != nil {
+		h.logger.Warn("Failed to refresh tenant routes in Caddy", zap.Error(err))
+	}
+
 	h.logger.Info("Primary domain updated",
 		zap.String("domain", domain.Domain),
 		zap.String("tenant_id", tenantID),
@@ -346,6 +547,29 @@ func (h *Handler) SetPrimaryDomain(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// ResolveHost handles GET /internal/resolve
+func (h *Handler) ResolveHost(w http.ResponseWriter, r *http.Request) {
+	host := r.URL.Query().Get("host")
+	if host == "" {
+		h.sendError(w, http.StatusBadRequest, "invalid_host", "Host is required")
+		return
+	}
+
+	resolution, err := h.resolver.Resolve(r.Context(), host)
+	if err != nil {
+		h.logger.Error("Failed to resolve host", zap.String("host", host), zap.Error(err))
+		h.sendError(w, http.StatusInternalServerError, "internal_error", "Failed to resolve host")
+		return
+	}
+
+	if resolution == nil {
+		h.sendError(w, http.StatusNotFound, "not_found", "Host not found")
+		return
+	}
+
+	h.sendJSON(w, http.StatusOK, resolution)
+}
+
 // Health handles GET /health
 func (h *Handler) Health(w http.ResponseWriter, r *http.Request) {
 	h.sendJSON(w, http.StatusOK, map[string]interface{}{
@@ -362,10 +586,15 @@ func (h *Handler) sendJSON(w http.ResponseWriter, status int, data interface{})
 }
 
 func (h *Handler) sendError(w http.ResponseWriter, status int, code, message string) {
+	h.sendErrorDetails(w, status, code, message, "")
+}
+
+func (h *Handler) sendErrorDetails(w http.ResponseWriter, status int, code, message, details string) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
 	json.NewEncoder(w).Encode(models.ErrorResponse{
-		Error: message,
-		Code:  code,
+		Error:   message,
+		Code:    code,
+		Details: details,
 	})
 }
@@ -0,0 +1,148 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/panaroid/domain-gateway/internal/caddy"
+	"github.com/panaroid/domain-gateway/internal/config"
+	"github.com/panaroid/domain-gateway/internal/database"
+	"github.com/panaroid/domain-gateway/internal/dns"
+	"github.com/panaroid/domain-gateway/internal/resolver"
+	"github.com/panaroid/domain-gateway/pkg/models"
+)
+
+func TestDomainTypeFor(t *testing.T) {
+	tests := []struct {
+		name       string
+		baseDomain string
+		want       models.DomainType
+	}{
+		{"shop.panaroid.app", "panaroid.app", models.DomainTypeSubdomain},
+		{"a.b.panaroid.app", "panaroid.app", models.DomainTypeSubdomain},
+		{"panaroid.app", "panaroid.app", models.DomainTypeCustom},
+		{"victim.com", "panaroid.app", models.DomainTypeCustom},
+		{"notpanaroid.app", "panaroid.app", models.DomainTypeCustom},
+		{"shop.panaroid.app", "", models.DomainTypeCustom},
+	}
+
+	for _, tt := range tests {
+		if got := domainTypeFor(tt.name, tt.baseDomain); got != tt.want {
+			t.Errorf("domainTypeFor(%q, %q) = %s, want %s", tt.name, tt.baseDomain, got, tt.want)
+		}
+	}
+}
+
+// newTestHandler builds a handler over a MemoryStore, with a Caddy admin API
+// that accepts every change
+func newTestHandler(t *testing.T) (*Handler, *database.MemoryStore) {
+	t.Helper()
+
+	admin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	t.Cleanup(admin.Close)
+
+	cfg := config.CaddyConfig{
+		AdminAPIAddr: strings.TrimPrefix(admin.URL, "http://"),
+		BaseDomain:   "panaroid.app",
+		BackendHost:  "backend",
+		BackendPort:  3000,
+	}
+	logger := zap.NewNop()
+	store := database.NewMemoryStore()
+
+	h := NewHandler(
+		store, store, nil, nil, nil, nil, nil, nil, nil,
+		dns.NewVerifier(logger),
+		caddy.NewManager(cfg, config.DNSConfig{}, logger),
+		nil,
+		resolver.NewResolver(store, config.ResolverConfig{}, logger),
+		cfg,
+		logger,
+	)
+	return h, store
+}
+
+// createDomain posts body to CreateDomain as tenantID
+func createDomain(h *Handler, tenantID, body string) *httptest.ResponseRecorder {
+	ctx := context.WithValue(context.Background(), ContextKeyTenantID, tenantID)
+	req := httptest.NewRequest(http.MethodPost, "/api/domains", strings.NewReader(body)).WithContext(ctx)
+	rec := httptest.NewRecorder()
+	h.CreateDomain(rec, req)
+	return rec
+}
+
+func TestCreateDomain(t *testing.T) {
+	h, _ := newTestHandler(t)
+	tenantID := uuid.New().String()
+
+	tests := []struct {
+		name   string
+		body   string
+		status int
+		want   models.DomainStatus
+	}{
+		{"custom", `{"domain":"Shop.Example.com"}`, http.StatusCreated, models.StatusPendingDNS},
+		{"subdomain", `{"domain":"shop.panaroid.app"}`, http.StatusCreated, models.StatusActive},
+		{"matching type", `{"domain":"blog.example.com","type":"custom"}`, http.StatusCreated, models.StatusPendingDNS},
+		{"duplicate", `{"domain":"shop.example.com"}`, http.StatusConflict, ""},
+		{"missing domain", `{}`, http.StatusBadRequest, ""},
+	}
+
+	for _, tt := range tests {
+		rec := createDomain(h, tenantID, tt.body)
+		if rec.Code != tt.status {
+			t.Errorf("%s: status = %d, want %d: %s", tt.name, rec.Code, tt.status, rec.Body.String())
+			continue
+		}
+		if tt.want == "" {
+			continue
+		}
+
+		var resp models.CreateDomainResponse
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("%s: failed to decode response: %v", tt.name, err)
+		}
+		if resp.Domain.Status != tt.want || resp.Domain.TenantID != tenantID {
+			t.Errorf("%s: domain = %+v, want status %s for the tenant", tt.name, resp.Domain, tt.want)
+		}
+		if (tt.want == models.StatusPendingDNS) != (resp.VerificationInfo != nil) {
+			t.Errorf("%s: verification info = %+v", tt.name, resp.VerificationInfo)
+		}
+	}
+}
+
+func TestCreateDomainRejectsChosenType(t *testing.T) {
+	h, store := newTestHandler(t)
+
+	// Claiming a custom domain as a subdomain would skip the DNS proof
+	rec := createDomain(h, uuid.New().String(), `{"domain":"victim.com","type":"subdomain"}`)
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), `"code":"invalid_type"`) {
+		t.Fatalf("status = %d, body = %s; want 400 invalid_type", rec.Code, rec.Body.String())
+	}
+
+	domain, err := store.GetByDomain(context.Background(), "victim.com")
+	if err != nil || domain != nil {
+		t.Errorf("GetByDomain = %+v, %v; want no domain", domain, err)
+	}
+}
+
+func TestCreateDomainSuspendedTenant(t *testing.T) {
+	h, store := newTestHandler(t)
+	tenantID := uuid.New().String()
+
+	if _, err := store.SetTenantSuspended(context.Background(), tenantID, true, "unpaid"); err != nil {
+		t.Fatalf("SetTenantSuspended: %v", err)
+	}
+
+	rec := createDomain(h, tenantID, `{"domain":"shop.example.com"}`)
+	if rec.Code != http.StatusForbidden || !strings.Contains(rec.Body.String(), "tenant_suspended") {
+		t.Errorf("status = %d, body = %s; want 403 tenant_suspended", rec.Code, rec.Body.String())
+	}
+}
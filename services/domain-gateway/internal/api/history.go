@@ -0,0 +1,77 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"go.uber.org/zap"
+
+	"github.com/panaroid/domain-gateway/pkg/models"
+)
+
+// DomainHistory handles GET /api/domains/{id}/history
+func (h *Handler) DomainHistory(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if id == "" {
+		h.sendError(w, http.StatusBadRequest, "invalid_id", "Domain ID is required")
+		return
+	}
+
+	tenantID := GetTenantID(r.Context())
+	limit, offset := pagination(r)
+
+	// Scoped by tenant rather than by loading the domain, so deleted domains keep their history
+	events, total, err := h.eventRepo.ListByDomain(r.Context(), tenantID, id, limit, offset)
+	if err != nil {
+		h.logger.Error("Failed to list domain events", zap.Error(err))
+		h.sendError(w, http.StatusInternalServerError, "internal_error", "Failed to list domain history")
+		return
+	}
+
+	h.sendEvents(w, events, total)
+}
+
+// TenantHistory handles GET /api/history
+func (h *Handler) TenantHistory(w http.ResponseWriter, r *http.Request) {
+	tenantID := GetTenantID(r.Context())
+	limit, offset := pagination(r)
+
+	events, total, err := h.eventRepo.ListByTenant(r.Context(), tenantID, limit, offset)
+	if err != nil {
+		h.logger.Error("Failed to list tenant events", zap.Error(err))
+		h.sendError(w, http.StatusInternalServerError, "internal_error", "Failed to list history")
+		return
+	}
+
+	h.sendEvents(w, events, total)
+}
+
+func (h *Handler) sendEvents(w http.ResponseWriter, events []models.DomainEvent, total int) {
+	if events == nil {
+		events = []models.DomainEvent{}
+	}
+
+	h.sendJSON(w, http.StatusOK, models.DomainEventListResponse{
+		Events: events,
+		Total:  total,
+	})
+}
+
+// pagination reads limit and offset query parameters, capping limit at 200
+func pagination(r *http.Request) (int, int) {
+	query := r.URL.Query()
+
+	limit := 50
+	if v, err := strconv.Atoi(query.Get("limit")); err == nil && v > 0 {
+		limit = v
+	}
+	if limit > 200 {
+		limit = 200
+	}
+	offset := 0
+	if v, err := strconv.Atoi(query.Get("offset")); err == nil && v > 0 {
+		offset = v
+	}
+
+	return limit, offset
+}
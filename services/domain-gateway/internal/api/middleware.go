@@ -2,16 +2,20 @@ package api
 
 import (
 	"context"
+	"crypto/subtle"
 	"encoding/json"
-	"fmt"
 	"net/http"
 	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 
+	"github.com/panaroid/domain-gateway/internal/auth"
 	"github.com/panaroid/domain-gateway/internal/config"
+	"github.com/panaroid/domain-gateway/internal/database"
+	"github.com/panaroid/domain-gateway/pkg/models"
 )
 
 // Claims represents JWT claims
@@ -29,22 +33,75 @@ const (
 	ContextKeyTenantID contextKey = "tenant_id"
 	ContextKeyUserID   contextKey = "user_id"
 	ContextKeyRole     contextKey = "role"
+	ContextKeyAPIKeyID contextKey = "api_key_id"
+	ContextKeyScopes   contextKey = "scopes"
+	ContextKeyActingAs contextKey = "acting_as"
 )
 
+// HeaderRequestID carries the request ID for correlating logs and audit events
+const HeaderRequestID = "X-Request-ID"
+
+// HeaderActAsTenant lets platform staff act on behalf of another tenant
+const HeaderActAsTenant = "X-Act-As-Tenant"
+
+// auditTimeout bounds recording the outcome of a request in the access audit trail
+const auditTimeout = 5 * time.Second
+
+// apiKeyTouchInterval throttles last-used updates for API keys
+const apiKeyTouchInterval = time.Minute
+
 // Middleware provides HTTP middleware functions
 type Middleware struct {
-	jwtConfig config.JWTConfig
-	logger    *zap.Logger
+	jwtConfig    config.JWTConfig
+	serverConfig config.ServerConfig
+	keys         *auth.KeySet
+	apiKeys      *database.APIKeyRepository
+	audit        *database.AccessAuditRepository
+	parser       *jwt.Parser
+	logger       *zap.Logger
 }
 
 // NewMiddleware creates a new middleware instance
-func NewMiddleware(jwtConfig config.JWTConfig, logger *zap.Logger) *Middleware {
+func NewMiddleware(
+	jwtConfig config.JWTConfig,
+	serverConfig config.ServerConfig,
+	keys *auth.KeySet,
+	apiKeys *database.APIKeyRepository,
+	audit *database.AccessAuditRepository,
+	logger *zap.Logger,
+) *Middleware {
+	opts := []jwt.ParserOption{jwt.WithValidMethods(keys.ValidMethods())}
+	if jwtConfig.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(jwtConfig.Issuer))
+	}
+	if jwtConfig.Audience != "" {
+		opts = append(opts, jwt.WithAudience(jwtConfig.Audience))
+	}
+
 	return &Middleware{
-		jwtConfig: jwtConfig,
-		logger:    logger,
+		jwtConfig:    jwtConfig,
+		serverConfig: serverConfig,
+		keys:         keys,
+		apiKeys:      apiKeys,
+		audit:        audit,
+		parser:       jwt.NewParser(opts...),
+		logger:       logger,
 	}
 }
 
+// RequestID assigns each request an ID, reusing a well-formed incoming one
+func (m *Middleware) RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(HeaderRequestID)
+		if requestID == "" || len(requestID) > 64 {
+			requestID = uuid.New().String()
+		}
+
+		w.Header().Set(HeaderRequestID, requestID)
+		next.ServeHTTP(w, r.WithContext(database.WithRequestID(r.Context(), requestID)))
+	})
+}
+
 // Logging logs HTTP requests
 func (m *Middleware) Logging(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -61,6 +118,7 @@ func (m *Middleware) Logging(next http.Handler) http.Handler {
 			zap.Int("status", wrapped.statusCode),
 			zap.Duration("duration", time.Since(start)),
 			zap.String("remote_addr", r.RemoteAddr),
+			zap.String("request_id", database.RequestIDFromContext(r.Context())),
 		)
 	})
 }
@@ -76,9 +134,20 @@ func (rw *responseWriter) WriteHeader(code int) {
 	rw.ResponseWriter.WriteHeader(code)
 }
 
-// Auth validates JWT tokens
+// Unwrap exposes the underlying writer to http.ResponseController, e.g. for flushing streams
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
+// Auth validates JWT tokens and API keys
 func (m *Middleware) Auth(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// API keys may be sent in their own header
+		if apiKey := r.Header.Get("X-API-Key"); apiKey != "" {
+			m.authenticateAPIKey(w, r, apiKey, next)
+			return
+		}
+
 		// Get token from Authorization header
 		authHeader := r.Header.Get("Authorization")
 		if authHeader == "" {
@@ -95,14 +164,15 @@ func (m *Middleware) Auth(next http.Handler) http.Handler {
 
 		tokenString := parts[1]
 
+		// ... or as a bearer token
+		if auth.IsAPIKey(tokenString) {
+			m.authenticateAPIKey(w, r, tokenString, next)
+			return
+		}
+
 		// Parse and validate token
 		claims := &Claims{}
-		token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-			}
-			return []byte(m.jwtConfig.Secret), nil
-		})
+		token, err := m.parser.ParseWithClaims(tokenString, claims, m.keys.Keyfunc(r.Context()))
 
 		if err != nil {
 			m.logger.Debug("Token validation failed", zap.Error(err))
@@ -115,21 +185,210 @@ func (m *Middleware) Auth(next http.Handler) http.Handler {
 			return
 		}
 
+		role := m.effectiveRole(claims.Role)
+
 		// Add claims to context
 		ctx := context.WithValue(r.Context(), ContextKeyTenantID, claims.TenantID)
 		ctx = context.WithValue(ctx, ContextKeyUserID, claims.UserID)
-		ctx = context.WithValue(ctx, ContextKeyRole, claims.Role)
+		ctx = context.WithValue(ctx, ContextKeyRole, role)
+		ctx = database.WithActor(ctx, models.Actor{Type: models.ActorUser, ID: claims.UserID})
+
+		if actAs := r.Header.Get(HeaderActAsTenant); actAs != "" {
+			m.actAsTenant(w, r.WithContext(ctx), claims, role, actAs, next)
+			return
+		}
 
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
 
-// AdminOnly restricts access to admin users
+// actAsTenant swaps the request's tenant for the one named in the act-as header.
+// The request is recorded in the access audit trail before it runs and is
+// refused if it cannot be; its status is filled in once it completes.
+func (m *Middleware) actAsTenant(w http.ResponseWriter, r *http.Request, claims *Claims, role, tenantID string, next http.Handler) {
+	if !platformStaff(role) {
+		m.sendError(w, http.StatusForbidden, "forbidden", "Acting as another tenant requires a platform staff role")
+		return
+	}
+	if claims.UserID == "" {
+		m.sendError(w, http.StatusForbidden, "forbidden", "Acting as another tenant requires a user ID claim")
+		return
+	}
+	if _, err := uuid.Parse(tenantID); err != nil {
+		m.sendError(w, http.StatusBadRequest, "invalid_tenant", "Invalid "+HeaderActAsTenant+" header")
+		return
+	}
+
+	entry := &models.AccessAuditEntry{
+		UserID:       claims.UserID,
+		Role:         role,
+		HomeTenantID: claims.TenantID,
+		TenantID:     tenantID,
+		Method:       r.Method,
+		Path:         r.URL.Path,
+		RemoteAddr:   r.RemoteAddr,
+	}
+	if err := m.audit.Record(r.Context(), entry); err != nil {
+		m.logger.Error("Failed to record access audit entry",
+			zap.String("user_id", claims.UserID),
+			zap.String("tenant_id", tenantID),
+			zap.Error(err),
+		)
+		m.sendError(w, http.StatusInternalServerError, "internal_error", "An internal error occurred")
+		return
+	}
+
+	ctx := context.WithValue(r.Context(), ContextKeyTenantID, tenantID)
+	ctx = context.WithValue(ctx, ContextKeyActingAs, true)
+
+	wrapped := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+	next.ServeHTTP(wrapped, r.WithContext(ctx))
+
+	auditCtx, cancel := context.WithTimeout(context.WithoutCancel(r.Context()), auditTimeout)
+	defer cancel()
+
+	if err := m.audit.SetStatus(auditCtx, entry.ID, wrapped.statusCode); err != nil {
+		m.logger.Error("Failed to record access audit status",
+			zap.String("audit_id", entry.ID),
+			zap.Int("status", wrapped.statusCode),
+			zap.Error(err),
+		)
+	}
+
+	m.logger.Info("Request made on behalf of tenant",
+		zap.String("user_id", claims.UserID),
+		zap.String("role", role),
+		zap.String("tenant_id", tenantID),
+		zap.String("method", r.Method),
+		zap.String("path", r.URL.Path),
+		zap.Int("status", wrapped.statusCode),
+	)
+}
+
+// authenticateAPIKey validates an API key and adds its tenant and scopes to the context
+func (m *Middleware) authenticateAPIKey(w http.ResponseWriter, r *http.Request, plaintext string, next http.Handler) {
+	prefix, ok := auth.ParseAPIKeyPrefix(plaintext)
+	if !ok {
+		m.sendError(w, http.StatusUnauthorized, "invalid_api_key", "Invalid API key")
+		return
+	}
+
+	key, err := m.apiKeys.GetByPrefix(r.Context(), prefix)
+	if err != nil {
+		m.logger.Error("Failed to look up API key", zap.Error(err))
+		m.sendError(w, http.StatusInternalServerError, "internal_error", "An internal error occurred")
+		return
+	}
+
+	if key == nil || !auth.CompareAPIKey(plaintext, key.KeyHash) {
+		m.sendError(w, http.StatusUnauthorized, "invalid_api_key", "Invalid API key")
+		return
+	}
+
+	now := time.Now()
+	if !key.Active(now) {
+		m.sendError(w, http.StatusUnauthorized, "api_key_inactive", "API key is revoked or expired")
+		return
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) > apiKeyTouchInterval {
+		if err := m.apiKeys.TouchLastUsed(r.Context(), key.ID); err != nil {
+			m.logger.Warn("Failed to record API key usage", zap.Error(err))
+		}
+	}
+
+	ctx := context.WithValue(r.Context(), ContextKeyTenantID, key.TenantID)
+	ctx = context.WithValue(ctx, ContextKeyAPIKeyID, key.ID)
+	ctx = context.WithValue(ctx, ContextKeyScopes, key.Scopes)
+	ctx = database.WithActor(ctx, models.Actor{Type: models.ActorAPIKey, ID: key.ID})
+
+	next.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// RequirePermission restricts a route to principals granted a permission.
+// JWT requests are checked against the role claim; API key requests against the key's scopes.
+func (m *Middleware) RequirePermission(perm Permission) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if IsActingAs(r.Context()) && !m.jwtConfig.ImpersonationWrites && !readOnly(perm) {
+				m.sendErrorDetails(w, http.StatusForbidden, "impersonation_read_only",
+					"Requests made as another tenant are read-only", string(perm))
+				return
+			}
+			if !m.allowed(r.Context(), perm) {
+				m.sendErrorDetails(w, http.StatusForbidden, "forbidden",
+					"Missing permission: "+string(perm), string(perm))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// allowed reports whether the authenticated principal holds a permission
+func (m *Middleware) allowed(ctx context.Context, perm Permission) bool {
+	if GetAPIKeyID(ctx) != "" {
+		return scopesAllow(GetScopes(ctx), perm)
+	}
+	return roleAllows(GetRole(ctx), perm)
+}
+
+// effectiveRole falls back to the configured default role for tokens without
+// a recognised role claim
+func (m *Middleware) effectiveRole(role string) string {
+	if KnownRole(role) {
+		return role
+	}
+	return m.jwtConfig.DefaultRole
+}
+
+// UserOnly rejects requests authenticated with an API key
+func (m *Middleware) UserOnly(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if GetAPIKeyID(r.Context()) != "" {
+			m.sendError(w, http.StatusForbidden, "user_required", "This endpoint requires a user token")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// AdminOnly restricts access to platform admins. A tenant admin only manages
+// its own tenant, unless jwt.legacy_admin_role keeps admin tokens issued
+// before platform_admin existed working until the issuer is updated.
 func (m *Middleware) AdminOnly(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		role, ok := r.Context().Value(ContextKeyRole).(string)
-		if !ok || role != "admin" {
-			m.sendError(w, http.StatusForbidden, "forbidden", "Admin access required")
+		role := GetRole(r.Context())
+		if role == RoleAdmin && m.jwtConfig.LegacyAdminRole {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if role != RolePlatformAdmin {
+			if role == RoleAdmin {
+				m.logger.Warn("Refused admin token on an admin route; issue platform_admin or set jwt.legacy_admin_role",
+					zap.String("user_id", GetUserID(r.Context())),
+					zap.String("path", r.URL.Path),
+				)
+			}
+			m.sendError(w, http.StatusForbidden, "forbidden", "Platform admin access required")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Internal restricts access to in-cluster callers holding the internal token.
+// Internal routes are disabled until a token is configured.
+func (m *Middleware) Internal(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		expected := m.serverConfig.InternalToken
+		if expected == "" {
+			m.sendError(w, http.StatusForbidden, "internal_disabled", "Internal endpoints are disabled")
+			return
+		}
+		token := r.Header.Get("X-Internal-Token")
+		if subtle.ConstantTimeCompare([]byte(token), []byte(expected)) != 1 {
+			m.sendError(w, http.StatusUnauthorized, "invalid_internal_token", "Invalid internal token")
 			return
 		}
 		next.ServeHTTP(w, r)
@@ -141,7 +400,8 @@ func (m *Middleware) CORS(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
 		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-API-Key, "+HeaderActAsTenant+", "+HeaderRequestID)
+		w.Header().Set("Access-Control-Expose-Headers", HeaderRequestID)
 
 		if r.Method == http.MethodOptions {
 			w.WriteHeader(http.StatusOK)
@@ -169,11 +429,16 @@ func (m *Middleware) Recovery(next http.Handler) http.Handler {
 }
 
 func (m *Middleware) sendError(w http.ResponseWriter, status int, code, message string) {
+	m.sendErrorDetails(w, status, code, message, "")
+}
+
+func (m *Middleware) sendErrorDetails(w http.ResponseWriter, status int, code, message, details string) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
-	json.NewEncoder(w).Encode(map[string]string{
-		"error": message,
-		"code":  code,
+	json.NewEncoder(w).Encode(models.ErrorResponse{
+		Error:   message,
+		Code:    code,
+		Details: details,
 	})
 }
 
@@ -192,3 +457,42 @@ func GetUserID(ctx context.Context) string {
 	}
 	return ""
 }
+
+// GetRole extracts the role claim from context, empty for API key requests
+func GetRole(ctx context.Context) string {
+	if role, ok := ctx.Value(ContextKeyRole).(string); ok {
+		return role
+	}
+	return ""
+}
+
+// IsActingAs reports whether the request is made by staff on behalf of a tenant
+func IsActingAs(ctx context.Context) bool {
+	actingAs, _ := ctx.Value(ContextKeyActingAs).(bool)
+	return actingAs
+}
+
+// GetAPIKeyID extracts the API key ID from context, empty for JWT requests
+func GetAPIKeyID(ctx context.Context) string {
+	if id, ok := ctx.Value(ContextKeyAPIKeyID).(string); ok {
+		return id
+	}
+	return ""
+}
+
+// GetScopes extracts API key scopes from context
+func GetScopes(ctx context.Context) []string {
+	if scopes, ok := ctx.Value(ContextKeyScopes).([]string); ok {
+		return scopes
+	}
+	return nil
+}
+
+func hasScope(scopes []string, scope string) bool {
+	for _, s := range scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
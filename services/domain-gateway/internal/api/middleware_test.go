@@ -0,0 +1,206 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/panaroid/domain-gateway/internal/auth"
+	"github.com/panaroid/domain-gateway/internal/config"
+	"github.com/panaroid/domain-gateway/internal/database"
+	"github.com/panaroid/domain-gateway/pkg/models"
+)
+
+// openTestDB opens a migrated SQLite database in a temporary directory
+func openTestDB(t *testing.T) *database.DB {
+	t.Helper()
+
+	db, err := database.New(config.DatabaseConfig{
+		URL:             "sqlite://" + filepath.Join(t.TempDir(), "gateway.db"),
+		MaxOpenConns:    1,
+		MaxIdleConns:    1,
+		ConnMaxLifetime: time.Minute,
+	}, zap.NewNop())
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	if err := db.MigrateUp(context.Background(), 0); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+	return db
+}
+
+func TestActAsTenant(t *testing.T) {
+	db := openTestDB(t)
+	audit := database.NewAccessAuditRepository(db)
+	m := &Middleware{
+		jwtConfig: config.JWTConfig{ImpersonationWrites: true},
+		audit:     audit,
+		logger:    zap.NewNop(),
+	}
+
+	tenantID := uuid.New().String()
+	claims := &Claims{TenantID: uuid.New().String(), UserID: "staff-1"}
+
+	tests := []struct {
+		role   string
+		status int
+	}{
+		{RolePlatformSupport, http.StatusNoContent},
+		{RolePlatformAdmin, http.StatusNoContent},
+		{RoleSupport, http.StatusForbidden},
+		{RoleAdmin, http.StatusForbidden},
+		{RoleOwner, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.role, func(t *testing.T) {
+			var seen string
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				seen = GetTenantID(r.Context())
+
+				// The audit entry exists before the handler runs
+				entries, err := audit.List(r.Context(), tenantID, claims.UserID, 100, 0)
+				if err != nil || len(entries) == 0 || entries[0].Status != 0 {
+					t.Errorf("no pending audit entry while handling the request: %v", err)
+				}
+				w.WriteHeader(http.StatusNoContent)
+			})
+
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/api/domains", nil)
+			m.actAsTenant(rec, req, claims, tt.role, tenantID, next)
+
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.status)
+			}
+			if tt.status == http.StatusForbidden {
+				if seen != "" {
+					t.Error("handler ran for a tenant role")
+				}
+				return
+			}
+			if seen != tenantID {
+				t.Errorf("tenant = %q, want %q", seen, tenantID)
+			}
+
+			entries, err := audit.List(context.Background(), tenantID, claims.UserID, 1, 0)
+			if err != nil || len(entries) == 0 {
+				t.Fatalf("List = %v, %v", entries, err)
+			}
+			if entries[0].Status != http.StatusNoContent || entries[0].Role != tt.role {
+				t.Errorf("audit entry = %+v, want status %d for role %s", entries[0], http.StatusNoContent, tt.role)
+			}
+		})
+	}
+}
+
+func TestActAsTenantRefusesWithoutAudit(t *testing.T) {
+	db := openTestDB(t)
+	m := &Middleware{
+		audit:  database.NewAccessAuditRepository(db),
+		logger: zap.NewNop(),
+	}
+	db.Close()
+
+	called := false
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	})
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodDelete, "/api/domains/1", nil)
+	claims := &Claims{UserID: "staff-1"}
+	m.actAsTenant(rec, req, claims, RolePlatformAdmin, uuid.New().String(), next)
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusInternalServerError)
+	}
+	if called {
+		t.Error("handler ran without an audit entry")
+	}
+}
+
+func TestAuthenticateAPIKey(t *testing.T) {
+	db := openTestDB(t)
+	keys := database.NewAPIKeyRepository(db)
+	m := &Middleware{apiKeys: keys, logger: zap.NewNop()}
+	ctx := context.Background()
+
+	past := time.Now().Add(-time.Hour)
+	future := time.Now().Add(time.Hour)
+	tests := []struct {
+		name    string
+		expires *time.Time
+		revoke  bool
+		status  int
+		code    string
+	}{
+		{name: "active", status: http.StatusNoContent},
+		{name: "not yet expired", expires: &future, status: http.StatusNoContent},
+		{name: "expired", expires: &past, status: http.StatusUnauthorized, code: "api_key_inactive"},
+		{name: "revoked", revoke: true, status: http.StatusUnauthorized, code: "api_key_inactive"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plaintext, prefix, hash, err := auth.GenerateAPIKey()
+			if err != nil {
+				t.Fatalf("GenerateAPIKey: %v", err)
+			}
+			key := &models.APIKey{
+				TenantID:  uuid.New().String(),
+				Name:      tt.name,
+				Prefix:    prefix,
+				KeyHash:   hash,
+				Scopes:    []string{models.ScopeDomainsRead},
+				ExpiresAt: tt.expires,
+			}
+			if err := keys.Create(ctx, key); err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+			if tt.revoke {
+				if err := keys.Revoke(ctx, key.TenantID, key.ID); err != nil {
+					t.Fatalf("Revoke: %v", err)
+				}
+			}
+
+			var tenantID string
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				tenantID = GetTenantID(r.Context())
+				w.WriteHeader(http.StatusNoContent)
+			})
+
+			rec := httptest.NewRecorder()
+			m.authenticateAPIKey(rec, httptest.NewRequest(http.MethodGet, "/api/domains", nil), plaintext, next)
+
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.status)
+			}
+			if tt.code != "" && !strings.Contains(rec.Body.String(), `"code":"`+tt.code+`"`) {
+				t.Errorf("body = %s, want code %s", rec.Body.String(), tt.code)
+			}
+			if tt.status == http.StatusNoContent && tenantID != key.TenantID {
+				t.Errorf("tenant = %q, want %q", tenantID, key.TenantID)
+			}
+		})
+	}
+
+	// A well-formed key that does not match the stored hash is refused
+	plaintext, prefix, _, _ := auth.GenerateAPIKey()
+	forged := strings.Replace(plaintext, prefix, "000000000000", 1)
+	rec := httptest.NewRecorder()
+	m.authenticateAPIKey(rec, httptest.NewRequest(http.MethodGet, "/api/domains", nil), forged, http.NotFoundHandler())
+	if rec.Code != http.StatusUnauthorized || !strings.Contains(rec.Body.String(), "invalid_api_key") {
+		t.Errorf("unknown key: status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+}
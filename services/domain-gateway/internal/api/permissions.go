@@ -0,0 +1,133 @@
+package api
+
+import (
+	"github.com/panaroid/domain-gateway/pkg/models"
+)
+
+// Permission is an action a principal may perform on a tenant's resources
+type Permission string
+
+const (
+	PermDomainsRead     Permission = "domains:read"
+	PermDomainsCreate   Permission = "domains:create"
+	PermDomainsDelete   Permission = "domains:delete"
+	PermDomainsVerify   Permission = "domains:verify"
+	PermDomainsPrimary  Permission = "domains:primary"
+	PermDomainsTransfer Permission = "domains:transfer"
+	PermSettingsRead    Permission = "settings:read"
+	PermSettingsWrite   Permission = "settings:write"
+	PermKeysManage      Permission = "keys:manage"
+	PermWebhooksManage  Permission = "webhooks:manage"
+)
+
+// Roles carried in the JWT role claim
+const (
+	RoleOwner     = "owner"
+	RoleAdmin     = "admin"
+	RoleDeveloper = "developer"
+	RoleViewer    = "viewer"
+	RoleSupport   = "support"
+
+	// RolePlatformAdmin is platform staff. Unlike the tenant-scoped admin it
+	// reaches the /api/admin routes and may act as any tenant.
+	RolePlatformAdmin = "platform_admin"
+
+	// RolePlatformSupport is platform support staff. Unlike the tenant-scoped
+	// support role it may act as any tenant, with read access.
+	RolePlatformSupport = "platform_support"
+)
+
+// allPermissions is granted to owners, admins and platform admins
+var allPermissions = []Permission{
+	PermDomainsRead,
+	PermDomainsCreate,
+	PermDomainsDelete,
+	PermDomainsVerify,
+	PermDomainsPrimary,
+	PermDomainsTransfer,
+	PermSettingsRead,
+	PermSettingsWrite,
+	PermKeysManage,
+	PermWebhooksManage,
+}
+
+// rolePermissions maps each role to the actions it may perform
+var rolePermissions = map[string][]Permission{
+	RoleOwner:         allPermissions,
+	RoleAdmin:         allPermissions,
+	RolePlatformAdmin: allPermissions,
+	RoleDeveloper: {
+		PermDomainsRead,
+		PermDomainsCreate,
+		PermDomainsVerify,
+		PermSettingsRead,
+		PermSettingsWrite,
+		PermKeysManage,
+		PermWebhooksManage,
+	},
+	RoleViewer: {
+		PermDomainsRead,
+		PermSettingsRead,
+	},
+	RoleSupport: {
+		PermDomainsRead,
+		PermSettingsRead,
+	},
+	RolePlatformSupport: {
+		PermDomainsRead,
+		PermSettingsRead,
+	},
+}
+
+// scopePermissions maps API key scopes to the actions they grant
+var scopePermissions = map[string][]Permission{
+	models.ScopeDomainsRead:   {PermDomainsRead},
+	models.ScopeDomainsWrite:  {PermDomainsCreate, PermDomainsDelete, PermDomainsPrimary},
+	models.ScopeDomainsVerify: {PermDomainsVerify},
+	models.ScopeSettingsRead:  {PermSettingsRead},
+	models.ScopeSettingsWrite: {PermSettingsWrite},
+}
+
+// readPermissions are the permissions that never modify tenant state
+var readPermissions = map[Permission]bool{
+	PermDomainsRead:  true,
+	PermSettingsRead: true,
+}
+
+// readOnly reports whether a permission only reads tenant state
+func readOnly(perm Permission) bool {
+	return readPermissions[perm]
+}
+
+// roleAllows reports whether a role grants a permission
+func roleAllows(role string, perm Permission) bool {
+	for _, p := range rolePermissions[role] {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
+
+// scopesAllow reports whether any API key scope grants a permission
+func scopesAllow(scopes []string, perm Permission) bool {
+	for _, scope := range scopes {
+		for _, p := range scopePermissions[scope] {
+			if p == perm {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// platformStaff reports whether a role belongs to platform staff rather than a tenant
+func platformStaff(role string) bool {
+	return role == RolePlatformAdmin || role == RolePlatformSupport
+}
+
+// KnownRole reports whether a role is part of the permission model
+func KnownRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
@@ -0,0 +1,152 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/panaroid/domain-gateway/internal/config"
+	"github.com/panaroid/domain-gateway/pkg/models"
+)
+
+func TestRolePermissions(t *testing.T) {
+	tests := []struct {
+		perm  Permission
+		roles map[string]bool
+	}{
+		{PermDomainsRead, map[string]bool{RoleOwner: true, RoleAdmin: true, RoleDeveloper: true, RoleViewer: true, RoleSupport: true, RolePlatformAdmin: true, RolePlatformSupport: true}},
+		{PermDomainsCreate, map[string]bool{RoleOwner: true, RoleAdmin: true, RoleDeveloper: true, RolePlatformAdmin: true}},
+		{PermDomainsVerify, map[string]bool{RoleOwner: true, RoleAdmin: true, RoleDeveloper: true, RolePlatformAdmin: true}},
+		{PermDomainsDelete, map[string]bool{RoleOwner: true, RoleAdmin: true, RolePlatformAdmin: true}},
+		{PermDomainsPrimary, map[string]bool{RoleOwner: true, RoleAdmin: true, RolePlatformAdmin: true}},
+		{PermDomainsTransfer, map[string]bool{RoleOwner: true, RoleAdmin: true, RolePlatformAdmin: true}},
+		{PermSettingsRead, map[string]bool{RoleOwner: true, RoleAdmin: true, RoleDeveloper: true, RoleViewer: true, RoleSupport: true, RolePlatformAdmin: true, RolePlatformSupport: true}},
+		{PermSettingsWrite, map[string]bool{RoleOwner: true, RoleAdmin: true, RoleDeveloper: true, RolePlatformAdmin: true}},
+		{PermKeysManage, map[string]bool{RoleOwner: true, RoleAdmin: true, RoleDeveloper: true, RolePlatformAdmin: true}},
+		{PermWebhooksManage, map[string]bool{RoleOwner: true, RoleAdmin: true, RoleDeveloper: true, RolePlatformAdmin: true}},
+	}
+
+	for _, tt := range tests {
+		for role := range rolePermissions {
+			if got := roleAllows(role, tt.perm); got != tt.roles[role] {
+				t.Errorf("roleAllows(%s, %s) = %v, want %v", role, tt.perm, got, tt.roles[role])
+			}
+		}
+		if roleAllows("superuser", tt.perm) {
+			t.Errorf("unknown role granted %s", tt.perm)
+		}
+	}
+}
+
+func TestScopePermissions(t *testing.T) {
+	tests := []struct {
+		scope string
+		perms []Permission
+	}{
+		{models.ScopeDomainsRead, []Permission{PermDomainsRead}},
+		{models.ScopeDomainsWrite, []Permission{PermDomainsCreate, PermDomainsDelete, PermDomainsPrimary}},
+		{models.ScopeDomainsVerify, []Permission{PermDomainsVerify}},
+		{models.ScopeSettingsRead, []Permission{PermSettingsRead}},
+		{models.ScopeSettingsWrite, []Permission{PermSettingsWrite}},
+	}
+
+	for _, tt := range tests {
+		granted := make(map[Permission]bool)
+		for _, perm := range tt.perms {
+			granted[perm] = true
+		}
+		for _, perm := range allPermissions {
+			if got := scopesAllow([]string{tt.scope}, perm); got != granted[perm] {
+				t.Errorf("scopesAllow(%s, %s) = %v, want %v", tt.scope, perm, got, granted[perm])
+			}
+		}
+	}
+
+	// Transfers, keys and webhooks are never granted to API keys
+	for _, perm := range []Permission{PermDomainsTransfer, PermKeysManage, PermWebhooksManage} {
+		if scopesAllow(models.APIKeyScopes, perm) {
+			t.Errorf("every scope together granted %s", perm)
+		}
+	}
+}
+
+func TestRequirePermission(t *testing.T) {
+	m := &Middleware{logger: zap.NewNop()}
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	handler := m.RequirePermission(PermDomainsDelete)(next)
+
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		status int
+	}{
+		{"owner", context.WithValue(context.Background(), ContextKeyRole, RoleOwner), http.StatusNoContent},
+		{"developer", context.WithValue(context.Background(), ContextKeyRole, RoleDeveloper), http.StatusForbidden},
+		{"no role", context.Background(), http.StatusForbidden},
+		{"write key", apiKeyContext(models.ScopeDomainsWrite), http.StatusNoContent},
+		{"read key", apiKeyContext(models.ScopeDomainsRead), http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodDelete, "/api/domains/1", nil).WithContext(tt.ctx)
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != tt.status {
+			t.Errorf("%s: status = %d, want %d", tt.name, rec.Code, tt.status)
+			continue
+		}
+		if tt.status != http.StatusForbidden {
+			continue
+		}
+
+		var body models.ErrorResponse
+		if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+			t.Fatalf("%s: failed to decode error: %v", tt.name, err)
+		}
+		if body.Code != "forbidden" || body.Error != "Missing permission: domains:delete" || body.Details != "domains:delete" {
+			t.Errorf("%s: error = %+v, want the missing permission", tt.name, body)
+		}
+	}
+}
+
+func TestAdminOnly(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	tests := []struct {
+		role   string
+		legacy bool
+		status int
+	}{
+		{RolePlatformAdmin, false, http.StatusNoContent},
+		{RoleAdmin, false, http.StatusForbidden},
+		{RoleAdmin, true, http.StatusNoContent},
+		{RoleOwner, true, http.StatusForbidden},
+		{RolePlatformSupport, true, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		m := &Middleware{jwtConfig: config.JWTConfig{LegacyAdminRole: tt.legacy}, logger: zap.NewNop()}
+		rec := httptest.NewRecorder()
+		ctx := context.WithValue(context.Background(), ContextKeyRole, tt.role)
+		m.AdminOnly(next).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/admin/stats", nil).WithContext(ctx))
+
+		if rec.Code != tt.status {
+			t.Errorf("%s (legacy %v): status = %d, want %d", tt.role, tt.legacy, rec.Code, tt.status)
+		}
+	}
+}
+
+// apiKeyContext authenticates a request as an API key holding scopes
+func apiKeyContext(scopes ...string) context.Context {
+	ctx := context.WithValue(context.Background(), ContextKeyAPIKeyID, "key-1")
+	return context.WithValue(ctx, ContextKeyScopes, scopes)
+}
@@ -38,26 +38,100 @@ func (r *Router) Setup() http.Handler {
 		http.NotFound(w, req)
 	})
 
-	// Protected API routes
-	mux.HandleFunc("POST /api/domains", r.withAuth(r.handler.CreateDomain))
-	mux.HandleFunc("GET /api/domains", r.withAuth(r.handler.ListDomains))
-	mux.HandleFunc("GET /api/domains/{id}", r.withAuth(r.handler.GetDomain))
-	mux.HandleFunc("DELETE /api/domains/{id}", r.withAuth(r.handler.DeleteDomain))
-	mux.HandleFunc("POST /api/domains/{id}/verify", r.withAuth(r.handler.VerifyDomain))
-	mux.HandleFunc("POST /api/domains/{id}/primary", r.withAuth(r.handler.SetPrimaryDomain))
+	// Protected API routes, each gated by a role permission
+	mux.HandleFunc("POST /api/domains", r.withPermission(PermDomainsCreate, r.handler.CreateDomain))
+	mux.HandleFunc("GET /api/domains", r.withPermission(PermDomainsRead, r.handler.ListDomains))
+	mux.HandleFunc("GET /api/domains/events", r.withPermission(PermDomainsRead, r.handler.StreamDomainEvents))
+	mux.HandleFunc("GET /api/domains/{id}", r.withPermission(PermDomainsRead, r.handler.GetDomain))
+	mux.HandleFunc("DELETE /api/domains/{id}", r.withPermission(PermDomainsDelete, r.handler.DeleteDomain))
+	mux.HandleFunc("POST /api/domains/{id}/restore", r.withPermission(PermDomainsDelete, r.handler.RestoreDomain))
+	mux.HandleFunc("POST /api/domains/{id}/verify", r.withPermission(PermDomainsVerify, r.handler.VerifyDomain))
+	mux.HandleFunc("POST /api/domains/{id}/primary", r.withPermission(PermDomainsPrimary, r.handler.SetPrimaryDomain))
+	mux.HandleFunc("POST /api/domains/{id}/transfers", r.withPermission(PermDomainsTransfer, r.handler.CreateTransfer))
+	mux.HandleFunc("GET /api/domains/{id}/history", r.withPermission(PermDomainsRead, r.handler.DomainHistory))
+	mux.HandleFunc("GET /api/history", r.withPermission(PermDomainsRead, r.handler.TenantHistory))
+
+	// Domain transfers between tenants
+	mux.HandleFunc("GET /api/transfers", r.withPermission(PermDomainsRead, r.handler.ListTransfers))
+	mux.HandleFunc("GET /api/transfers/{id}", r.withPermission(PermDomainsRead, r.handler.GetTransfer))
+	mux.HandleFunc("POST /api/transfers/{id}/accept", r.withPermission(PermDomainsTransfer, r.handler.AcceptTransfer))
+	mux.HandleFunc("POST /api/transfers/{id}/complete", r.withPermission(PermDomainsTransfer, r.handler.CompleteTransfer))
+	mux.HandleFunc("DELETE /api/transfers/{id}", r.withPermission(PermDomainsTransfer, r.handler.CancelTransfer))
+
+	// Contests of another tenant's unverified claim on a hostname
+	mux.HandleFunc("POST /api/contests", r.withPermission(PermDomainsTransfer, r.handler.CreateContest))
+	mux.HandleFunc("GET /api/contests", r.withPermission(PermDomainsRead, r.handler.ListContests))
+	mux.HandleFunc("GET /api/contests/{id}", r.withPermission(PermDomainsRead, r.handler.GetContest))
+	mux.HandleFunc("POST /api/contests/{id}/resolve", r.withPermission(PermDomainsTransfer, r.handler.ResolveContest))
+	mux.HandleFunc("DELETE /api/contests/{id}", r.withPermission(PermDomainsTransfer, r.handler.CancelContest))
+
+	mux.HandleFunc("GET /api/settings", r.withPermission(PermSettingsRead, r.handler.GetSettings))
+	mux.HandleFunc("PUT /api/settings/maintenance", r.withPermission(PermSettingsWrite, r.handler.SetMaintenance))
+	mux.HandleFunc("PUT /api/settings/error-pages", r.withPermission(PermSettingsWrite, r.handler.SetErrorPages))
+
+	// API key management (user tokens only)
+	mux.HandleFunc("POST /api/keys", r.withUserPermission(PermKeysManage, r.handler.CreateAPIKey))
+	mux.HandleFunc("GET /api/keys", r.withUserPermission(PermKeysManage, r.handler.ListAPIKeys))
+	mux.HandleFunc("DELETE /api/keys/{id}", r.withUserPermission(PermKeysManage, r.handler.RevokeAPIKey))
+
+	// Webhook endpoints
+	mux.HandleFunc("POST /api/webhooks", r.withPermission(PermWebhooksManage, r.handler.CreateWebhook))
+	mux.HandleFunc("GET /api/webhooks", r.withPermission(PermWebhooksManage, r.handler.ListWebhooks))
+	mux.HandleFunc("DELETE /api/webhooks/{id}", r.withPermission(PermWebhooksManage, r.handler.DeleteWebhook))
+	mux.HandleFunc("GET /api/webhooks/{id}/deliveries", r.withPermission(PermWebhooksManage, r.handler.ListWebhookDeliveries))
+	mux.HandleFunc("POST /api/webhooks/{id}/deliveries/{deliveryId}/replay", r.withPermission(PermWebhooksManage, r.handler.ReplayWebhookDelivery))
+
+	// Admin routes
+	mux.HandleFunc("GET /api/admin/domains", r.withAdmin(r.handler.ListAllDomains))
+	mux.HandleFunc("POST /api/admin/domains/{id}/verify", r.withAdmin(r.handler.ForceVerifyDomain))
+	mux.HandleFunc("POST /api/admin/domains/{id}/unverify", r.withAdmin(r.handler.ForceUnverifyDomain))
+	mux.HandleFunc("POST /api/admin/domains/{id}/reassign", r.withAdmin(r.handler.ReassignDomain))
+	mux.HandleFunc("POST /api/admin/caddy/resync", r.withAdmin(r.handler.ResyncCaddy))
+	mux.HandleFunc("GET /api/admin/worker", r.withAdmin(r.handler.WorkerStatus))
+	mux.HandleFunc("GET /api/admin/maintenance", r.withAdmin(r.handler.GetGlobalMaintenance))
+	mux.HandleFunc("PUT /api/admin/maintenance", r.withAdmin(r.handler.SetGlobalMaintenance))
+	mux.HandleFunc("POST /api/admin/tenants/{id}/suspend", r.withAdmin(r.handler.SuspendTenant))
+	mux.HandleFunc("POST /api/admin/tenants/{id}/unsuspend", r.withAdmin(r.handler.UnsuspendTenant))
+	mux.HandleFunc("GET /api/admin/access-audit", r.withAdmin(r.handler.ListAccessAudit))
+
+	// Internal routes for the application backend
+	mux.HandleFunc("GET /internal/resolve", r.withInternal(r.handler.ResolveHost))
 
 	// Apply global middleware
 	handler := r.middleware.Recovery(mux)
 	handler = r.middleware.Logging(handler)
+	handler = r.middleware.RequestID(handler)
 	handler = r.middleware.CORS(handler)
 
 	return handler
 }
 
-// withAuth wraps a handler with authentication middleware
-func (r *Router) withAuth(fn http.HandlerFunc) http.HandlerFunc {
+// withPermission wraps a handler with authentication and a permission requirement
+func (r *Router) withPermission(perm Permission, fn http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		r.middleware.Auth(r.middleware.RequirePermission(perm)(http.HandlerFunc(fn))).ServeHTTP(w, req)
+	}
+}
+
+// withUserPermission wraps a handler with authentication that rejects API keys
+// and a permission requirement
+func (r *Router) withUserPermission(perm Permission, fn http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		r.middleware.Auth(r.middleware.UserOnly(r.middleware.RequirePermission(perm)(http.HandlerFunc(fn)))).ServeHTTP(w, req)
+	}
+}
+
+// withAdmin wraps a handler with authentication and admin-only middleware
+func (r *Router) withAdmin(fn http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		r.middleware.Auth(r.middleware.AdminOnly(http.HandlerFunc(fn))).ServeHTTP(w, req)
+	}
+}
+
+// withInternal wraps a handler with internal token middleware
+func (r *Router) withInternal(fn http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, req *http.Request) {
-		r.middleware.Auth(http.HandlerFunc(fn)).ServeHTTP(w, req)
+		r.middleware.Internal(http.HandlerFunc(fn)).ServeHTTP(w, req)
 	}
 }
 
@@ -0,0 +1,185 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/panaroid/domain-gateway/pkg/models"
+)
+
+// maxPageSize limits custom HTML pages pushed into the Caddy config
+const maxPageSize = 256 << 10
+
+// GetSettings handles GET /api/settings
+func (h *Handler) GetSettings(w http.ResponseWriter, r *http.Request) {
+	tenantID := GetTenantID(r.Context())
+	if tenantID == "" {
+		h.sendError(w, http.StatusUnauthorized, "unauthorized", "Tenant ID not found")
+		return
+	}
+
+	settings, err := h.settingsRepo.GetTenant(r.Context(), tenantID)
+	if err != nil {
+		h.logger.Error("Failed to get settings", zap.Error(err))
+		h.sendError(w, http.StatusInternalServerError, "internal_error", "Failed to get settings")
+		return
+	}
+
+	h.sendJSON(w, http.StatusOK, settings)
+}
+
+// SetMaintenance handles PUT /api/settings/maintenance
+func (h *Handler) SetMaintenance(w http.ResponseWriter, r *http.Request) {
+	var req models.MaintenanceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendError(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		return
+	}
+
+	tenantID := GetTenantID(r.Context())
+	if tenantID == "" {
+		h.sendError(w, http.StatusUnauthorized, "unauthorized", "Tenant ID not found")
+		return
+	}
+
+	if len(req.Page) > maxPageSize {
+		h.sendError(w, http.StatusBadRequest, "page_too_large", "Maintenance page is too large")
+		return
+	}
+
+	settings, err := h.settingsRepo.GetTenant(r.Context(), tenantID)
+	if err != nil {
+		h.logger.Error("Failed to get settings", zap.Error(err))
+		h.sendError(w, http.StatusInternalServerError, "internal_error", "Failed to update maintenance mode")
+		return
+	}
+
+	settings.MaintenanceMode = req.Enabled
+	if req.Page != "" {
+		settings.MaintenancePage = req.Page
+	}
+
+	if err := h.settingsRepo.UpsertTenant(r.Context(), settings); err != nil {
+		h.logger.Error("Failed to save settings", zap.Error(err))
+		h.sendError(w, http.StatusInternalServerError, "internal_error", "Failed to update maintenance mode")
+		return
+	}
+
+	if err := h.caddyManager.ApplyTenantSettings(r.Context(), settings); err != nil {
+		h.logger.Warn("Failed to apply tenant settings to Caddy", zap.Error(err))
+	}
+
+	h.logger.Info("Tenant maintenance mode updated",
+		zap.String("tenant_id", tenantID),
+		zap.Bool("enabled", req.Enabled),
+	)
+
+	h.sendJSON(w, http.StatusOK, settings)
+}
+
+// SetErrorPages handles PUT /api/settings/error-pages
+func (h *Handler) SetErrorPages(w http.ResponseWriter, r *http.Request) {
+	var req models.ErrorPagesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendError(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		return
+	}
+
+	tenantID := GetTenantID(r.Context())
+	if tenantID == "" {
+		h.sendError(w, http.StatusUnauthorized, "unauthorized", "Tenant ID not found")
+		return
+	}
+
+	allowed := make(map[string]bool)
+	for _, code := range models.CustomErrorPageCodes {
+		allowed[code] = true
+	}
+	for code, page := range req.ErrorPages {
+		if !allowed[code] {
+			h.sendError(w, http.StatusBadRequest, "invalid_status_code",
+				fmt.Sprintf("Custom pages are supported for %v", models.CustomErrorPageCodes))
+			return
+		}
+		if len(page) > maxPageSize {
+			h.sendError(w, http.StatusBadRequest, "page_too_large", "Error page is too large")
+			return
+		}
+	}
+
+	settings, err := h.settingsRepo.GetTenant(r.Context(), tenantID)
+	if err != nil {
+		h.logger.Error("Failed to get settings", zap.Error(err))
+		h.sendError(w, http.StatusInternalServerError, "internal_error", "Failed to update error pages")
+		return
+	}
+
+	settings.ErrorPages = req.ErrorPages
+
+	if err := h.settingsRepo.UpsertTenant(r.Context(), settings); err != nil {
+		h.logger.Error("Failed to save settings", zap.Error(err))
+		h.sendError(w, http.StatusInternalServerError, "internal_error", "Failed to update error pages")
+		return
+	}
+
+	if err := h.caddyManager.ApplyTenantSettings(r.Context(), settings); err != nil {
+		h.logger.Warn("Failed to apply tenant settings to Caddy", zap.Error(err))
+	}
+
+	h.sendJSON(w, http.StatusOK, settings)
+}
+
+// GetGlobalMaintenance handles GET /api/admin/maintenance
+func (h *Handler) GetGlobalMaintenance(w http.ResponseWriter, r *http.Request) {
+	settings, err := h.settingsRepo.GetGlobal(r.Context())
+	if err != nil {
+		h.logger.Error("Failed to get global settings", zap.Error(err))
+		h.sendError(w, http.StatusInternalServerError, "internal_error", "Failed to get maintenance mode")
+		return
+	}
+
+	h.sendJSON(w, http.StatusOK, settings)
+}
+
+// SetGlobalMaintenance handles PUT /api/admin/maintenance
+func (h *Handler) SetGlobalMaintenance(w http.ResponseWriter, r *http.Request) {
+	var req models.MaintenanceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendError(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		return
+	}
+
+	if len(req.Page) > maxPageSize {
+		h.sendError(w, http.StatusBadRequest, "page_too_large", "Maintenance page is too large")
+		return
+	}
+
+	settings, err := h.settingsRepo.GetGlobal(r.Context())
+	if err != nil {
+		h.logger.Error("Failed to get global settings", zap.Error(err))
+		h.sendError(w, http.StatusInternalServerError, "internal_error", "Failed to update maintenance mode")
+		return
+	}
+
+	settings.MaintenanceMode = req.Enabled
+	if req.Page != "" {
+		settings.MaintenancePage = req.Page
+	}
+
+	if err := h.settingsRepo.UpsertGlobal(r.Context(), settings); err != nil {
+		h.logger.Error("Failed to save global settings", zap.Error(err))
+		h.sendError(w, http.StatusInternalServerError, "internal_error", "Failed to update maintenance mode")
+		return
+	}
+
+	if err := h.caddyManager.ApplyGlobalSettings(r.Context(), settings); err != nil {
+		h.logger.Warn("Failed to apply global settings to Caddy", zap.Error(err))
+	}
+
+	h.logger.Info("Global maintenance mode updated", zap.Bool("enabled", req.Enabled))
+
+	h.sendJSON(w, http.StatusOK, settings)
+}
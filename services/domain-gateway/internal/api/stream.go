@@ -0,0 +1,72 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// streamHeartbeat keeps idle event streams open through proxies
+const streamHeartbeat = 25 * time.Second
+
+// StreamDomainEvents handles GET /api/domains/events as a Server-Sent Events
+// stream of the tenant's domain changes
+func (h *Handler) StreamDomainEvents(w http.ResponseWriter, r *http.Request) {
+	tenantID := GetTenantID(r.Context())
+	if tenantID == "" {
+		h.sendError(w, http.StatusUnauthorized, "unauthorized", "Tenant ID not found")
+		return
+	}
+
+	rc := http.NewResponseController(w)
+	// Streams outlive the server's write timeout
+	_ = rc.SetWriteDeadline(time.Time{})
+
+	sub := h.broker.Subscribe(tenantID)
+	defer sub.Close()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	fmt.Fprint(w, "retry: 3000\n\n")
+	if err := rc.Flush(); err != nil {
+		h.logger.Warn("Event stream does not support flushing", zap.Error(err))
+		return
+	}
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": heartbeat\n\n")
+
+		case event, ok := <-sub.Events():
+			if !ok {
+				// Dropped for falling behind or closed for shutdown; the
+				// client reconnects and reloads
+				return
+			}
+			data, err := json.Marshal(event)
+			if err != nil {
+				h.logger.Error("Failed to encode domain event", zap.Error(err))
+				continue
+			}
+			fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", event.ID, event.Action, data)
+		}
+
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,283 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/panaroid/domain-gateway/internal/database"
+	"github.com/panaroid/domain-gateway/pkg/models"
+)
+
+// CreateTransfer handles POST /api/domains/{id}/transfers
+func (h *Handler) CreateTransfer(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateTransferRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendError(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		return
+	}
+	if _, err := uuid.Parse(req.TenantID); err != nil {
+		h.sendError(w, http.StatusBadRequest, "invalid_tenant", "A valid receiving tenant ID is required")
+		return
+	}
+
+	id := r.PathValue("id")
+	domain, err := h.repo.GetByID(r.Context(), id)
+	if err != nil {
+		h.logger.Error("Failed to get domain", zap.Error(err))
+		h.sendError(w, http.StatusInternalServerError, "internal_error", "Failed to create transfer")
+		return
+	}
+
+	if domain == nil {
+		h.sendError(w, http.StatusNotFound, "not_found", "Domain not found")
+		return
+	}
+
+	// Only the current owner may give a domain away
+	tenantID := GetTenantID(r.Context())
+	if domain.TenantID != tenantID {
+		h.sendError(w, http.StatusForbidden, "forbidden", "Access denied")
+		return
+	}
+	if !h.tenantActive(w, r, tenantID, "Failed to create transfer") {
+		return
+	}
+	if req.TenantID == tenantID {
+		h.sendError(w, http.StatusBadRequest, "invalid_tenant", "Domain already belongs to this tenant")
+		return
+	}
+
+	transfer := &models.DomainTransfer{
+		DomainID:   domain.ID,
+		ToTenantID: req.TenantID,
+	}
+	err = h.transferRepo.Create(r.Context(), transfer)
+	if errors.Is(err, database.ErrTransferExists) {
+		h.sendError(w, http.StatusConflict, "transfer_exists", "Domain already has an open transfer")
+		return
+	}
+	if errors.Is(err, database.ErrInvalidTransition) {
+		h.sendError(w, http.StatusConflict, "domain_deleted", "Domain is in the trash")
+		return
+	}
+	if err != nil {
+		h.logger.Error("Failed to create transfer", zap.Error(err))
+		h.sendError(w, http.StatusInternalServerError, "internal_error", "Failed to create transfer")
+		return
+	}
+
+	h.logger.Info("Domain transfer initiated",
+		zap.String("domain", transfer.Domain),
+		zap.String("from_tenant_id", transfer.FromTenantID),
+		zap.String("to_tenant_id", transfer.ToTenantID),
+	)
+
+	h.sendJSON(w, http.StatusCreated, models.TransferResponse{Transfer: transfer})
+}
+
+// ListTransfers handles GET /api/transfers
+func (h *Handler) ListTransfers(w http.ResponseWriter, r *http.Request) {
+	tenantID := GetTenantID(r.Context())
+	if tenantID == "" {
+		h.sendError(w, http.StatusUnauthorized, "unauthorized", "Tenant ID not found")
+		return
+	}
+
+	transfers, err := h.transferRepo.ListByTenant(r.Context(), tenantID)
+	if err != nil {
+		h.logger.Error("Failed to list transfers", zap.Error(err))
+		h.sendError(w, http.StatusInternalServerError, "internal_error", "Failed to list transfers")
+		return
+	}
+	if transfers == nil {
+		transfers = []models.DomainTransfer{}
+	}
+
+	h.sendJSON(w, http.StatusOK, models.TransferListResponse{Transfers: transfers})
+}
+
+// GetTransfer handles GET /api/transfers/{id}. The receiving tenant also
+// gets the DNS challenge once it has accepted.
+func (h *Handler) GetTransfer(w http.ResponseWriter, r *http.Request) {
+	transfer, ok := h.loadTransfer(w, r)
+	if !ok {
+		return
+	}
+
+	h.sendJSON(w, http.StatusOK, h.transferResponse(r, transfer))
+}
+
+// AcceptTransfer handles POST /api/transfers/{id}/accept
+func (h *Handler) AcceptTransfer(w http.ResponseWriter, r *http.Request) {
+	transfer, ok := h.loadTransfer(w, r)
+	if !ok {
+		return
+	}
+
+	if transfer.ToTenantID != GetTenantID(r.Context()) {
+		h.sendError(w, http.StatusForbidden, "forbidden", "Only the receiving tenant can accept a transfer")
+		return
+	}
+	if !h.tenantActive(w, r, transfer.ToTenantID, "Failed to accept transfer") {
+		return
+	}
+
+	// A fresh token, so only whoever controls the DNS now can complete it
+	token, err := h.verifier.GenerateChallenge()
+	if err != nil {
+		h.logger.Error("Failed to generate challenge", zap.Error(err))
+		h.sendError(w, http.StatusInternalServerError, "internal_error", "Failed to accept transfer")
+		return
+	}
+
+	accepted, err := h.transferRepo.Accept(r.Context(), transfer.ID, token)
+	if errors.Is(err, database.ErrTransferNotOpen) {
+		h.sendError(w, http.StatusConflict, "transfer_not_open", "Transfer is "+transfer.Status)
+		return
+	}
+	if err != nil {
+		h.logger.Error("Failed to accept transfer", zap.Error(err))
+		h.sendError(w, http.StatusInternalServerError, "internal_error", "Failed to accept transfer")
+		return
+	}
+
+	h.logger.Info("Domain transfer accepted",
+		zap.String("domain", accepted.Domain),
+		zap.String("to_tenant_id", accepted.ToTenantID),
+	)
+
+	h.sendJSON(w, http.StatusOK, h.transferResponse(r, accepted))
+}
+
+// CompleteTransfer handles POST /api/transfers/{id}/complete
+func (h *Handler) CompleteTransfer(w http.ResponseWriter, r *http.Request) {
+	transfer, ok := h.loadTransfer(w, r)
+	if !ok {
+		return
+	}
+
+	if transfer.ToTenantID != GetTenantID(r.Context()) {
+		h.sendError(w, http.StatusForbidden, "forbidden", "Only the receiving tenant can complete a transfer")
+		return
+	}
+	if transfer.Status != models.TransferAccepted {
+		h.sendError(w, http.StatusConflict, "transfer_not_open", "Transfer is "+transfer.Status)
+		return
+	}
+	if !h.tenantActive(w, r, transfer.ToTenantID, "Failed to complete transfer") {
+		return
+	}
+
+	proven, err := h.verifier.VerifyChallenge(r.Context(), transfer.Domain, transfer.ChallengeToken)
+	if err != nil {
+		h.logger.Error("Challenge lookup failed", zap.Error(err))
+		h.sendError(w, http.StatusInternalServerError, "verification_failed", "Failed to check the DNS challenge")
+		return
+	}
+	if !proven {
+		h.sendJSON(w, http.StatusOK, models.CompleteTransferResponse{
+			Completed: false,
+			Message:   "TXT record not found. Please publish the challenge and try again.",
+		})
+		return
+	}
+
+	domain, err := h.transferRepo.Complete(r.Context(), transfer.ID)
+	if errors.Is(err, database.ErrTransferNotOpen) {
+		h.sendError(w, http.StatusConflict, "transfer_not_open", "Transfer can no longer be completed")
+		return
+	}
+	if err != nil {
+		h.logger.Error("Failed to complete transfer", zap.Error(err))
+		h.sendError(w, http.StatusInternalServerError, "internal_error", "Failed to complete transfer")
+		return
+	}
+
+	// Re-render the route so the backend sees the new tenant identity
+	if domain.Verified {
+		if err := h.caddyManager.AddDomain(r.Context(), domain); err != nil {
+			h.logger.Warn("Failed to update domain route in Caddy", zap.Error(err))
+		}
+	}
+
+	// The domain is no longer the previous owner's primary
+	if err := h.caddyManager.ClearPrimaryDomain(r.Context(), transfer.FromTenantID, domain.Domain); err != nil {
+		h.logger.Warn("Failed to clear primary domain in Caddy", zap.Error(err))
+	}
+
+	h.resolver.InvalidateTenant(transfer.FromTenantID)
+	h.resolver.InvalidateTenant(domain.TenantID)
+	h.resolver.Invalidate(domain.Domain)
+
+	h.logger.Info("Domain transferred",
+		zap.String("domain", domain.Domain),
+		zap.String("from_tenant_id", transfer.FromTenantID),
+		zap.String("to_tenant_id", domain.TenantID),
+	)
+
+	h.sendJSON(w, http.StatusOK, models.CompleteTransferResponse{
+		Completed: true,
+		Message:   "Domain transferred successfully",
+		Domain:    domain,
+	})
+}
+
+// CancelTransfer handles DELETE /api/transfers/{id}. Either tenant may cancel
+// an open transfer; for the receiving tenant this declines it.
+func (h *Handler) CancelTransfer(w http.ResponseWriter, r *http.Request) {
+	transfer, ok := h.loadTransfer(w, r)
+	if !ok {
+		return
+	}
+
+	cancelled, err := h.transferRepo.Cancel(r.Context(), transfer.ID)
+	if errors.Is(err, database.ErrTransferNotOpen) {
+		h.sendError(w, http.StatusConflict, "transfer_not_open", "Transfer is "+transfer.Status)
+		return
+	}
+	if err != nil {
+		h.logger.Error("Failed to cancel transfer", zap.Error(err))
+		h.sendError(w, http.StatusInternalServerError, "internal_error", "Failed to cancel transfer")
+		return
+	}
+
+	h.logger.Info("Domain transfer cancelled",
+		zap.String("domain", cancelled.Domain),
+		zap.String("tenant_id", GetTenantID(r.Context())),
+	)
+
+	h.sendJSON(w, http.StatusOK, models.TransferResponse{Transfer: cancelled})
+}
+
+// loadTransfer loads the transfer named by the {id} path value. Transfers are
+// only visible to the two tenants involved.
+func (h *Handler) loadTransfer(w http.ResponseWriter, r *http.Request) (*models.DomainTransfer, bool) {
+	transfer, err := h.transferRepo.Get(r.Context(), r.PathValue("id"))
+	if err != nil {
+		h.logger.Error("Failed to get transfer", zap.Error(err))
+		h.sendError(w, http.StatusInternalServerError, "internal_error", "Failed to get transfer")
+		return nil, false
+	}
+
+	tenantID := GetTenantID(r.Context())
+	if transfer == nil || (transfer.FromTenantID != tenantID && transfer.ToTenantID != tenantID) {
+		h.sendError(w, http.StatusNotFound, "not_found", "Transfer not found")
+		return nil, false
+	}
+
+	return transfer, true
+}
+
+// transferResponse adds the DNS challenge to an accepted transfer when the
+// receiving tenant is asking
+func (h *Handler) transferResponse(r *http.Request, transfer *models.DomainTransfer) models.TransferResponse {
+	resp := models.TransferResponse{Transfer: transfer}
+	if transfer.Status == models.TransferAccepted && transfer.ToTenantID == GetTenantID(r.Context()) {
+		resp.Challenge = h.verifier.GetChallengeInstructions(transfer.Domain, transfer.ChallengeToken)
+	}
+	return resp
+}
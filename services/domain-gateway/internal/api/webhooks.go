@@ -0,0 +1,162 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/panaroid/domain-gateway/internal/database"
+	"github.com/panaroid/domain-gateway/internal/webhooks"
+	"github.com/panaroid/domain-gateway/pkg/models"
+)
+
+// CreateWebhook handles POST /api/webhooks
+func (h *Handler) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendError(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		return
+	}
+
+	tenantID := GetTenantID(r.Context())
+	if tenantID == "" {
+		h.sendError(w, http.StatusUnauthorized, "unauthorized", "Tenant ID not found")
+		return
+	}
+
+	req.URL = strings.TrimSpace(req.URL)
+	if err := webhooks.ValidateURL(r.Context(), req.URL); err != nil {
+		h.sendError(w, http.StatusBadRequest, "invalid_url", err.Error())
+		return
+	}
+
+	for _, event := range req.Events {
+		if !models.IsWebhookEvent(event) {
+			h.sendError(w, http.StatusBadRequest, "invalid_events", "Unknown event type: "+event)
+			return
+		}
+	}
+
+	secret, err := webhooks.GenerateSecret()
+	if err != nil {
+		h.logger.Error("Failed to generate webhook secret", zap.Error(err))
+		h.sendError(w, http.StatusInternalServerError, "internal_error", "Failed to create webhook")
+		return
+	}
+
+	endpoint := &models.WebhookEndpoint{
+		TenantID: tenantID,
+		URL:      req.URL,
+		Secret:   secret,
+		Events:   req.Events,
+	}
+	if endpoint.Events == nil {
+		endpoint.Events = []string{}
+	}
+
+	if err := h.webhookRepo.CreateEndpoint(r.Context(), endpoint); err != nil {
+		h.logger.Error("Failed to create webhook endpoint", zap.Error(err))
+		h.sendError(w, http.StatusInternalServerError, "internal_error", "Failed to create webhook")
+		return
+	}
+
+	h.logger.Info("Webhook endpoint created",
+		zap.String("webhook_id", endpoint.ID),
+		zap.String("tenant_id", tenantID),
+		zap.String("url", endpoint.URL),
+	)
+
+	h.sendJSON(w, http.StatusCreated, models.CreateWebhookResponse{
+		WebhookEndpoint: endpoint,
+		Secret:          secret,
+	})
+}
+
+// ListWebhooks handles GET /api/webhooks
+func (h *Handler) ListWebhooks(w http.ResponseWriter, r *http.Request) {
+	tenantID := GetTenantID(r.Context())
+
+	endpoints, err := h.webhookRepo.ListEndpoints(r.Context(), tenantID)
+	if err != nil {
+		h.logger.Error("Failed to list webhook endpoints", zap.Error(err))
+		h.sendError(w, http.StatusInternalServerError, "internal_error", "Failed to list webhooks")
+		return
+	}
+
+	if endpoints == nil {
+		endpoints = []models.WebhookEndpoint{}
+	}
+
+	h.sendJSON(w, http.StatusOK, models.WebhookListResponse{Webhooks: endpoints})
+}
+
+// DeleteWebhook handles DELETE /api/webhooks/{id}
+func (h *Handler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	tenantID := GetTenantID(r.Context())
+
+	if err := h.webhookRepo.DeleteEndpoint(r.Context(), tenantID, id); err != nil {
+		if errors.Is(err, database.ErrWebhookNotFound) {
+			h.sendError(w, http.StatusNotFound, "not_found", "Webhook not found")
+			return
+		}
+		h.logger.Error("Failed to delete webhook endpoint", zap.Error(err))
+		h.sendError(w, http.StatusInternalServerError, "internal_error", "Failed to delete webhook")
+		return
+	}
+
+	h.logger.Info("Webhook endpoint deleted",
+		zap.String("webhook_id", id),
+		zap.String("tenant_id", tenantID),
+	)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListWebhookDeliveries handles GET /api/webhooks/{id}/deliveries
+func (h *Handler) ListWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	limit, offset := pagination(r)
+
+	deliveries, total, err := h.webhookRepo.ListDeliveries(r.Context(), GetTenantID(r.Context()), r.PathValue("id"), limit, offset)
+	if err != nil {
+		h.logger.Error("Failed to list webhook deliveries", zap.Error(err))
+		h.sendError(w, http.StatusInternalServerError, "internal_error", "Failed to list deliveries")
+		return
+	}
+
+	if deliveries == nil {
+		deliveries = []models.WebhookDelivery{}
+	}
+
+	h.sendJSON(w, http.StatusOK, models.WebhookDeliveryListResponse{
+		Deliveries: deliveries,
+		Total:      total,
+	})
+}
+
+// ReplayWebhookDelivery handles POST /api/webhooks/{id}/deliveries/{deliveryId}/replay
+func (h *Handler) ReplayWebhookDelivery(w http.ResponseWriter, r *http.Request) {
+	tenantID := GetTenantID(r.Context())
+
+	delivery, err := h.webhookRepo.Replay(r.Context(), tenantID, r.PathValue("id"), r.PathValue("deliveryId"))
+	if err != nil {
+		if errors.Is(err, database.ErrDeliveryNotFound) {
+			h.sendError(w, http.StatusNotFound, "not_found", "Delivery not found")
+			return
+		}
+		h.logger.Error("Failed to replay webhook delivery", zap.Error(err))
+		h.sendError(w, http.StatusInternalServerError, "internal_error", "Failed to replay delivery")
+		return
+	}
+
+	h.logger.Info("Webhook delivery replayed",
+		zap.String("delivery_id", delivery.ID),
+		zap.String("replay_of", delivery.ReplayOf),
+		zap.String("tenant_id", tenantID),
+	)
+
+	h.sendJSON(w, http.StatusAccepted, delivery)
+}
@@ -0,0 +1,54 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerateAPIKey(t *testing.T) {
+	key, prefix, hash, err := GenerateAPIKey()
+	if err != nil {
+		t.Fatalf("GenerateAPIKey: %v", err)
+	}
+
+	if !IsAPIKey(key) || !strings.HasPrefix(key, APIKeyPrefix+prefix+"_") {
+		t.Errorf("key %q does not carry its prefix %q", key, prefix)
+	}
+	if got, ok := ParseAPIKeyPrefix(key); !ok || got != prefix {
+		t.Errorf("ParseAPIKeyPrefix = %q, %v; want %q", got, ok, prefix)
+	}
+	if hash != HashAPIKey(key) || !CompareAPIKey(key, hash) {
+		t.Error("generated hash does not match the key")
+	}
+	if CompareAPIKey(key+"x", hash) || CompareAPIKey(key, HashAPIKey("other")) {
+		t.Error("CompareAPIKey accepted a different key")
+	}
+
+	other, otherPrefix, _, _ := GenerateAPIKey()
+	if other == key || otherPrefix == prefix {
+		t.Error("GenerateAPIKey returned the same key twice")
+	}
+}
+
+func TestParseAPIKeyPrefix(t *testing.T) {
+	tests := []struct {
+		key    string
+		prefix string
+		ok     bool
+	}{
+		{"dgw_abc123_secret", "abc123", true},
+		{"dgw_abc123_sec_ret", "abc123", true},
+		{"dgw_abc123", "", false},
+		{"dgw__secret", "", false},
+		{"dgw_abc123_", "", false},
+		{"abc123_secret", "", false},
+		{"eyJhbGciOiJIUzI1NiJ9.e30.sig", "", false},
+	}
+
+	for _, tt := range tests {
+		prefix, ok := ParseAPIKeyPrefix(tt.key)
+		if prefix != tt.prefix || ok != tt.ok {
+			t.Errorf("ParseAPIKeyPrefix(%q) = %q, %v; want %q, %v", tt.key, prefix, ok, tt.prefix, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,399 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"go.uber.org/zap"
+
+	"github.com/panaroid/domain-gateway/internal/config"
+)
+
+// minRefreshInterval rate-limits JWKS refreshes triggered by unknown key IDs
+const minRefreshInterval = time.Minute
+
+// ErrKeyNotFound is returned when no verification key matches a token
+var ErrKeyNotFound = errors.New("verification key not found")
+
+// KeySet resolves JWT verification keys from a JWKS endpoint and local key files.
+// Keys dropped from the JWKS document are kept for a grace period so tokens
+// signed just before a rotation remain valid.
+type KeySet struct {
+	cfg         config.JWTConfig
+	logger      *zap.Logger
+	client      *http.Client
+	mu          sync.RWMutex
+	keys        map[string]*key
+	lastRefresh time.Time
+	refreshMu   sync.Mutex
+}
+
+// key is a verification key with its source bookkeeping
+type key struct {
+	id        string
+	public    crypto.PublicKey
+	static    bool
+	retiredAt time.Time
+}
+
+// jwk is a single JSON Web Key
+type jwk struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// NewKeySet creates a key set and loads any configured local key files
+func NewKeySet(cfg config.JWTConfig, logger *zap.Logger) (*KeySet, error) {
+	ks := &KeySet{
+		cfg:    cfg,
+		logger: logger,
+		client: &http.Client{Timeout: 10 * time.Second},
+		keys:   make(map[string]*key),
+	}
+
+	for _, path := range cfg.PublicKeyFiles {
+		if err := ks.loadFile(path); err != nil {
+			return nil, err
+		}
+	}
+
+	return ks, nil
+}
+
+// Enabled reports whether asymmetric verification keys are configured
+func (ks *KeySet) Enabled() bool {
+	return ks.cfg.JWKSURL != "" || len(ks.cfg.PublicKeyFiles) > 0
+}
+
+// Start fetches the JWKS document and keeps it refreshed until ctx is done
+func (ks *KeySet) Start(ctx context.Context) {
+	if ks.cfg.JWKSURL == "" {
+		return
+	}
+
+	if err := ks.Refresh(ctx); err != nil {
+		ks.logger.Error("Failed to fetch JWKS", zap.Error(err))
+	}
+
+	interval := ks.cfg.JWKSRefreshInterval
+	if interval <= 0 {
+		interval = time.Hour
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := ks.Refresh(ctx); err != nil {
+					ks.logger.Warn("Failed to refresh JWKS", zap.Error(err))
+				}
+			}
+		}
+	}()
+}
+
+// Refresh fetches the JWKS document, adding new keys and retiring removed ones
+func (ks *KeySet) Refresh(ctx context.Context) error {
+	ks.refreshMu.Lock()
+	defer ks.refreshMu.Unlock()
+
+	return ks.fetch(ctx)
+}
+
+// refreshIfStale refreshes the JWKS unless it was fetched within minRefreshInterval
+func (ks *KeySet) refreshIfStale(ctx context.Context) error {
+	ks.refreshMu.Lock()
+	defer ks.refreshMu.Unlock()
+
+	ks.mu.RLock()
+	stale := time.Since(ks.lastRefresh) > minRefreshInterval
+	ks.mu.RUnlock()
+
+	if !stale {
+		return nil
+	}
+	return ks.fetch(ctx)
+}
+
+func (ks *KeySet) fetch(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ks.cfg.JWKSURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create JWKS request: %w", err)
+	}
+
+	resp, err := ks.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("JWKS endpoint returned status %d", resp.StatusCode)
+	}
+
+	var doc struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return fmt.Errorf("failed to decode JWKS: %w", err)
+	}
+
+	fetched := make(map[string]crypto.PublicKey)
+	for _, k := range doc.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		public, err := k.publicKey()
+		if err != nil {
+			ks.logger.Warn("Skipping invalid JWK", zap.String("kid", k.Kid), zap.Error(err))
+			continue
+		}
+		fetched[k.Kid] = public
+	}
+
+	now := time.Now()
+
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	for id, public := range fetched {
+		ks.keys[id] = &key{id: id, public: public}
+	}
+	for id, k := range ks.keys {
+		if k.static {
+			continue
+		}
+		if _, ok := fetched[id]; ok {
+			continue
+		}
+		if k.retiredAt.IsZero() {
+			k.retiredAt = now
+		}
+		if now.Sub(k.retiredAt) > ks.cfg.KeyGracePeriod {
+			delete(ks.keys, id)
+		}
+	}
+	ks.lastRefresh = now
+
+	ks.logger.Debug("JWKS refreshed", zap.Int("keys", len(fetched)))
+	return nil
+}
+
+// Keyfunc returns the verification key for a parsed token
+func (ks *KeySet) Keyfunc(ctx context.Context) jwt.Keyfunc {
+	return func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); ok {
+			if ks.cfg.Secret == "" {
+				return nil, fmt.Errorf("HMAC tokens are not accepted")
+			}
+			return []byte(ks.cfg.Secret), nil
+		}
+
+		kid, _ := token.Header["kid"].(string)
+		return ks.lookup(ctx, kid, token.Method)
+	}
+}
+
+// ValidMethods returns the signing algorithms accepted by this key set
+func (ks *KeySet) ValidMethods() []string {
+	var methods []string
+	if ks.cfg.Secret != "" {
+		methods = append(methods, "HS256", "HS384", "HS512")
+	}
+	if ks.Enabled() {
+		methods = append(methods,
+			"RS256", "RS384", "RS512",
+			"PS256", "PS384", "PS512",
+			"ES256", "ES384", "ES512",
+			"EdDSA",
+		)
+	}
+	return methods
+}
+
+// lookup selects a key by kid, refreshing the JWKS once if the kid is unknown.
+// Tokens without a kid are checked against every key of a compatible type.
+func (ks *KeySet) lookup(ctx context.Context, kid string, method jwt.SigningMethod) (interface{}, error) {
+	if kid == "" {
+		candidates := ks.compatibleKeys(method)
+		if len(candidates.Keys) == 0 {
+			return nil, ErrKeyNotFound
+		}
+		return candidates, nil
+	}
+
+	if public := ks.find(kid, method); public != nil {
+		return public, nil
+	}
+
+	if ks.cfg.JWKSURL != "" {
+		if err := ks.refreshIfStale(ctx); err != nil {
+			ks.logger.Warn("Failed to refresh JWKS for unknown kid", zap.String("kid", kid), zap.Error(err))
+		}
+		if public := ks.find(kid, method); public != nil {
+			return public, nil
+		}
+	}
+
+	return nil, ErrKeyNotFound
+}
+
+// find returns the key with the given kid if it can verify the signing method
+func (ks *KeySet) find(kid string, method jwt.SigningMethod) crypto.PublicKey {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	if k, ok := ks.keys[kid]; ok && compatible(k.public, method) {
+		return k.public
+	}
+	return nil
+}
+
+// compatibleKeys returns every key that can verify the signing method
+func (ks *KeySet) compatibleKeys(method jwt.SigningMethod) jwt.VerificationKeySet {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	var set jwt.VerificationKeySet
+	for _, k := range ks.keys {
+		if compatible(k.public, method) {
+			set.Keys = append(set.Keys, k.public)
+		}
+	}
+	return set
+}
+
+// loadFile loads a PEM public key; its key ID is the file name without extension
+func (ks *KeySet) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read public key %s: %w", path, err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return fmt.Errorf("no PEM data in %s", path)
+	}
+
+	public, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		cert, certErr := x509.ParseCertificate(block.Bytes)
+		if certErr != nil {
+			return fmt.Errorf("failed to parse public key %s: %w", path, err)
+		}
+		public = cert.PublicKey
+	}
+
+	id := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	ks.keys[id] = &key{id: id, public: public, static: true}
+
+	ks.logger.Info("Loaded JWT public key", zap.String("kid", id), zap.String("path", path))
+	return nil
+}
+
+// compatible reports whether a public key can verify the signing method
+func compatible(public crypto.PublicKey, method jwt.SigningMethod) bool {
+	switch method.(type) {
+	case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS:
+		_, ok := public.(*rsa.PublicKey)
+		return ok
+	case *jwt.SigningMethodECDSA:
+		_, ok := public.(*ecdsa.PublicKey)
+		return ok
+	case *jwt.SigningMethodEd25519:
+		_, ok := public.(ed25519.PublicKey)
+		return ok
+	}
+	return false
+}
+
+// publicKey decodes the JWK into a crypto public key
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid Ed25519 key size %d", len(x))
+		}
+		return ed25519.PublicKey(x), nil
+	}
+
+	return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
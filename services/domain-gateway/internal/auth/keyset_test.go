@@ -0,0 +1,269 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"go.uber.org/zap"
+
+	"github.com/panaroid/domain-gateway/internal/config"
+)
+
+// jwksServer serves a JWKS document that tests can swap out
+type jwksServer struct {
+	*httptest.Server
+	mu      sync.Mutex
+	keys    []jwk
+	fetches atomic.Int32
+}
+
+func newJWKSServer(t *testing.T, keys ...jwk) *jwksServer {
+	s := &jwksServer{keys: keys}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.fetches.Add(1)
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		json.NewEncoder(w).Encode(map[string][]jwk{"keys": s.keys})
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *jwksServer) setKeys(keys ...jwk) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys = keys
+}
+
+func encodeBigInt(n *big.Int) string {
+	return base64.RawURLEncoding.EncodeToString(n.Bytes())
+}
+
+func rsaJWK(kid string, public *rsa.PublicKey) jwk {
+	return jwk{Kid: kid, Kty: "RSA", Use: "sig", N: encodeBigInt(public.N), E: encodeBigInt(big.NewInt(int64(public.E)))}
+}
+
+func ecJWK(kid string, public *ecdsa.PublicKey) jwk {
+	return jwk{Kid: kid, Kty: "EC", Crv: "P-256", X: encodeBigInt(public.X), Y: encodeBigInt(public.Y)}
+}
+
+func edJWK(kid string, public ed25519.PublicKey) jwk {
+	return jwk{Kid: kid, Kty: "OKP", Crv: "Ed25519", X: base64.RawURLEncoding.EncodeToString(public)}
+}
+
+// sign issues a token signed with private, naming kid if it is not empty
+func sign(t *testing.T, method jwt.SigningMethod, kid string, private crypto.PrivateKey) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(method, jwt.MapClaims{"sub": "user-1"})
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(private)
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+	return signed
+}
+
+// verify parses a token against the key set the way the auth middleware does
+func verify(ks *KeySet, token string) error {
+	parser := jwt.NewParser(jwt.WithValidMethods(ks.ValidMethods()))
+	_, err := parser.Parse(token, ks.Keyfunc(context.Background()))
+	return err
+}
+
+func TestKeySetParsesJWKS(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	edPublic, edPrivate, _ := ed25519.GenerateKey(rand.Reader)
+
+	encryption := rsaJWK("enc", &rsaKey.PublicKey)
+	encryption.Use = "enc"
+	server := newJWKSServer(t,
+		rsaJWK("rsa", &rsaKey.PublicKey),
+		ecJWK("ec", &ecKey.PublicKey),
+		edJWK("ed", edPublic),
+		encryption,
+		jwk{Kid: "bad", Kty: "EC", Crv: "P-192"},
+	)
+
+	ks, err := NewKeySet(config.JWTConfig{JWKSURL: server.URL}, zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewKeySet: %v", err)
+	}
+	if err := ks.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+
+	for _, kid := range []string{"rsa", "ec", "ed"} {
+		if _, ok := ks.keys[kid]; !ok {
+			t.Errorf("key %s was not loaded", kid)
+		}
+	}
+	for _, kid := range []string{"enc", "bad"} {
+		if _, ok := ks.keys[kid]; ok {
+			t.Errorf("key %s should have been skipped", kid)
+		}
+	}
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"RS256", sign(t, jwt.SigningMethodRS256, "rsa", rsaKey)},
+		{"PS256", sign(t, jwt.SigningMethodPS256, "rsa", rsaKey)},
+		{"ES256", sign(t, jwt.SigningMethodES256, "ec", ecKey)},
+		{"EdDSA", sign(t, jwt.SigningMethodEdDSA, "ed", edPrivate)},
+	}
+	for _, tt := range tests {
+		if err := verify(ks, tt.token); err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+		}
+	}
+}
+
+func TestKeySetSelectsKeyByKid(t *testing.T) {
+	current, _ := rsa.GenerateKey(rand.Reader, 2048)
+	other, _ := rsa.GenerateKey(rand.Reader, 2048)
+	server := newJWKSServer(t, rsaJWK("current", &current.PublicKey), rsaJWK("other", &other.PublicKey))
+
+	ks, _ := NewKeySet(config.JWTConfig{JWKSURL: server.URL}, zap.NewNop())
+	if err := ks.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+
+	if err := verify(ks, sign(t, jwt.SigningMethodRS256, "current", current)); err != nil {
+		t.Errorf("token with its own kid: %v", err)
+	}
+	if err := verify(ks, sign(t, jwt.SigningMethodRS256, "other", current)); err == nil {
+		t.Error("token signed with a different key than its kid was accepted")
+	}
+	if err := verify(ks, sign(t, jwt.SigningMethodRS256, "", other)); err != nil {
+		t.Errorf("token without a kid: %v", err)
+	}
+
+	// A key of the wrong type for the algorithm is never used
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err := verify(ks, sign(t, jwt.SigningMethodES256, "current", ecKey)); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("ES256 token naming an RSA key = %v, want ErrKeyNotFound", err)
+	}
+}
+
+func TestKeySetRefreshesForUnknownKid(t *testing.T) {
+	old, _ := rsa.GenerateKey(rand.Reader, 2048)
+	rotated, _ := rsa.GenerateKey(rand.Reader, 2048)
+	server := newJWKSServer(t, rsaJWK("old", &old.PublicKey))
+
+	ks, _ := NewKeySet(config.JWTConfig{JWKSURL: server.URL}, zap.NewNop())
+	if err := ks.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+
+	// Unknown kids only refresh once per minRefreshInterval
+	server.setKeys(rsaJWK("old", &old.PublicKey), rsaJWK("new", &rotated.PublicKey))
+	if err := verify(ks, sign(t, jwt.SigningMethodRS256, "new", rotated)); err == nil {
+		t.Error("unknown kid accepted before the refresh interval elapsed")
+	}
+
+	ks.lastRefresh = time.Now().Add(-2 * minRefreshInterval)
+	if err := verify(ks, sign(t, jwt.SigningMethodRS256, "new", rotated)); err != nil {
+		t.Errorf("rotated key after refresh: %v", err)
+	}
+
+	fetches := server.fetches.Load()
+	verify(ks, sign(t, jwt.SigningMethodRS256, "missing", rotated))
+	if server.fetches.Load() != fetches {
+		t.Error("unknown kid refreshed again within the refresh interval")
+	}
+}
+
+func TestKeySetRotationGrace(t *testing.T) {
+	old, _ := rsa.GenerateKey(rand.Reader, 2048)
+	rotated, _ := rsa.GenerateKey(rand.Reader, 2048)
+	server := newJWKSServer(t, rsaJWK("old", &old.PublicKey))
+
+	ks, _ := NewKeySet(config.JWTConfig{JWKSURL: server.URL, KeyGracePeriod: time.Hour}, zap.NewNop())
+	ctx := context.Background()
+	if err := ks.Refresh(ctx); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	token := sign(t, jwt.SigningMethodRS256, "old", old)
+
+	// Dropped from the document, the key stays valid for the grace period
+	server.setKeys(rsaJWK("new", &rotated.PublicKey))
+	if err := ks.Refresh(ctx); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	if err := verify(ks, token); err != nil {
+		t.Errorf("retired key within the grace period: %v", err)
+	}
+
+	ks.keys["old"].retiredAt = time.Now().Add(-2 * time.Hour)
+	if err := ks.Refresh(ctx); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	if _, ok := ks.keys["old"]; ok {
+		t.Error("retired key kept past the grace period")
+	}
+
+	// A key that comes back is no longer retired
+	server.setKeys(rsaJWK("new", &rotated.PublicKey))
+	ks.keys["new"].retiredAt = time.Now().Add(-2 * time.Hour)
+	if err := ks.Refresh(ctx); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	if k, ok := ks.keys["new"]; !ok || !k.retiredAt.IsZero() {
+		t.Error("key present in the document is still retired")
+	}
+}
+
+func TestKeySetLoadsFiles(t *testing.T) {
+	private, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	der, err := x509.MarshalPKIXPublicKey(&private.PublicKey)
+	if err != nil {
+		t.Fatalf("failed to encode key: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "issuer-2024.pem")
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0o600); err != nil {
+		t.Fatalf("failed to write key: %v", err)
+	}
+
+	ks, err := NewKeySet(config.JWTConfig{PublicKeyFiles: []string{path}}, zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewKeySet: %v", err)
+	}
+	if err := verify(ks, sign(t, jwt.SigningMethodES256, "issuer-2024", private)); err != nil {
+		t.Errorf("token for a key file: %v", err)
+	}
+
+	// Without a secret, HMAC tokens are refused
+	hmacToken, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"sub": "user-1"}).SignedString([]byte("guess"))
+	if err := verify(ks, hmacToken); err == nil {
+		t.Error("HMAC token accepted without a configured secret")
+	}
+
+	if _, err := NewKeySet(config.JWTConfig{PublicKeyFiles: []string{filepath.Join(t.TempDir(), "missing.pem")}}, zap.NewNop()); err == nil {
+		t.Error("NewKeySet accepted a missing key file")
+	}
+}
@@ -0,0 +1,68 @@
+package caddy
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+)
+
+// Headers injected into every request proxied to the backend
+const (
+	HeaderTenantID      = "X-Tenant-ID"
+	HeaderDomainID      = "X-Domain-ID"
+	HeaderPrimaryDomain = "X-Primary-Domain"
+	HeaderSignature     = "X-Gateway-Signature"
+)
+
+// tenantHeaderNames lists the headers the backend trusts for tenant identity
+var tenantHeaderNames = []string{
+	HeaderTenantID,
+	HeaderDomainID,
+	HeaderPrimaryDomain,
+	HeaderSignature,
+}
+
+// SignTenant returns the hex-encoded HMAC-SHA256 of "<tenantID>.<domainID>".
+// Caddy cannot compute a signature per request, so this is a static token per
+// route: it proves the headers came through the gateway, not when. Backends
+// must not expect it to change or expire.
+func SignTenant(secret, tenantID, domainID string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%s.%s", tenantID, domainID)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyTenantSignature checks a signature produced by SignTenant
+func VerifyTenantSignature(secret, tenantID, domainID, signature string) bool {
+	expected := SignTenant(secret, tenantID, domainID)
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
+
+// tenantHeaders builds the header set injected for a tenant route
+func (m *Manager) tenantHeaders(route *Route, primary string) map[string][]string {
+	headers := map[string][]string{
+		HeaderTenantID:      {route.TenantID},
+		HeaderDomainID:      {route.ID},
+		HeaderPrimaryDomain: {primary},
+	}
+
+	if m.cfg.SigningSecret != "" {
+		headers[HeaderSignature] = []string{SignTenant(m.cfg.SigningSecret, route.TenantID, route.ID)}
+	}
+
+	return headers
+}
+
+// unsetHeaders lists the tenant headers a route does not set, which are
+// stripped from the client request. Caddy applies deletes after sets, so a
+// header must never be both set and deleted.
+func unsetHeaders(set map[string][]string) []string {
+	var names []string
+	for _, name := range tenantHeaderNames {
+		if _, ok := set[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	return names
+}
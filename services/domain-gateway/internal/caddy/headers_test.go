@@ -0,0 +1,91 @@
+package caddy
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSignTenant(t *testing.T) {
+	const want = "0d8c1f2b68e3a6a1825e004dc2726494c73b003670abb50c30939c876f07be64"
+
+	if got := SignTenant("secret", "tenant-1", "domain-1"); got != want {
+		t.Errorf("SignTenant = %s, want %s", got, want)
+	}
+	if SignTenant("secret", "tenant-1", "domain-2") == want {
+		t.Error("SignTenant does not cover the domain ID")
+	}
+	if SignTenant("secret", "tenant-2", "domain-1") == want {
+		t.Error("SignTenant does not cover the tenant ID")
+	}
+}
+
+func TestVerifyTenantSignature(t *testing.T) {
+	signature := SignTenant("secret", "tenant-1", "domain-1")
+
+	tests := []struct {
+		name      string
+		secret    string
+		tenantID  string
+		domainID  string
+		signature string
+		want      bool
+	}{
+		{"valid", "secret", "tenant-1", "domain-1", signature, true},
+		{"wrong secret", "other", "tenant-1", "domain-1", signature, false},
+		{"other tenant", "secret", "tenant-2", "domain-1", signature, false},
+		{"other domain", "secret", "tenant-1", "domain-2", signature, false},
+		{"empty signature", "secret", "tenant-1", "domain-1", "", false},
+		{"truncated signature", "secret", "tenant-1", "domain-1", signature[:32], false},
+	}
+
+	for _, tt := range tests {
+		if got := VerifyTenantSignature(tt.secret, tt.tenantID, tt.domainID, tt.signature); got != tt.want {
+			t.Errorf("%s: VerifyTenantSignature = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestUnsetHeaders(t *testing.T) {
+	tests := []struct {
+		name string
+		set  map[string][]string
+		want []string
+	}{
+		{
+			name: "nothing set",
+			set:  map[string][]string{},
+			want: tenantHeaderNames,
+		},
+		{
+			name: "unsigned",
+			set: map[string][]string{
+				HeaderTenantID:      {"tenant-1"},
+				HeaderDomainID:      {"domain-1"},
+				HeaderPrimaryDomain: {"shop.example.com"},
+			},
+			want: []string{HeaderSignature},
+		},
+		{
+			name: "signed",
+			set: map[string][]string{
+				HeaderTenantID:      {"tenant-1"},
+				HeaderDomainID:      {"domain-1"},
+				HeaderPrimaryDomain: {"shop.example.com"},
+				HeaderSignature:     {"sig"},
+			},
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		got := unsetHeaders(tt.set)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: unsetHeaders = %v, want %v", tt.name, got, tt.want)
+		}
+		for _, name := range got {
+			if _, ok := tt.set[name]; ok {
+				t.Errorf("%s: %s is both set and deleted", tt.name, name)
+			}
+		}
+	}
+}
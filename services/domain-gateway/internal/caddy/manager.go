@@ -20,16 +20,28 @@ type Manager struct {
 	dnsCfg   config.DNSConfig
 	logger   *zap.Logger
 	adminURL string
+
+	// mu guards the route cache and settings. It is never held across an
+	// admin API call; locksMu, routeLocks and errorsMu order those calls.
 	mu       sync.RWMutex
 	routes   map[string]*Route
+	primary  map[string]string
+	settings map[string]*models.TenantSettings
+	global   *models.GlobalSettings
+
+	locksMu    sync.Mutex
+	routeLocks map[string]*sync.Mutex
+	errorsMu   sync.Mutex
 }
 
 // Route represents a Caddy route
 type Route struct {
+	ID        string
 	Domain    string
 	TenantID  string
 	Upstream  string
 	SSLIssued bool
+	Suspended bool
 }
 
 // CaddyConfig represents the Caddy JSON config structure
@@ -47,30 +59,62 @@ type CaddyHTTPApp struct {
 }
 
 type CaddyHTTPServer struct {
-	Listen []string     `json:"listen"`
+	Listen []string         `json:"listen"`
+	Routes []CaddyRoute     `json:"routes"`
+	Errors *CaddyHTTPErrors `json:"errors,omitempty"`
+}
+
+type CaddyHTTPErrors struct {
 	Routes []CaddyRoute `json:"routes"`
 }
 
 type CaddyRoute struct {
+	ID       string         `json:"@id,omitempty"`
 	Match    []CaddyMatch   `json:"match,omitempty"`
 	Handle   []CaddyHandler `json:"handle"`
 	Terminal bool           `json:"terminal,omitempty"`
 }
 
 type CaddyMatch struct {
-	Host []string `json:"host,omitempty"`
+	Host       []string `json:"host,omitempty"`
+	Expression string   `json:"expression,omitempty"`
 }
 
 type CaddyHandler struct {
-	Handler   string          `json:"handler"`
-	Upstreams []CaddyUpstream `json:"upstreams,omitempty"`
-	Routes    []CaddyRoute    `json:"routes,omitempty"`
+	Handler    string          `json:"handler"`
+	Upstreams  []CaddyUpstream `json:"upstreams,omitempty"`
+	Headers    *CaddyHeaders   `json:"headers,omitempty"`
+	Response   *CaddyHeaderOps `json:"response,omitempty"`
+	StatusCode int             `json:"status_code,omitempty"`
+	Body       string          `json:"body,omitempty"`
+	Routes     []CaddyRoute    `json:"routes,omitempty"`
+
+	HandleResponse []CaddyResponseHandler `json:"handle_response,omitempty"`
+}
+
+// CaddyResponseHandler replaces a proxied response matching Match with Routes
+type CaddyResponseHandler struct {
+	Match  *CaddyResponseMatch `json:"match,omitempty"`
+	Routes []CaddyRoute        `json:"routes"`
+}
+
+type CaddyResponseMatch struct {
+	StatusCode []int `json:"status_code,omitempty"`
 }
 
 type CaddyUpstream struct {
 	Dial string `json:"dial"`
 }
 
+type CaddyHeaders struct {
+	Request *CaddyHeaderOps `json:"request,omitempty"`
+}
+
+type CaddyHeaderOps struct {
+	Set    map[string][]string `json:"set,omitempty"`
+	Delete []string            `json:"delete,omitempty"`
+}
+
 type CaddyTLSApp struct {
 	Automation CaddyTLSAutomation `json:"automation"`
 }
@@ -111,15 +155,44 @@ func NewManager(cfg config.CaddyConfig, dnsCfg config.DNSConfig, logger *zap.Log
 		logger:   logger,
 		adminURL: fmt.Sprintf("http://%s", cfg.AdminAPIAddr),
 		routes:   make(map[string]*Route),
+		primary:  make(map[string]string),
+		settings: make(map[string]*models.TenantSettings),
+
+		routeLocks: make(map[string]*sync.Mutex),
 	}
 }
 
+// LoadSettings seeds the tenant and global settings used when rendering routes.
+// It should be called before BuildConfig.
+func (m *Manager) LoadSettings(tenants []models.TenantSettings, global *models.GlobalSettings) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.settings = make(map[string]*models.TenantSettings)
+	for i := range tenants {
+		settings := tenants[i]
+		m.settings[settings.TenantID] = &settings
+	}
+	m.global = global
+}
+
 // BuildConfig builds the complete Caddy configuration
 func (m *Manager) BuildConfig(domains []models.Domain) *CaddyConfig {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	backend := fmt.Sprintf("%s:%d", m.cfg.BackendHost, m.cfg.BackendPort)
+	backend := m.backend()
+
+	// A full build replaces the route cache
+	m.routes = make(map[string]*Route)
+	m.primary = make(map[string]string)
+
+	// Index primary domains per tenant
+	for _, domain := range domains {
+		if domain.IsPrimary {
+			m.primary[domain.TenantID] = domain.Domain
+		}
+	}
 
 	// Build routes
 	var routes []CaddyRoute
@@ -130,28 +203,18 @@ func (m *Manager) BuildConfig(domains []models.Domain) *CaddyConfig {
 			continue
 		}
 
-		route := CaddyRoute{
-			Match: []CaddyMatch{
-				{Host: []string{domain.Domain}},
-			},
-			Handle: []CaddyHandler{
-				{
-					Handler: "reverse_proxy",
-					Upstreams: []CaddyUpstream{
-						{Dial: backend},
-					},
-				},
-			},
-			Terminal: true,
+		route := &Route{
+			ID:        domain.ID,
+			Domain:    domain.Domain,
+			TenantID:  domain.TenantID,
+			Upstream:  backend,
+			SSLIssued: domain.SSLIssued,
+			Suspended: domain.Suspended,
 		}
-		routes = append(routes, route)
+		routes = append(routes, m.buildRoute(route))
 
 		// Store in local cache
-		m.routes[domain.Domain] = &Route{
-			Domain:   domain.Domain,
-			TenantID: domain.TenantID,
-			Upstream: backend,
-		}
+		m.routes[domain.Domain] = route
 	}
 
 	// Add wildcard subdomain route
@@ -166,6 +229,10 @@ func (m *Manager) BuildConfig(domains []models.Domain) *CaddyConfig {
 					Upstreams: []CaddyUpstream{
 						{Dial: backend},
 					},
+					// Unknown subdomains carry no tenant identity; strip spoofed headers
+					Headers: &CaddyHeaders{
+						Request: &CaddyHeaderOps{Delete: tenantHeaderNames},
+					},
 				},
 			},
 			Terminal: true,
@@ -193,6 +260,9 @@ func (m *Manager) BuildConfig(domains []models.Domain) *CaddyConfig {
 					"main": {
 						Listen: []string{":80", ":443"},
 						Routes: routes,
+						Errors: &CaddyHTTPErrors{
+							Routes: m.buildErrorRoutes(),
+						},
 					},
 				},
 			},
@@ -255,91 +325,397 @@ func (m *Manager) LoadConfig(ctx context.Context, config *CaddyConfig) error {
 
 // AddDomain adds a single domain route dynamically
 func (m *Manager) AddDomain(ctx context.Context, domain *models.Domain) error {
+	lock := m.routeLock(domain.Domain)
+	lock.Lock()
+	defer lock.Unlock()
+
+	route := &Route{
+		ID:        domain.ID,
+		Domain:    domain.Domain,
+		TenantID:  domain.TenantID,
+		Upstream:  m.backend(),
+		SSLIssued: domain.SSLIssued,
+		Suspended: domain.Suspended,
+	}
+
 	m.mu.Lock()
-	defer m.mu.Unlock()
+	if domain.IsPrimary {
+		m.primary[domain.TenantID] = domain.Domain
+	}
+	m.routes[domain.Domain] = route
+	settings := m.settings[domain.TenantID]
+	m.mu.Unlock()
+
+	if err := m.pushLocked(ctx, domain.Domain); err != nil {
+		// Only cache routes Caddy has
+		m.mu.Lock()
+		if m.routes[domain.Domain] == route {
+			delete(m.routes, domain.Domain)
+		}
+		m.mu.Unlock()
+		return err
+	}
+
+	// Extend the tenant's custom error pages to the new host
+	if settings != nil && len(settings.ErrorPages) > 0 {
+		if err := m.pushErrorRoutes(ctx); err != nil {
+			m.logger.Warn("Failed to refresh error routes", zap.Error(err))
+		}
+	}
 
-	backend := fmt.Sprintf("%s:%d", m.cfg.BackendHost, m.cfg.BackendPort)
+	m.logger.Info("Domain route added", zap.String("domain", domain.Domain))
+	return nil
+}
 
-	route := map[string]interface{}{
-		"@id": fmt.Sprintf("route-%s", domain.ID),
-		"match": []map[string]interface{}{
-			{"host": []string{domain.Domain}},
+// SetPrimaryDomain records a tenant's primary domain and refreshes the
+// X-Primary-Domain header on all of the tenant's routes
+func (m *Manager) SetPrimaryDomain(ctx context.Context, tenantID, domain string) error {
+	m.mu.Lock()
+	m.primary[tenantID] = domain
+	m.mu.Unlock()
+
+	if err := m.refreshTenant(ctx, tenantID); err != nil {
+		return err
+	}
+
+	m.logger.Info("Primary domain routes refreshed",
+		zap.String("tenant_id", tenantID),
+		zap.String("domain", domain),
+	)
+	return nil
+}
+
+// ClearPrimaryDomain forgets a tenant's primary domain if it is domain, as
+// when the domain moves to another tenant, and refreshes the tenant's routes
+// so X-Primary-Domain falls back to each route's own host
+func (m *Manager) ClearPrimaryDomain(ctx context.Context, tenantID, domain string) error {
+	m.mu.Lock()
+	if m.primary[tenantID] != domain {
+		m.mu.Unlock()
+		return nil
+	}
+	delete(m.primary, tenantID)
+	m.mu.Unlock()
+
+	if err := m.refreshTenant(ctx, tenantID); err != nil {
+		return err
+	}
+
+	m.logger.Info("Primary domain cleared",
+		zap.String("tenant_id", tenantID),
+		zap.String("domain", domain),
+	)
+	return nil
+}
+
+// ApplyTenantSettings swaps a tenant's routes between proxying and the
+// maintenance page and refreshes its custom error pages
+func (m *Manager) ApplyTenantSettings(ctx context.Context, settings *models.TenantSettings) error {
+	m.mu.Lock()
+	m.settings[settings.TenantID] = settings
+	m.mu.Unlock()
+
+	if err := m.refreshTenant(ctx, settings.TenantID); err != nil {
+		return err
+	}
+	if err := m.pushErrorRoutes(ctx); err != nil {
+		return err
+	}
+
+	m.logger.Info("Tenant settings applied",
+		zap.String("tenant_id", settings.TenantID),
+		zap.Bool("maintenance", settings.MaintenanceMode),
+	)
+	return nil
+}
+
+// SetTenantSuspended replaces all of a tenant's routes with the suspension
+// page, or restores the proxy routes when unsuspending
+func (m *Manager) SetTenantSuspended(ctx context.Context, tenantID string, suspended bool) error {
+	m.mu.Lock()
+	for _, route := range m.routes {
+		if route.TenantID == tenantID {
+			route.Suspended = suspended
+		}
+	}
+	m.mu.Unlock()
+
+	if err := m.refreshTenant(ctx, tenantID); err != nil {
+		return err
+	}
+
+	m.logger.Info("Tenant routes updated",
+		zap.String("tenant_id", tenantID),
+		zap.Bool("suspended", suspended),
+	)
+	return nil
+}
+
+// ApplyGlobalSettings swaps every route between proxying and the global maintenance page
+func (m *Manager) ApplyGlobalSettings(ctx context.Context, global *models.GlobalSettings) error {
+	m.mu.Lock()
+	m.global = global
+	names := make([]string, 0, len(m.routes))
+	for name := range m.routes {
+		names = append(names, name)
+	}
+	m.mu.Unlock()
+
+	for _, name := range names {
+		if err := m.pushRoute(ctx, name); err != nil {
+			return err
+		}
+	}
+
+	m.logger.Info("Global settings applied", zap.Bool("maintenance", global.MaintenanceMode))
+	return nil
+}
+
+// RemoveDomain removes a domain route
+func (m *Manager) RemoveDomain(ctx context.Context, domainID string) error {
+	m.mu.RLock()
+	name := ""
+	for _, route := range m.routes {
+		if route.ID == domainID {
+			name = route.Domain
+		}
+	}
+	m.mu.RUnlock()
+
+	// Serialise with pushes of the same host so none re-adds the route
+	if name != "" {
+		lock := m.routeLock(name)
+		lock.Lock()
+		defer lock.Unlock()
+	}
+
+	m.mu.Lock()
+	for name, route := range m.routes {
+		if route.ID == domainID {
+			delete(m.routes, name)
+		}
+	}
+	m.mu.Unlock()
+
+	status, err := m.send(ctx, http.MethodDelete, "/id/"+routeID(domainID), nil)
+	if err != nil {
+		return fmt.Errorf("failed to remove route: %w", err)
+	}
+	if status != http.StatusOK {
+		m.logger.Warn("Route might not exist", zap.String("domain_id", domainID))
+	}
+
+	m.logger.Info("Domain route removed", zap.String("domain_id", domainID))
+	return nil
+}
+
+// HasDomain reports whether a domain's route is cached
+func (m *Manager) HasDomain(domainID string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, route := range m.routes {
+		if route.ID == domainID {
+			return true
+		}
+	}
+	return false
+}
+
+// GetRouteCount returns the number of active routes
+func (m *Manager) GetRouteCount() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.routes)
+}
+
+// backend returns the upstream dial address
+func (m *Manager) backend() string {
+	return fmt.Sprintf("%s:%d", m.cfg.BackendHost, m.cfg.BackendPort)
+}
+
+// primaryDomain returns the tenant's primary domain, falling back to the route's own domain
+func (m *Manager) primaryDomain(route *Route) string {
+	if primary, ok := m.primary[route.TenantID]; ok && primary != "" {
+		return primary
+	}
+	return route.Domain
+}
+
+// buildRoute renders the Caddy route for a cached domain route
+func (m *Manager) buildRoute(route *Route) CaddyRoute {
+	if route.Suspended {
+		return CaddyRoute{
+			ID: routeID(route.ID),
+			Match: []CaddyMatch{
+				{Host: []string{route.Domain}},
+			},
+			Handle:   staticPage(http.StatusServiceUnavailable, defaultSuspensionPage),
+			Terminal: true,
+		}
+	}
+
+	if page, ok := m.maintenancePage(route.TenantID); ok {
+		return CaddyRoute{
+			ID: routeID(route.ID),
+			Match: []CaddyMatch{
+				{Host: []string{route.Domain}},
+			},
+			Handle:   staticPage(http.StatusServiceUnavailable, page),
+			Terminal: true,
+		}
+	}
+
+	// Set replaces any client-sent value; only headers left unset are deleted
+	headers := m.tenantHeaders(route, m.primaryDomain(route))
+
+	return CaddyRoute{
+		ID: routeID(route.ID),
+		Match: []CaddyMatch{
+			{Host: []string{route.Domain}},
 		},
-		"handle": []map[string]interface{}{
+		Handle: []CaddyHandler{
 			{
-				"handler": "reverse_proxy",
-				"upstreams": []map[string]interface{}{
-					{"dial": backend},
+				Handler: "reverse_proxy",
+				Upstreams: []CaddyUpstream{
+					{Dial: route.Upstream},
 				},
+				Headers: &CaddyHeaders{
+					Request: &CaddyHeaderOps{
+						Delete: unsetHeaders(headers),
+						Set:    headers,
+					},
+				},
+				HandleResponse: m.errorResponses(route.TenantID),
 			},
 		},
-		"terminal": true,
+		Terminal: true,
+	}
+}
+
+// routeLock returns the lock serialising admin API calls for one host
+func (m *Manager) routeLock(name string) *sync.Mutex {
+	m.locksMu.Lock()
+	defer m.locksMu.Unlock()
+
+	lock, ok := m.routeLocks[name]
+	if !ok {
+		lock = &sync.Mutex{}
+		m.routeLocks[name] = lock
 	}
+	return lock
+}
+
+// pushRoute sends a cached route to Caddy. m.mu is held only while rendering,
+// never across the admin API call, so a slow call does not stall other
+// routes. Pushes of one host are serialised and each renders the state at
+// the time it is sent, so the last push always carries the latest state.
+func (m *Manager) pushRoute(ctx context.Context, name string) error {
+	lock := m.routeLock(name)
+	lock.Lock()
+	defer lock.Unlock()
+
+	return m.pushLocked(ctx, name)
+}
 
-	data, err := json.Marshal(route)
+// pushLocked is pushRoute for a caller holding the host's route lock. A route
+// removed since the push was requested is skipped.
+func (m *Manager) pushLocked(ctx context.Context, name string) error {
+	m.mu.RLock()
+	route, ok := m.routes[name]
+	if !ok {
+		m.mu.RUnlock()
+		return nil
+	}
+	id := route.ID
+	data, err := json.Marshal(m.buildRoute(route))
+	m.mu.RUnlock()
 	if err != nil {
 		return fmt.Errorf("failed to marshal route: %w", err)
 	}
 
-	// Add route via PATCH to routes
-	url := fmt.Sprintf("%s/config/apps/http/servers/main/routes", m.adminURL)
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	status, err := m.send(ctx, http.MethodPatch, "/id/"+routeID(id), data)
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return fmt.Errorf("failed to update route: %w", err)
+	}
+	if status == http.StatusOK {
+		return nil
 	}
-	req.Header.Set("Content-Type", "application/json")
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	// Route does not exist yet: insert at the head so it wins over the wildcard
+	status, err = m.send(ctx, http.MethodPut, "/config/apps/http/servers/main/routes/0", data)
 	if err != nil {
 		return fmt.Errorf("failed to add route: %w", err)
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-		return fmt.Errorf("failed to add route: status %d", resp.StatusCode)
+	if status != http.StatusOK && status != http.StatusCreated {
+		return fmt.Errorf("failed to add route: status %d", status)
 	}
 
-	// Store in cache
-	m.routes[domain.Domain] = &Route{
-		Domain:   domain.Domain,
-		TenantID: domain.TenantID,
-		Upstream: backend,
+	return nil
+}
+
+// refreshTenant re-renders all cached routes of a tenant
+func (m *Manager) refreshTenant(ctx context.Context, tenantID string) error {
+	m.mu.RLock()
+	var names []string
+	for name, route := range m.routes {
+		if route.TenantID == tenantID {
+			names = append(names, name)
+		}
 	}
+	m.mu.RUnlock()
 
-	m.logger.Info("Domain route added", zap.String("domain", domain.Domain))
+	for _, name := range names {
+		if err := m.pushRoute(ctx, name); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
-// RemoveDomain removes a domain route
-func (m *Manager) RemoveDomain(ctx context.Context, domainID string) error {
-	m.mu.Lock()
-	defer m.mu.Unlock()
+// pushErrorRoutes replaces the server's handle_errors routes. Like pushRoute
+// it renders under m.mu and sends without it.
+func (m *Manager) pushErrorRoutes(ctx context.Context) error {
+	m.errorsMu.Lock()
+	defer m.errorsMu.Unlock()
 
-	url := fmt.Sprintf("%s/id/route-%s", m.adminURL, domainID)
-	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, url, nil)
+	m.mu.RLock()
+	data, err := json.Marshal(&CaddyHTTPErrors{Routes: m.buildErrorRoutes()})
+	m.mu.RUnlock()
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return fmt.Errorf("failed to marshal error routes: %w", err)
 	}
 
+	status, err := m.send(ctx, http.MethodPost, "/config/apps/http/servers/main/errors", data)
+	if err != nil {
+		return fmt.Errorf("failed to update error routes: %w", err)
+	}
+	if status != http.StatusOK {
+		return fmt.Errorf("failed to update error routes: status %d", status)
+	}
+
+	return nil
+}
+
+// send issues a request against the Caddy admin API and returns the status code
+func (m *Manager) send(ctx context.Context, method, path string, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, method, m.adminURL+path, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
 	client := &http.Client{}
 	resp, err := client.Do(req)
 	if err != nil {
-		return fmt.Errorf("failed to remove route: %w", err)
+		return 0, err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		m.logger.Warn("Route might not exist", zap.String("domain_id", domainID))
-	}
-
-	m.logger.Info("Domain route removed", zap.String("domain_id", domainID))
-	return nil
+	return resp.StatusCode, nil
 }
 
-// GetRouteCount returns the number of active routes
-func (m *Manager) GetRouteCount() int {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
-	return len(m.routes)
+// routeID returns the Caddy @id for a domain route
+func routeID(domainID string) string {
+	return fmt.Sprintf("route-%s", domainID)
 }
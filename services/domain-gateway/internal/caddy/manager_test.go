@@ -0,0 +1,59 @@
+package caddy
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/panaroid/domain-gateway/internal/config"
+	"github.com/panaroid/domain-gateway/pkg/models"
+)
+
+func TestSlowAdminCallDoesNotBlockOtherRoutes(t *testing.T) {
+	release := make(chan struct{})
+	admin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "route-slow") {
+			<-release
+		}
+	}))
+	defer admin.Close()
+	defer close(release)
+
+	m := NewManager(config.CaddyConfig{
+		AdminAPIAddr: strings.TrimPrefix(admin.URL, "http://"),
+		BackendHost:  "backend",
+		BackendPort:  3000,
+	}, config.DNSConfig{}, zap.NewNop())
+
+	ctx := context.Background()
+	go m.AddDomain(ctx, &models.Domain{ID: "slow", TenantID: "tenant-1", Domain: "slow.example.com"})
+
+	// Wait for the slow call to be in flight
+	deadline := time.Now().Add(time.Second)
+	for !m.HasDomain("slow") && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- m.AddDomain(ctx, &models.Domain{ID: "fast", TenantID: "tenant-2", Domain: "fast.example.com"})
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("AddDomain = %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("a slow admin call for one route blocked another route")
+	}
+
+	if got := m.GetRouteCount(); got != 2 {
+		t.Errorf("GetRouteCount = %d, want 2", got)
+	}
+}
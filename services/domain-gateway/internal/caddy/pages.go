@@ -0,0 +1,169 @@
+package caddy
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/panaroid/domain-gateway/pkg/models"
+)
+
+// pageTemplate is the shell for the built-in status pages
+const pageTemplate = `<!DOCTYPE html>
+<html lang="ar" dir="rtl">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>%[1]s</title>
+<style>body{font-family:system-ui,sans-serif;display:flex;align-items:center;justify-content:center;min-height:100vh;margin:0;background:#f8fafc;color:#0f172a}main{text-align:center;padding:2rem}h1{font-size:1.5rem}p{color:#475569}</style>
+</head>
+<body><main><h1>%[1]s</h1><p>%[2]s</p></main></body>
+</html>`
+
+var (
+	defaultMaintenancePage = fmt.Sprintf(pageTemplate,
+		"الموقع تحت الصيانة",
+		"نعمل على تحسين الموقع وسنعود قريباً. Under maintenance, please check back soon.",
+	)
+
+	defaultSuspensionPage = fmt.Sprintf(pageTemplate,
+		"الموقع موقوف مؤقتاً",
+		"هذا الموقع غير متاح حالياً. This site is currently unavailable.",
+	)
+
+	defaultErrorPages = map[string]string{
+		"502": fmt.Sprintf(pageTemplate,
+			"الموقع غير متاح مؤقتاً",
+			"تعذر الوصول إلى الخادم، يرجى المحاولة بعد قليل. The site is temporarily unavailable.",
+		),
+		"503": fmt.Sprintf(pageTemplate,
+			"الخدمة غير متاحة",
+			"يرجى المحاولة بعد قليل. Service unavailable, please try again shortly.",
+		),
+	}
+)
+
+// maintenancePage returns the page to serve if the tenant or the whole gateway
+// is in maintenance mode
+func (m *Manager) maintenancePage(tenantID string) (string, bool) {
+	settings := m.settings[tenantID]
+
+	if settings != nil && settings.MaintenanceMode {
+		if settings.MaintenancePage != "" {
+			return settings.MaintenancePage, true
+		}
+		if m.global != nil && m.global.MaintenancePage != "" {
+			return m.global.MaintenancePage, true
+		}
+		return defaultMaintenancePage, true
+	}
+
+	if m.global != nil && m.global.MaintenanceMode {
+		if m.global.MaintenancePage != "" {
+			return m.global.MaintenancePage, true
+		}
+		return defaultMaintenancePage, true
+	}
+
+	return "", false
+}
+
+// staticPage renders handlers that serve an HTML body with a fixed status
+func staticPage(status int, body string) []CaddyHandler {
+	return []CaddyHandler{
+		{
+			Handler: "headers",
+			Response: &CaddyHeaderOps{
+				Set: map[string][]string{
+					"Content-Type":  {"text/html; charset=utf-8"},
+					"Cache-Control": {"no-store"},
+				},
+			},
+		},
+		{
+			Handler:    "static_response",
+			StatusCode: status,
+			Body:       body,
+		},
+	}
+}
+
+// errorResponses renders the handle_response entries that replace backend
+// responses with the tenant's custom pages. handle_errors only sees errors
+// raised by Caddy itself, such as an unreachable backend, so a 404 or a 503
+// returned by the backend is only replaced here.
+func (m *Manager) errorResponses(tenantID string) []CaddyResponseHandler {
+	settings := m.settings[tenantID]
+	if settings == nil {
+		return nil
+	}
+
+	var handlers []CaddyResponseHandler
+	for _, code := range models.CustomErrorPageCodes {
+		page := settings.ErrorPages[code]
+		if page == "" {
+			continue
+		}
+		status, _ := strconv.Atoi(code)
+		handlers = append(handlers, CaddyResponseHandler{
+			Match:  &CaddyResponseMatch{StatusCode: []int{status}},
+			Routes: []CaddyRoute{{Handle: staticPage(status, page)}},
+		})
+	}
+	return handlers
+}
+
+// buildErrorRoutes renders the handle_errors routes for errors raised by
+// Caddy: per-tenant custom pages first, then the built-in defaults
+func (m *Manager) buildErrorRoutes() []CaddyRoute {
+	hostsByTenant := make(map[string][]string)
+	for _, route := range m.routes {
+		hostsByTenant[route.TenantID] = append(hostsByTenant[route.TenantID], route.Domain)
+	}
+
+	tenantIDs := make([]string, 0, len(m.settings))
+	for tenantID := range m.settings {
+		tenantIDs = append(tenantIDs, tenantID)
+	}
+	sort.Strings(tenantIDs)
+
+	var routes []CaddyRoute
+	for _, tenantID := range tenantIDs {
+		hosts := hostsByTenant[tenantID]
+		if len(hosts) == 0 {
+			continue
+		}
+		sort.Strings(hosts)
+
+		for _, code := range models.CustomErrorPageCodes {
+			page := m.settings[tenantID].ErrorPages[code]
+			if page == "" {
+				continue
+			}
+			routes = append(routes, errorRoute(hosts, code, page))
+		}
+	}
+
+	for _, code := range models.CustomErrorPageCodes {
+		if page, ok := defaultErrorPages[code]; ok {
+			routes = append(routes, errorRoute(nil, code, page))
+		}
+	}
+
+	return routes
+}
+
+// errorRoute renders a handle_errors route for a status code
+func errorRoute(hosts []string, code, page string) CaddyRoute {
+	status, _ := strconv.Atoi(code)
+	return CaddyRoute{
+		Match: []CaddyMatch{
+			{
+				Host:       hosts,
+				Expression: fmt.Sprintf("{http.error.status_code} == %s", code),
+			},
+		},
+		Handle:   staticPage(status, page),
+		Terminal: true,
+	}
+}
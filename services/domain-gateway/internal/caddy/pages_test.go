@@ -0,0 +1,39 @@
+package caddy
+
+import (
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/panaroid/domain-gateway/internal/config"
+	"github.com/panaroid/domain-gateway/pkg/models"
+)
+
+func TestBuildRouteServesCustomErrorPages(t *testing.T) {
+	m := NewManager(config.CaddyConfig{BackendHost: "backend", BackendPort: 3000}, config.DNSConfig{}, zap.NewNop())
+	m.LoadSettings([]models.TenantSettings{{
+		TenantID:   "tenant-1",
+		ErrorPages: map[string]string{"404": "not here", "503": "back soon"},
+	}}, nil)
+
+	route := m.buildRoute(&Route{ID: "1", Domain: "shop.example.com", TenantID: "tenant-1", Upstream: "backend:3000"})
+	handlers := route.Handle[0].HandleResponse
+	if len(handlers) != 2 {
+		t.Fatalf("handle_response = %+v, want the 404 and 503 pages", handlers)
+	}
+
+	want := map[int]string{404: "not here", 503: "back soon"}
+	for _, h := range handlers {
+		status := h.Match.StatusCode[0]
+		page := h.Routes[0].Handle[1]
+		if page.StatusCode != status || page.Body != want[status] {
+			t.Errorf("response for %d = %d %q, want %q", status, page.StatusCode, page.Body, want[status])
+		}
+	}
+
+	// Tenants without custom pages get the backend's response unchanged
+	other := m.buildRoute(&Route{ID: "2", Domain: "other.example.com", TenantID: "tenant-2", Upstream: "backend:3000"})
+	if len(other.Handle[0].HandleResponse) != 0 {
+		t.Errorf("handle_response = %+v for a tenant without pages", other.Handle[0].HandleResponse)
+	}
+}
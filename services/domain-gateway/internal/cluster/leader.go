@@ -0,0 +1,155 @@
+package cluster
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"hash/fnv"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/panaroid/domain-gateway/internal/database"
+)
+
+// Elector holds a Postgres advisory lock to make one instance the leader for a
+// named role. The lock lives on a dedicated connection, so it is released by
+// the server as soon as the leader's session ends and another instance takes
+// over within one retry interval.
+type Elector struct {
+	db       *database.DB
+	name     string
+	key      int64
+	interval time.Duration
+	logger   *zap.Logger
+	stopCh   chan struct{}
+	wg       sync.WaitGroup
+
+	// conn is only used by the campaign goroutine, and by Stop once it has
+	// exited. mu guards leader alone, so IsLeader never waits on the network.
+	conn   *sql.Conn
+	mu     sync.RWMutex
+	leader bool
+}
+
+// NewElector creates an elector for the named role
+func NewElector(db *database.DB, name string, interval time.Duration, logger *zap.Logger) *Elector {
+	h := fnv.New64a()
+	h.Write([]byte(name))
+
+	return &Elector{
+		db:       db,
+		name:     name,
+		key:      int64(h.Sum64()),
+		interval: interval,
+		logger:   logger.With(zap.String("role", name)),
+		stopCh:   make(chan struct{}),
+	}
+}
+
+// Start campaigns for leadership until Stop is called
+func (e *Elector) Start(ctx context.Context) {
+	e.wg.Add(1)
+	go e.run(ctx)
+	e.logger.Info("Leader election started", zap.Duration("retry_interval", e.interval))
+}
+
+// Stop stops campaigning and releases leadership if held
+func (e *Elector) Stop() {
+	close(e.stopCh)
+	e.wg.Wait()
+
+	if e.conn != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if _, err := e.conn.ExecContext(ctx, `SELECT pg_advisory_unlock($1)`, e.key); err != nil {
+			e.logger.Warn("Failed to release leadership", zap.Error(err))
+		}
+		e.release()
+	}
+
+	e.logger.Info("Leader election stopped")
+}
+
+// IsLeader reports whether this instance currently holds leadership
+func (e *Elector) IsLeader() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.leader
+}
+
+func (e *Elector) run(ctx context.Context) {
+	defer e.wg.Done()
+
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	e.campaign(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-e.stopCh:
+			return
+		case <-ticker.C:
+			e.campaign(ctx)
+		}
+	}
+}
+
+// campaign tries to acquire leadership, or checks that it is still held.
+// The database calls run without mu; only the outcome is published under it.
+func (e *Elector) campaign(ctx context.Context) {
+	checkCtx, cancel := context.WithTimeout(ctx, e.interval)
+	defer cancel()
+
+	if e.conn != nil {
+		// The lock is only as alive as its session
+		if err := e.conn.PingContext(checkCtx); err != nil {
+			e.logger.Warn("Lost leadership", zap.Error(err))
+			e.release()
+		}
+		return
+	}
+
+	conn, err := e.db.Conn(checkCtx)
+	if err != nil {
+		e.logger.Warn("Failed to open leader election connection", zap.Error(err))
+		return
+	}
+
+	var acquired bool
+	if err := conn.QueryRowContext(checkCtx, `SELECT pg_try_advisory_lock($1)`, e.key).Scan(&acquired); err != nil {
+		e.logger.Warn("Failed to try leader lock", zap.Error(err))
+		conn.Close()
+		return
+	}
+	if !acquired {
+		conn.Close()
+		return
+	}
+
+	e.conn = conn
+	e.setLeader(true)
+	e.logger.Info("Acquired leadership", zap.String("instance_id", e.db.InstanceID()))
+}
+
+// release gives up leadership and drops the lock connection
+func (e *Elector) release() {
+	e.setLeader(false)
+
+	// Closing a pooled conn returns it to the pool with the lock still held,
+	// so the session is discarded instead
+	e.conn.Raw(func(interface{}) error { return driver.ErrBadConn })
+	e.conn.Close()
+	e.conn = nil
+}
+
+// setLeader publishes whether this instance holds leadership
+func (e *Elector) setLeader(leader bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.leader = leader
+}
@@ -0,0 +1,68 @@
+package cluster
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/panaroid/domain-gateway/internal/config"
+	"github.com/panaroid/domain-gateway/internal/database"
+)
+
+// testDatabaseURL names a Postgres database for the election test; advisory
+// locks have no SQLite equivalent
+const testDatabaseURL = "GATEWAY_TEST_DATABASE_URL"
+
+func TestElectorHandsOverLeadership(t *testing.T) {
+	url := os.Getenv(testDatabaseURL)
+	if url == "" {
+		t.Skip(testDatabaseURL + " not set")
+	}
+
+	open := func() *database.DB {
+		db, err := database.New(config.DatabaseConfig{
+			URL:             url,
+			MaxOpenConns:    2,
+			MaxIdleConns:    2,
+			ConnMaxLifetime: time.Minute,
+		}, zap.NewNop())
+		if err != nil {
+			t.Fatalf("failed to open database: %v", err)
+		}
+		t.Cleanup(func() { db.Close() })
+		return db
+	}
+
+	// Each elector gets its own pool, as separate instances would
+	name := "test-" + t.Name()
+	first := NewElector(open(), name, time.Second, zap.NewNop())
+	second := NewElector(open(), name, time.Second, zap.NewNop())
+	ctx := context.Background()
+
+	first.campaign(ctx)
+	second.campaign(ctx)
+	if !first.IsLeader() || second.IsLeader() {
+		t.Fatalf("leaders = %v, %v; want only the first", first.IsLeader(), second.IsLeader())
+	}
+
+	// A held lock is kept across campaigns
+	first.campaign(ctx)
+	second.campaign(ctx)
+	if !first.IsLeader() || second.IsLeader() {
+		t.Fatalf("leaders = %v, %v; want the first to stay leader", first.IsLeader(), second.IsLeader())
+	}
+
+	first.Stop()
+	if first.IsLeader() {
+		t.Error("stopped elector still reports leadership")
+	}
+
+	second.campaign(ctx)
+	if !second.IsLeader() {
+		t.Error("leadership was not taken over after the leader stopped")
+	}
+	second.Stop()
+}
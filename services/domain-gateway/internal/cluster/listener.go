@@ -0,0 +1,283 @@
+package cluster
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+
+	"github.com/panaroid/domain-gateway/internal/caddy"
+	"github.com/panaroid/domain-gateway/internal/config"
+	"github.com/panaroid/domain-gateway/internal/database"
+	"github.com/panaroid/domain-gateway/internal/events"
+	"github.com/panaroid/domain-gateway/internal/resolver"
+	"github.com/panaroid/domain-gateway/pkg/models"
+)
+
+const (
+	// catchUpOverlap re-reads recent changes so rows committed out of order are not missed
+	catchUpOverlap = time.Minute
+
+	// pollInterval catches up even if a notification was lost
+	pollInterval = 30 * time.Second
+
+	// retention is how long change rows are kept for lagging instances
+	retention = time.Hour
+
+	// batchSize caps the changes applied per catch-up query
+	batchSize = 500
+)
+
+// changeLog is the part of the change outbox the listener reads
+type changeLog interface {
+	Since(ctx context.Context, since time.Time, afterSeq int64, limit int) ([]database.Change, error)
+	Latest(ctx context.Context) (time.Time, error)
+	Prune(ctx context.Context, before time.Time) (int64, error)
+}
+
+// Listener applies changes committed by other gateway instances to this
+// instance's Caddy routes, resolver index and event broker. Notifications only
+// trigger a catch-up read of the change outbox, so nothing is lost across
+// reconnects.
+type Listener struct {
+	db           *database.DB
+	instanceID   string
+	changes      changeLog
+	repo         database.DomainStore
+	settingsRepo database.SettingsStore
+	caddyManager *caddy.Manager
+	resolver     *resolver.Resolver
+	broker       *events.Broker
+	cfg          config.DatabaseConfig
+	logger       *zap.Logger
+	listener     *pq.Listener
+	cursor       time.Time
+	applied      map[int64]time.Time
+	stopCh       chan struct{}
+	wg           sync.WaitGroup
+}
+
+// NewListener creates a new change listener
+func NewListener(
+	db *database.DB,
+	changes *database.ChangeRepository,
+	repo database.DomainStore,
+	settingsRepo database.SettingsStore,
+	caddyManager *caddy.Manager,
+	resolver *resolver.Resolver,
+	broker *events.Broker,
+	cfg config.DatabaseConfig,
+	logger *zap.Logger,
+) *Listener {
+	return &Listener{
+		db:           db,
+		instanceID:   db.InstanceID(),
+		changes:      changes,
+		repo:         repo,
+		settingsRepo: settingsRepo,
+		caddyManager: caddyManager,
+		resolver:     resolver,
+		broker:       broker,
+		cfg:          cfg,
+		logger:       logger,
+		applied:      make(map[int64]time.Time),
+		stopCh:       make(chan struct{}),
+	}
+}
+
+// Start listens for change notifications. Changes committed before Start are
+// assumed to be part of the state loaded at startup.
+func (l *Listener) Start(ctx context.Context) error {
+	cursor, err := l.changes.Latest(ctx)
+	if err != nil {
+		return err
+	}
+	l.cursor = cursor
+
+	l.listener = pq.NewListener(l.cfg.URL, time.Second, 30*time.Second, func(event pq.ListenerEventType, err error) {
+		switch event {
+		case pq.ListenerEventDisconnected:
+			l.logger.Warn("Change listener disconnected", zap.Error(err))
+		case pq.ListenerEventReconnected:
+			l.logger.Info("Change listener reconnected")
+		case pq.ListenerEventConnectionAttemptFailed:
+			l.logger.Warn("Change listener connection attempt failed", zap.Error(err))
+		}
+	})
+	if err := l.listener.Listen(database.ChangeChannel); err != nil {
+		l.listener.Close()
+		return err
+	}
+
+	l.wg.Add(1)
+	go l.run(ctx)
+	l.logger.Info("Change listener started", zap.String("instance_id", l.instanceID))
+	return nil
+}
+
+// Stop stops the change listener
+func (l *Listener) Stop() {
+	close(l.stopCh)
+	l.wg.Wait()
+	l.listener.Close()
+	l.logger.Info("Change listener stopped")
+}
+
+func (l *Listener) run(ctx context.Context) {
+	defer l.wg.Done()
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	lastPrune := time.Now()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-l.stopCh:
+			return
+
+		case n := <-l.listener.Notify:
+			// A nil notification follows a reconnect; anything may have been missed
+			if n != nil && n.Extra == l.instanceID {
+				continue
+			}
+			l.catchUp(ctx)
+
+		case <-ticker.C:
+			l.catchUp(ctx)
+
+			if time.Since(lastPrune) > retention/4 {
+				lastPrune = time.Now()
+				if _, err := l.changes.Prune(ctx, time.Now().Add(-retention)); err != nil {
+					l.logger.Warn("Failed to prune change outbox", zap.Error(err))
+				}
+			}
+		}
+	}
+}
+
+// catchUp applies every change from other instances not yet applied. The
+// window reaches back catchUpOverlap before the cursor to pick up late
+// commits, and is paged by sequence number so a window holding more than
+// batchSize changes is still read to the end.
+func (l *Listener) catchUp(ctx context.Context) {
+	since := l.cursor.Add(-catchUpOverlap)
+	var lastSeq int64
+	for {
+		changes, err := l.changes.Since(ctx, since, lastSeq, batchSize)
+		if err != nil {
+			l.logger.Error("Failed to read change outbox", zap.Error(err))
+			return
+		}
+
+		for i := range changes {
+			change := &changes[i]
+			lastSeq = change.Seq
+			if _, ok := l.applied[change.Seq]; ok {
+				continue
+			}
+			l.applied[change.Seq] = change.CreatedAt
+			if change.CreatedAt.After(l.cursor) {
+				l.cursor = change.CreatedAt
+			}
+
+			if change.InstanceID == l.instanceID {
+				continue
+			}
+			l.apply(ctx, change)
+		}
+
+		for seq, at := range l.applied {
+			if at.Before(l.cursor.Add(-2 * catchUpOverlap)) {
+				delete(l.applied, seq)
+			}
+		}
+
+		if len(changes) < batchSize {
+			return
+		}
+	}
+}
+
+// apply updates local state for one change
+func (l *Listener) apply(ctx context.Context, change *database.Change) {
+	logger := l.logger.With(
+		zap.Int64("seq", change.Seq),
+		zap.String("kind", change.Kind),
+		zap.String("tenant_id", change.TenantID),
+	)
+
+	switch change.Kind {
+	case database.ChangeDomain:
+		l.applyDomain(ctx, change, logger)
+
+	case database.ChangeTenant:
+		settings, err := l.settingsRepo.GetTenant(ctx, change.TenantID)
+		if err != nil {
+			logger.Error("Failed to load tenant settings", zap.Error(err))
+			return
+		}
+		if err := l.caddyManager.ApplyTenantSettings(ctx, settings); err != nil {
+			logger.Warn("Failed to apply tenant settings to Caddy", zap.Error(err))
+		}
+		l.resolver.InvalidateTenant(change.TenantID)
+
+	case database.ChangeGlobal:
+		global, err := l.settingsRepo.GetGlobal(ctx)
+		if err != nil {
+			logger.Error("Failed to load global settings", zap.Error(err))
+			return
+		}
+		if err := l.caddyManager.ApplyGlobalSettings(ctx, global); err != nil {
+			logger.Warn("Failed to apply global settings to Caddy", zap.Error(err))
+		}
+	}
+
+	logger.Debug("Applied change from another instance", zap.String("from", change.InstanceID))
+}
+
+// applyDomain reconciles a domain's route with its current database state
+func (l *Listener) applyDomain(ctx context.Context, change *database.Change, logger *zap.Logger) {
+	domain, err := l.repo.GetByID(ctx, change.DomainID)
+	if err != nil {
+		logger.Error("Failed to load changed domain", zap.Error(err))
+		return
+	}
+
+	switch {
+	case domain == nil || !domain.Verified:
+		if l.caddyManager.HasDomain(change.DomainID) {
+			if err := l.caddyManager.RemoveDomain(ctx, change.DomainID); err != nil {
+				logger.Warn("Failed to remove domain from Caddy", zap.Error(err))
+			}
+		}
+
+	case change.Event != nil && change.Event.Action == models.EventDomainPrimarySet:
+		if err := l.caddyManager.SetPrimaryDomain(ctx, domain.TenantID, domain.Domain); err != nil {
+			logger.Warn("Failed to set primary domain in Caddy", zap.Error(err))
+		}
+
+	default:
+		if err := l.caddyManager.AddDomain(ctx, domain); err != nil {
+			logger.Warn("Failed to update domain in Caddy", zap.Error(err))
+		}
+	}
+
+	// A change recorded for a tenant the domain has left is the previous
+	// owner's copy of a move; the domain is no longer its primary
+	if domain != nil && domain.TenantID != change.TenantID {
+		if err := l.caddyManager.ClearPrimaryDomain(ctx, change.TenantID, domain.Domain); err != nil {
+			logger.Warn("Failed to clear primary domain in Caddy", zap.Error(err))
+		}
+	}
+
+	l.resolver.InvalidateTenant(change.TenantID)
+	if change.Event != nil {
+		l.resolver.Invalidate(change.Event.Domain)
+		l.broker.Publish(*change.Event)
+	}
+}
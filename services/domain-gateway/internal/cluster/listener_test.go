@@ -0,0 +1,84 @@
+package cluster
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/panaroid/domain-gateway/internal/database"
+)
+
+// memoryChangeLog is a change outbox held in memory, ordered by seq
+type memoryChangeLog struct {
+	changes []database.Change
+}
+
+func (m *memoryChangeLog) Since(_ context.Context, since time.Time, afterSeq int64, limit int) ([]database.Change, error) {
+	var page []database.Change
+	for _, change := range m.changes {
+		if change.CreatedAt.Before(since) || change.Seq <= afterSeq {
+			continue
+		}
+		page = append(page, change)
+		if len(page) == limit {
+			break
+		}
+	}
+	return page, nil
+}
+
+func (m *memoryChangeLog) Latest(context.Context) (time.Time, error) {
+	return m.changes[len(m.changes)-1].CreatedAt, nil
+}
+
+func (m *memoryChangeLog) Prune(context.Context, time.Time) (int64, error) {
+	return 0, nil
+}
+
+func TestCatchUpReadsPastAFullWindow(t *testing.T) {
+	start := time.Now().UTC()
+	log := &memoryChangeLog{}
+
+	// More changes than one batch, all inside the overlap window. They come
+	// from this instance so catching up only records them as applied.
+	total := 2*batchSize + 50
+	for i := 0; i < total; i++ {
+		log.changes = append(log.changes, database.Change{
+			Seq:        int64(i + 1),
+			Kind:       database.ChangeDomain,
+			InstanceID: "self",
+			CreatedAt:  start.Add(time.Duration(i) * time.Millisecond),
+		})
+	}
+
+	l := &Listener{
+		instanceID: "self",
+		changes:    log,
+		logger:     zap.NewNop(),
+		cursor:     start,
+		applied:    make(map[int64]time.Time),
+	}
+	l.catchUp(context.Background())
+
+	if len(l.applied) != total {
+		t.Fatalf("applied %d changes, want %d", len(l.applied), total)
+	}
+	if last := log.changes[total-1].CreatedAt; !l.cursor.Equal(last) {
+		t.Errorf("cursor = %v, want %v", l.cursor, last)
+	}
+
+	// A later change is still picked up by the next catch-up
+	log.changes = append(log.changes, database.Change{
+		Seq:        int64(total + 1),
+		Kind:       database.ChangeDomain,
+		InstanceID: "self",
+		CreatedAt:  start.Add(time.Second),
+	})
+	l.catchUp(context.Background())
+
+	if _, ok := l.applied[int64(total+1)]; !ok {
+		t.Error("change after a full window was not read")
+	}
+}
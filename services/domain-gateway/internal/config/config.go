@@ -1,6 +1,7 @@
 package config
 
 import (
+	"errors"
 	"strings"
 	"time"
 
@@ -16,14 +17,17 @@ type Config struct {
 	DNS      DNSConfig
 	JWT      JWTConfig
 	Worker   WorkerConfig
+	Resolver ResolverConfig
+	Webhook  WebhookConfig
 }
 
 // ServerConfig holds server-related configuration
 type ServerConfig struct {
-	APIPort     int    `mapstructure:"api_port"`
-	HTTPPort    int    `mapstructure:"http_port"`
-	HTTPSPort   int    `mapstructure:"https_port"`
-	Environment string `mapstructure:"environment"`
+	APIPort       int    `mapstructure:"api_port"`
+	HTTPPort      int    `mapstructure:"http_port"`
+	HTTPSPort     int    `mapstructure:"https_port"`
+	Environment   string `mapstructure:"environment"`
+	InternalToken string `mapstructure:"internal_token"`
 }
 
 // DatabaseConfig holds database configuration
@@ -36,12 +40,13 @@ type DatabaseConfig struct {
 
 // CaddyConfig holds Caddy-related configuration
 type CaddyConfig struct {
-	AdminAPIAddr string `mapstructure:"admin_api_addr"`
-	StoragePath  string `mapstructure:"storage_path"`
-	Email        string `mapstructure:"acme_email"`
-	BaseDomain   string `mapstructure:"base_domain"`
-	BackendHost  string `mapstructure:"backend_host"`
-	BackendPort  int    `mapstructure:"backend_port"`
+	AdminAPIAddr  string `mapstructure:"admin_api_addr"`
+	StoragePath   string `mapstructure:"storage_path"`
+	Email         string `mapstructure:"acme_email"`
+	BaseDomain    string `mapstructure:"base_domain"`
+	BackendHost   string `mapstructure:"backend_host"`
+	BackendPort   int    `mapstructure:"backend_port"`
+	SigningSecret string `mapstructure:"signing_secret"`
 }
 
 // DNSConfig holds DNS provider configuration
@@ -53,15 +58,54 @@ type DNSConfig struct {
 
 // JWTConfig holds JWT authentication configuration
 type JWTConfig struct {
-	Secret     string        `mapstructure:"secret"`
-	Expiration time.Duration `mapstructure:"expiration"`
-	Issuer     string        `mapstructure:"issuer"`
+	Secret              string        `mapstructure:"secret"`
+	Expiration          time.Duration `mapstructure:"expiration"`
+	Issuer              string        `mapstructure:"issuer"`
+	Audience            string        `mapstructure:"audience"`
+	JWKSURL             string        `mapstructure:"jwks_url"`
+	PublicKeyFiles      []string      `mapstructure:"public_key_files"`
+	JWKSRefreshInterval time.Duration `mapstructure:"jwks_refresh_interval"`
+	KeyGracePeriod      time.Duration `mapstructure:"key_grace_period"`
+	DefaultRole         string        `mapstructure:"default_role"`
+	LegacyAdminRole     bool          `mapstructure:"legacy_admin_role"`
+	ImpersonationWrites bool          `mapstructure:"impersonation_writes"`
 }
 
 // WorkerConfig holds background worker configuration
 type WorkerConfig struct {
 	VerificationInterval time.Duration `mapstructure:"verification_interval"`
 	MaxRetries           int           `mapstructure:"max_retries"`
+	CertCheckInterval    time.Duration `mapstructure:"cert_check_interval"`
+	CertExpiryWarning    time.Duration `mapstructure:"cert_expiry_warning"`
+	Sharded              bool          `mapstructure:"sharded"`
+	LeaderRetryInterval  time.Duration `mapstructure:"leader_retry_interval"`
+	Concurrency          int           `mapstructure:"concurrency"`
+	LookupTimeout        time.Duration `mapstructure:"lookup_timeout"`
+	CycleBudget          time.Duration `mapstructure:"cycle_budget"`
+	MaxPerCycle          int           `mapstructure:"max_per_cycle"`
+	BackoffMax           time.Duration `mapstructure:"backoff_max"`
+	VerificationWindow   time.Duration `mapstructure:"verification_window"`
+	ReverifyInterval     time.Duration `mapstructure:"reverify_interval"`
+	MisconfiguredAfter   int           `mapstructure:"misconfigured_after"`
+	MisconfiguredGrace   time.Duration `mapstructure:"misconfigured_grace"`
+	TrashRetention       time.Duration `mapstructure:"trash_retention"`
+	TrashPurgeInterval   time.Duration `mapstructure:"trash_purge_interval"`
+}
+
+// ResolverConfig holds host-to-tenant resolver configuration
+type ResolverConfig struct {
+	CacheTTL         time.Duration `mapstructure:"cache_ttl"`
+	NegativeCacheTTL time.Duration `mapstructure:"negative_cache_ttl"`
+}
+
+// WebhookConfig holds outgoing webhook delivery configuration
+type WebhookConfig struct {
+	PollInterval time.Duration `mapstructure:"poll_interval"`
+	Timeout      time.Duration `mapstructure:"timeout"`
+	MaxAttempts  int           `mapstructure:"max_attempts"`
+	BackoffBase  time.Duration `mapstructure:"backoff_base"`
+	BackoffMax   time.Duration `mapstructure:"backoff_max"`
+	BatchSize    int           `mapstructure:"batch_size"`
 }
 
 // Load loads configuration from environment and config file
@@ -86,10 +130,42 @@ func Load(logger *zap.Logger) (*Config, error) {
 	v.SetDefault("dns.provider", "cloudflare")
 
 	v.SetDefault("jwt.expiration", "24h")
-	v.SetDefault("jwt.issuer", "domain-gateway")
+	// No issuer by default: requiring one rejects existing tokens without an iss claim
+	v.SetDefault("jwt.issuer", "")
+	v.SetDefault("jwt.jwks_refresh_interval", "1h")
+	v.SetDefault("jwt.key_grace_period", "24h")
+	v.SetDefault("jwt.default_role", "viewer")
+	// Lets tokens issued before platform_admin existed keep the admin routes
+	v.SetDefault("jwt.legacy_admin_role", false)
+	v.SetDefault("jwt.impersonation_writes", false)
 
 	v.SetDefault("worker.verification_interval", "5m")
 	v.SetDefault("worker.max_retries", 3)
+	v.SetDefault("worker.cert_check_interval", "12h")
+	v.SetDefault("worker.cert_expiry_warning", "336h")
+	v.SetDefault("worker.sharded", false)
+	v.SetDefault("worker.leader_retry_interval", "5s")
+	v.SetDefault("worker.concurrency", 10)
+	v.SetDefault("worker.lookup_timeout", "10s")
+	v.SetDefault("worker.cycle_budget", "4m")
+	v.SetDefault("worker.max_per_cycle", 1000)
+	v.SetDefault("worker.backoff_max", "6h")
+	v.SetDefault("worker.verification_window", "168h")
+	v.SetDefault("worker.reverify_interval", "24h")
+	v.SetDefault("worker.misconfigured_after", 3)
+	v.SetDefault("worker.misconfigured_grace", "0")
+	v.SetDefault("worker.trash_retention", "720h")
+	v.SetDefault("worker.trash_purge_interval", "1h")
+
+	v.SetDefault("resolver.cache_ttl", "10m")
+	v.SetDefault("resolver.negative_cache_ttl", "30s")
+
+	v.SetDefault("webhook.poll_interval", "5s")
+	v.SetDefault("webhook.timeout", "10s")
+	v.SetDefault("webhook.max_attempts", 8)
+	v.SetDefault("webhook.backoff_base", "30s")
+	v.SetDefault("webhook.backoff_max", "6h")
+	v.SetDefault("webhook.batch_size", 50)
 
 	// Environment variable bindings
 	v.SetEnvPrefix("GATEWAY")
@@ -101,8 +177,14 @@ func Load(logger *zap.Logger) (*Config, error) {
 	_ = v.BindEnv("dns.api_token", "DNS_API_TOKEN")
 	_ = v.BindEnv("dns.zone_id", "DNS_ZONE_ID")
 	_ = v.BindEnv("jwt.secret", "JWT_SECRET")
+	_ = v.BindEnv("jwt.jwks_url", "JWT_JWKS_URL")
+	_ = v.BindEnv("jwt.issuer", "JWT_ISSUER")
+	_ = v.BindEnv("jwt.audience", "JWT_AUDIENCE")
+	_ = v.BindEnv("jwt.public_key_files", "JWT_PUBLIC_KEY_FILES")
 	_ = v.BindEnv("caddy.acme_email", "ACME_EMAIL")
 	_ = v.BindEnv("caddy.base_domain", "BASE_DOMAIN")
+	_ = v.BindEnv("caddy.signing_secret", "GATEWAY_SIGNING_SECRET")
+	_ = v.BindEnv("server.internal_token", "GATEWAY_INTERNAL_TOKEN")
 
 	// Read config file if exists
 	v.SetConfigName("config")
@@ -127,5 +209,8 @@ func Load(logger *zap.Logger) (*Config, error) {
 
 // Validate validates the configuration
 func (c *Config) Validate() error {
+	if c.JWT.Secret == "" && c.JWT.JWKSURL == "" && len(c.JWT.PublicKeyFiles) == 0 {
+		return errors.New("jwt: one of secret, jwks_url or public_key_files is required")
+	}
 	return nil
 }
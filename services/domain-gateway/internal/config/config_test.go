@@ -0,0 +1,23 @@
+package config
+
+import "testing"
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		jwt   JWTConfig
+		valid bool
+	}{
+		{"nothing configured", JWTConfig{}, false},
+		{"secret", JWTConfig{Secret: "s"}, true},
+		{"jwks", JWTConfig{JWKSURL: "https://auth.example.com/jwks.json"}, true},
+		{"key files", JWTConfig{PublicKeyFiles: []string{"/keys/a.pem"}}, true},
+	}
+
+	for _, tt := range tests {
+		cfg := &Config{JWT: tt.jwt}
+		if err := cfg.Validate(); (err == nil) != tt.valid {
+			t.Errorf("%s: Validate = %v, want valid %v", tt.name, err, tt.valid)
+		}
+	}
+}
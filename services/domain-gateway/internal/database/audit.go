@@ -0,0 +1,105 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/panaroid/domain-gateway/pkg/models"
+)
+
+// AccessAuditRepository records requests made by staff acting as a tenant
+type AccessAuditRepository struct {
+	db *DB
+}
+
+// NewAccessAuditRepository creates a new access audit repository
+func NewAccessAuditRepository(db *DB) *AccessAuditRepository {
+	return &AccessAuditRepository{db: db}
+}
+
+// Record stores an access audit entry
+func (r *AccessAuditRepository) Record(ctx context.Context, entry *models.AccessAuditEntry) error {
+	if entry.ID == "" {
+		entry.ID = uuid.New().String()
+	}
+	entry.CreatedAt = time.Now().UTC()
+
+	query := `
+		INSERT INTO access_audit (id, user_id, role, home_tenant_id, tenant_id, method, path, status, remote_addr, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		entry.ID,
+		entry.UserID,
+		entry.Role,
+		nullString(entry.HomeTenantID),
+		entry.TenantID,
+		entry.Method,
+		entry.Path,
+		entry.Status,
+		nullString(entry.RemoteAddr),
+		entry.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record access audit entry: %w", err)
+	}
+
+	return nil
+}
+
+// SetStatus records the response status of an audited request once it completes
+func (r *AccessAuditRepository) SetStatus(ctx context.Context, id string, status int) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE access_audit SET status = $2 WHERE id = $1`, id, status)
+	if err != nil {
+		return fmt.Errorf("failed to update access audit entry: %w", err)
+	}
+	return nil
+}
+
+// List retrieves audit entries, newest first, optionally filtered by tenant and user
+func (r *AccessAuditRepository) List(ctx context.Context, tenantID, userID string, limit, offset int) ([]models.AccessAuditEntry, error) {
+	query := `
+		SELECT id, user_id, role, home_tenant_id, tenant_id, method, path, status, remote_addr, created_at
+		FROM access_audit
+		WHERE ($1 = '' OR CAST(tenant_id AS TEXT) = $1)
+		  AND ($2 = '' OR user_id = $2)
+		ORDER BY created_at DESC
+		LIMIT $3 OFFSET $4
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, tenantID, userID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list access audit entries: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []models.AccessAuditEntry
+	for rows.Next() {
+		var entry models.AccessAuditEntry
+		var homeTenantID, remoteAddr sql.NullString
+		if err := rows.Scan(
+			&entry.ID,
+			&entry.UserID,
+			&entry.Role,
+			&homeTenantID,
+			&entry.TenantID,
+			&entry.Method,
+			&entry.Path,
+			&entry.Status,
+			&remoteAddr,
+			&entry.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan access audit entry: %w", err)
+		}
+		entry.HomeTenantID = homeTenantID.String
+		entry.RemoteAddr = remoteAddr.String
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
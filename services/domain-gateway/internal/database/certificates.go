@@ -0,0 +1,71 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/panaroid/domain-gateway/pkg/models"
+)
+
+// CertificateRepository tracks observed certificate expiry per domain
+type CertificateRepository struct {
+	db *DB
+}
+
+// NewCertificateRepository creates a new certificate repository
+func NewCertificateRepository(db *DB) *CertificateRepository {
+	return &CertificateRepository{db: db}
+}
+
+// RecordCheck stores the expiry observed for a domain's certificate. If the
+// certificate expires within warning and this expiry has not been reported yet,
+// a certificate.expiring event is recorded in the same transaction.
+// It reports whether the event was recorded.
+func (r *CertificateRepository) RecordCheck(ctx context.Context, domain *models.Domain, expiresAt time.Time, warning time.Duration) (bool, error) {
+	now := time.Now().UTC()
+
+	var notify bool
+	err := r.db.WithTx(ctx, func(tx *Tx) error {
+		var notified sql.NullTime
+		err := tx.QueryRowContext(ctx, `
+			SELECT notified_expires_at FROM certificate_checks WHERE domain_id = $1 FOR UPDATE
+		`, domain.ID).Scan(&notified)
+		if err != nil && err != sql.ErrNoRows {
+			return fmt.Errorf("failed to load certificate check: %w", err)
+		}
+
+		expiring := expiresAt.Sub(now) <= warning
+		// A renewed certificate has a new expiry and is reported afresh
+		notify = expiring && !(notified.Valid && notified.Time.Equal(expiresAt))
+		if notify {
+			notified = sql.NullTime{Time: expiresAt, Valid: true}
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO certificate_checks (domain_id, expires_at, checked_at, notified_expires_at)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (domain_id) DO UPDATE SET
+				expires_at = EXCLUDED.expires_at,
+				checked_at = EXCLUDED.checked_at,
+				notified_expires_at = EXCLUDED.notified_expires_at
+		`, domain.ID, expiresAt, now, notified)
+		if err != nil {
+			return fmt.Errorf("failed to save certificate check: %w", err)
+		}
+
+		if !notify {
+			return nil
+		}
+		return recordEventData(ctx, tx, models.EventCertificateExpiring, domain, domain, models.CertificateExpiry{
+			Domain:    *domain,
+			ExpiresAt: expiresAt,
+		})
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return notify, nil
+}
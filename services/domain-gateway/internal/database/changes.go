@@ -0,0 +1,141 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/panaroid/domain-gateway/pkg/models"
+)
+
+// ChangeChannel is the Postgres NOTIFY channel announcing new change_outbox rows
+const ChangeChannel = "gateway_changes"
+
+// Change kinds
+const (
+	ChangeDomain = "domain"
+	ChangeTenant = "tenant"
+	ChangeGlobal = "global"
+)
+
+// Change is a committed write that other instances must apply to their in-memory state
+type Change struct {
+	Seq        int64
+	Kind       string
+	TenantID   string
+	DomainID   string
+	Event      *models.DomainEvent
+	InstanceID string
+	CreatedAt  time.Time
+}
+
+// recordChange writes a change row and notifies listeners within the caller's
+// transaction; Postgres delivers the notification only if the transaction commits.
+// The payload is the writer's instance ID so it can ignore its own notifications.
+// SQLite serves a single instance, so there is no one to notify.
+func (db *DB) recordChange(ctx context.Context, tx *Tx, kind, tenantID, domainID string, event *models.DomainEvent) error {
+	if db.dialect == DialectSQLite {
+		return nil
+	}
+
+	var encoded sql.NullString
+	if event != nil {
+		data, err := json.Marshal(event)
+		if err != nil {
+			return fmt.Errorf("failed to encode change event: %w", err)
+		}
+		encoded = sql.NullString{String: string(data), Valid: true}
+	}
+
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO change_outbox (kind, tenant_id, domain_id, event, instance_id)
+		VALUES ($1, $2, $3, $4, $5)
+	`, kind, nullString(tenantID), nullString(domainID), encoded, db.instanceID)
+	if err != nil {
+		return fmt.Errorf("failed to write change: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `SELECT pg_notify($1, $2)`, ChangeChannel, db.instanceID); err != nil {
+		return fmt.Errorf("failed to notify change: %w", err)
+	}
+
+	return nil
+}
+
+// ChangeRepository reads the change outbox
+type ChangeRepository struct {
+	db *DB
+}
+
+// NewChangeRepository creates a new change repository
+func NewChangeRepository(db *DB) *ChangeRepository {
+	return &ChangeRepository{db: db}
+}
+
+// Since retrieves changes created at or after since with a sequence number
+// above afterSeq, oldest first. Paging by afterSeq moves past a page even
+// when every row in it shares the same time window.
+func (r *ChangeRepository) Since(ctx context.Context, since time.Time, afterSeq int64, limit int) ([]Change, error) {
+	query := `
+		SELECT seq, kind, tenant_id, domain_id, event, instance_id, created_at
+		FROM change_outbox
+		WHERE created_at >= $1 AND seq > $2
+		ORDER BY seq ASC
+		LIMIT $3
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, since, afterSeq, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list changes: %w", err)
+	}
+	defer rows.Close()
+
+	var changes []Change
+	for rows.Next() {
+		var change Change
+		var tenantID, domainID, event sql.NullString
+		if err := rows.Scan(
+			&change.Seq,
+			&change.Kind,
+			&tenantID,
+			&domainID,
+			&event,
+			&change.InstanceID,
+			&change.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan change: %w", err)
+		}
+		change.TenantID = tenantID.String
+		change.DomainID = domainID.String
+		if event.Valid {
+			change.Event = &models.DomainEvent{}
+			if err := json.Unmarshal([]byte(event.String), change.Event); err != nil {
+				return nil, fmt.Errorf("failed to decode change event: %w", err)
+			}
+		}
+		changes = append(changes, change)
+	}
+
+	return changes, rows.Err()
+}
+
+// Latest returns the database time of the newest change, or the current time if there are none
+func (r *ChangeRepository) Latest(ctx context.Context) (time.Time, error) {
+	var latest time.Time
+	err := r.db.QueryRowContext(ctx, `SELECT COALESCE(MAX(created_at), clock_timestamp()) FROM change_outbox`).Scan(&latest)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to read latest change: %w", err)
+	}
+	return latest, nil
+}
+
+// Prune deletes changes older than before
+func (r *ChangeRepository) Prune(ctx context.Context, before time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM change_outbox WHERE created_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune changes: %w", err)
+	}
+	return result.RowsAffected()
+}
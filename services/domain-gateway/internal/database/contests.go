@@ -0,0 +1,372 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/panaroid/domain-gateway/pkg/models"
+)
+
+// ContestWindow is how long a domain contest stays open after it is started
+const ContestWindow = 7 * 24 * time.Hour
+
+// contestColumns is the column list matching scanContest
+const contestColumns = `id, domain, tenant_id, holder_tenant_id, domain_id, status, challenge_token,
+	created_at, expires_at, resolved_at`
+
+var (
+	// ErrContestNotFound is returned when a domain contest does not exist
+	ErrContestNotFound = errors.New("domain contest not found")
+
+	// ErrContestExists is returned when a tenant already has an open contest
+	// for a domain
+	ErrContestExists = errors.New("domain contest already open")
+
+	// ErrContestNotOpen is returned when a contest has already been resolved,
+	// cancelled or has expired
+	ErrContestNotOpen = errors.New("domain contest is not open")
+
+	// ErrDomainLocked is returned when a domain's claim cannot be contested,
+	// because it has passed verification or already belongs to the tenant
+	ErrDomainLocked = errors.New("domain claim cannot be contested")
+)
+
+// ContestRepository handles domain contest database operations
+type ContestRepository struct {
+	db *DB
+}
+
+// NewContestRepository creates a new contest repository
+func NewContestRepository(db *DB) *ContestRepository {
+	return &ContestRepository{db: db}
+}
+
+// Create opens a contest of another tenant's claim on a domain, which must
+// have the contest's challenge token set. The holding tenant is told of it.
+// It returns ErrDomainNotFound if nobody claims the domain, ErrDomainLocked if
+// the claim cannot be contested, and ErrContestExists if the tenant already
+// contests it.
+func (r *ContestRepository) Create(ctx context.Context, contest *models.DomainContest) error {
+	if contest.ID == "" {
+		contest.ID = uuid.New().String()
+	}
+	contest.Status = models.ContestPending
+	contest.CreatedAt = time.Now().UTC()
+	contest.ExpiresAt = contest.CreatedAt.Add(ContestWindow)
+
+	return r.db.WithTx(ctx, func(tx *Tx) error {
+		// Locking the claim serialises contests against its resolution
+		holder, err := lockDomainByName(ctx, tx, contest.Domain)
+		if err != nil {
+			return err
+		}
+		if holder == nil {
+			return ErrDomainNotFound
+		}
+		if !holder.Contestable() || holder.TenantID == contest.TenantID {
+			return fmt.Errorf("%w: %s", ErrDomainLocked, holder.Domain)
+		}
+
+		var open int
+		err = tx.QueryRowContext(ctx, `
+			SELECT COUNT(*) FROM domain_contests
+			WHERE domain = $1 AND tenant_id = $2 AND status = $3 AND expires_at > $4
+		`, contest.Domain, contest.TenantID, models.ContestPending, contest.CreatedAt).Scan(&open)
+		if err != nil {
+			return fmt.Errorf("failed to check open contests: %w", err)
+		}
+		if open > 0 {
+			return ErrContestExists
+		}
+
+		contest.HolderTenantID = holder.TenantID
+
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO domain_contests (id, domain, tenant_id, holder_tenant_id, status, challenge_token, created_at, expires_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		`,
+			contest.ID,
+			contest.Domain,
+			contest.TenantID,
+			contest.HolderTenantID,
+			contest.Status,
+			contest.ChallengeToken,
+			contest.CreatedAt,
+			contest.ExpiresAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to create domain contest: %w", err)
+		}
+
+		return recordEventData(ctx, tx, models.EventDomainContested, holder, holder, models.DomainContested{
+			Domain:      *holder,
+			ContestID:   contest.ID,
+			ContestedBy: contest.TenantID,
+			ExpiresAt:   contest.ExpiresAt,
+		})
+	})
+}
+
+// Get retrieves a contest by ID
+func (r *ContestRepository) Get(ctx context.Context, id string) (*models.DomainContest, error) {
+	query := `
+		SELECT ` + contestColumns + `
+		FROM domain_contests
+		WHERE id = $1
+	`
+
+	contest, err := scanContest(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get domain contest: %w", err)
+	}
+
+	return contest, nil
+}
+
+// ListByTenant retrieves the contests a tenant has started or whose claims it
+// holds, newest first
+func (r *ContestRepository) ListByTenant(ctx context.Context, tenantID string) ([]models.DomainContest, error) {
+	query := `
+		SELECT ` + contestColumns + `
+		FROM domain_contests
+		WHERE tenant_id = $1 OR holder_tenant_id = $1
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list domain contests: %w", err)
+	}
+	defer rows.Close()
+
+	var contests []models.DomainContest
+	for rows.Next() {
+		contest, err := scanContest(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan domain contest: %w", err)
+		}
+		contests = append(contests, *contest)
+	}
+
+	return contests, rows.Err()
+}
+
+// Resolve settles an open contest once the challenging tenant has proven
+// control of the domain's DNS. If the claim is still contestable it is removed
+// and the challenger gets the domain, pending DNS under the challenge token;
+// both tenants' histories record the displacement. If the claim has since
+// passed verification the contest is lost instead. Any other open contests
+// for the domain are lost either way. It returns the resolved contest and, if
+// it was won, the challenger's new domain.
+func (r *ContestRepository) Resolve(ctx context.Context, id string) (*models.DomainContest, *models.Domain, error) {
+	var resolved *models.DomainContest
+	var domain *models.Domain
+	err := r.db.WithTx(ctx, func(tx *Tx) error {
+		contest, err := lockContest(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		if !contest.IsOpen() {
+			return fmt.Errorf("%w: contest is %s", ErrContestNotOpen, contest.Status)
+		}
+
+		now := time.Now().UTC()
+		holder, err := lockDomainByName(ctx, tx, contest.Domain)
+		if err != nil {
+			return err
+		}
+		if holder != nil && (!holder.Contestable() || holder.TenantID == contest.TenantID) {
+			_, err = tx.ExecContext(ctx, `
+				UPDATE domain_contests SET status = $2, resolved_at = $3 WHERE id = $1
+			`, id, models.ContestLost, now)
+			if err != nil {
+				return fmt.Errorf("failed to resolve domain contest: %w", err)
+			}
+			resolved, err = lockContest(ctx, tx, id)
+			return err
+		}
+
+		domain = &models.Domain{
+			ID:                uuid.New().String(),
+			TenantID:          contest.TenantID,
+			Domain:            contest.Domain,
+			Type:              models.DomainTypeCustom,
+			VerificationToken: contest.ChallengeToken,
+			CreatedAt:         now,
+			UpdatedAt:         now,
+		}
+		domain.SetStatus(models.StatusPendingDNS, "")
+
+		// The claim may have been purged since the contest began
+		if holder == nil {
+			if err := insertDomain(ctx, tx, domain); err != nil {
+				return err
+			}
+			if err := recordEvent(ctx, tx, models.EventDomainCreated, nil, domain); err != nil {
+				return err
+			}
+		} else {
+			// Removing the claim drops its transfers with it, so close them
+			// where both tenants can see it first
+			if err := cancelOpenTransfers(ctx, tx, holder, models.TransferCancelledDisplaced); err != nil {
+				return err
+			}
+			if _, err := tx.ExecContext(ctx, `DELETE FROM domains WHERE id = $1`, holder.ID); err != nil {
+				return fmt.Errorf("failed to remove displaced domain: %w", err)
+			}
+			if err := insertDomain(ctx, tx, domain); err != nil {
+				return err
+			}
+
+			displaced := models.DomainDisplaced{
+				Domain:       *holder,
+				ContestID:    contest.ID,
+				FromTenantID: holder.TenantID,
+				ToTenantID:   contest.TenantID,
+			}
+			if err := recordEventData(ctx, tx, models.EventDomainDisplaced, holder, nil, displaced); err != nil {
+				return err
+			}
+			displaced.Domain = *domain
+			if err := recordEventData(ctx, tx, models.EventDomainDisplaced, nil, domain, displaced); err != nil {
+				return err
+			}
+
+			_, err = tx.ExecContext(ctx, `UPDATE domain_contests SET holder_tenant_id = $2 WHERE id = $1`, id, holder.TenantID)
+			if err != nil {
+				return fmt.Errorf("failed to resolve domain contest: %w", err)
+			}
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			UPDATE domain_contests SET status = $2, domain_id = $3, resolved_at = $4 WHERE id = $1
+		`, id, models.ContestWon, domain.ID, now)
+		if err != nil {
+			return fmt.Errorf("failed to resolve domain contest: %w", err)
+		}
+		_, err = tx.ExecContext(ctx, `
+			UPDATE domain_contests SET status = $3, resolved_at = $4
+			WHERE domain = $1 AND id <> $2 AND status = $5
+		`, contest.Domain, id, models.ContestLost, now, models.ContestPending)
+		if err != nil {
+			return fmt.Errorf("failed to close competing contests: %w", err)
+		}
+
+		resolved, err = lockContest(ctx, tx, id)
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return resolved, domain, nil
+}
+
+// Cancel withdraws an open contest
+func (r *ContestRepository) Cancel(ctx context.Context, id string) (*models.DomainContest, error) {
+	var contest *models.DomainContest
+	err := r.db.WithTx(ctx, func(tx *Tx) error {
+		current, err := lockContest(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		if !current.IsOpen() {
+			return fmt.Errorf("%w: contest is %s", ErrContestNotOpen, current.Status)
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			UPDATE domain_contests SET status = $2, resolved_at = $3 WHERE id = $1
+		`, id, models.ContestCancelled, time.Now().UTC())
+		if err != nil {
+			return fmt.Errorf("failed to cancel domain contest: %w", err)
+		}
+
+		contest, err = lockContest(ctx, tx, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return contest, nil
+}
+
+// lockContest loads a contest within a transaction, locking its row
+func lockContest(ctx context.Context, tx *Tx, id string) (*models.DomainContest, error) {
+	query := `
+		SELECT ` + contestColumns + `
+		FROM domain_contests
+		WHERE id = $1
+		FOR UPDATE
+	`
+
+	contest, err := scanContest(tx.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrContestNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get domain contest: %w", err)
+	}
+	return contest, nil
+}
+
+// lockDomainByName loads a domain by name within a transaction, locking its
+// row. It returns nil if nobody claims the name.
+func lockDomainByName(ctx context.Context, tx *Tx, name string) (*models.Domain, error) {
+	query := `
+		SELECT ` + domainColumns + `
+		FROM domains
+		WHERE domain = $1
+		FOR UPDATE
+	`
+
+	domain, err := scanDomain(tx.QueryRowContext(ctx, query, name))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get domain: %w", err)
+	}
+	return domain, nil
+}
+
+// scanContest scans a single contest row selected with contestColumns.
+// A pending contest past its expiry is reported as expired.
+func scanContest(row rowScanner) (*models.DomainContest, error) {
+	contest := &models.DomainContest{}
+	var domainID sql.NullString
+	var resolvedAt sql.NullTime
+
+	if err := row.Scan(
+		&contest.ID,
+		&contest.Domain,
+		&contest.TenantID,
+		&contest.HolderTenantID,
+		&domainID,
+		&contest.Status,
+		&contest.ChallengeToken,
+		&contest.CreatedAt,
+		&contest.ExpiresAt,
+		&resolvedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	contest.DomainID = domainID.String
+	if resolvedAt.Valid {
+		contest.ResolvedAt = &resolvedAt.Time
+	}
+	if contest.IsOpen() && time.Now().After(contest.ExpiresAt) {
+		contest.Status = models.ContestExpired
+	}
+
+	return contest, nil
+}
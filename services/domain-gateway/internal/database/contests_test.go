@@ -0,0 +1,54 @@
+package database_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/panaroid/domain-gateway/internal/database"
+	"github.com/panaroid/domain-gateway/pkg/models"
+)
+
+func TestContestResolveCancelsTransfers(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t, "sqlite://"+filepath.Join(t.TempDir(), "gateway.db"))
+	domains := database.NewDomainRepository(db)
+	transfers := database.NewTransferRepository(db)
+	contests := database.NewContestRepository(db)
+	events := database.NewEventRepository(db)
+
+	holder, receiver, challenger := uuid.New().String(), uuid.New().String(), uuid.New().String()
+
+	domain := &models.Domain{TenantID: holder, Domain: "contested.example.com", Type: models.DomainTypeCustom}
+	if err := domains.Create(ctx, domain); err != nil {
+		t.Fatalf("Create domain: %v", err)
+	}
+	transfer := &models.DomainTransfer{DomainID: domain.ID, ToTenantID: receiver}
+	if err := transfers.Create(ctx, transfer); err != nil {
+		t.Fatalf("Create transfer: %v", err)
+	}
+	contest := &models.DomainContest{Domain: domain.Domain, TenantID: challenger, ChallengeToken: "challenge"}
+	if err := contests.Create(ctx, contest); err != nil {
+		t.Fatalf("Create contest: %v", err)
+	}
+
+	if _, won, err := contests.Resolve(ctx, contest.ID); err != nil || won == nil {
+		t.Fatalf("Resolve = %v, %v; want the challenger to win", won, err)
+	}
+
+	for _, tenantID := range []string{holder, receiver} {
+		history, _, err := events.ListByTenant(ctx, tenantID, 50, 0)
+		if err != nil {
+			t.Fatalf("ListByTenant: %v", err)
+		}
+		found := false
+		for _, event := range history {
+			found = found || event.Action == models.EventDomainTransferCancelled
+		}
+		if !found {
+			t.Errorf("tenant %s has no %s event", tenantID, models.EventDomainTransferCancelled)
+		}
+	}
+}
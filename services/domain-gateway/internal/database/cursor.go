@@ -0,0 +1,50 @@
+package database
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// ErrInvalidCursor is returned when a listing cursor cannot be decoded
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// domainCursor is the position after the last domain of a page. Listings are
+// ordered by (created_at, id) descending, so the pair identifies it exactly.
+type domainCursor struct {
+	CreatedAt time.Time `json:"c"`
+	ID        string    `json:"i"`
+}
+
+// encodeCursor returns the opaque cursor for the page after domain
+func encodeCursor(createdAt time.Time, id string) string {
+	data, _ := json.Marshal(domainCursor{CreatedAt: createdAt.UTC(), ID: id})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor parses a cursor from encodeCursor, nil for the first page
+func decodeCursor(cursor string) (*domainCursor, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c domainCursor
+	if err := json.Unmarshal(data, &c); err != nil || c.ID == "" || c.CreatedAt.IsZero() {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
+
+// follows reports whether a domain created at createdAt with id comes after
+// the cursor in listing order, i.e. belongs to a later page
+func (c *domainCursor) follows(createdAt time.Time, id string) bool {
+	if !createdAt.Equal(c.CreatedAt) {
+		return createdAt.Before(c.CreatedAt)
+	}
+	return id < c.ID
+}
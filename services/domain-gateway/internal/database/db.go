@@ -1,25 +1,39 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"sync"
 	"time"
 
+	"github.com/google/uuid"
 	_ "github.com/lib/pq"
 	"go.uber.org/zap"
 
 	"github.com/panaroid/domain-gateway/internal/config"
+	"github.com/panaroid/domain-gateway/pkg/models"
 )
 
 // DB wraps the database connection
 type DB struct {
 	*sql.DB
-	logger *zap.Logger
+	dialect     Dialect
+	logger      *zap.Logger
+	instanceID  string
+	listenersMu sync.RWMutex
+	listeners   []func(models.DomainEvent)
 }
 
-// New creates a new database connection
+// New creates a new database connection. The URL scheme selects the
+// dialect: sqlite:// for SQLite, Postgres otherwise.
 func New(cfg config.DatabaseConfig, logger *zap.Logger) (*DB, error) {
-	db, err := sql.Open("postgres", cfg.URL)
+	dialect, driverName, dsn, err := parseDatabaseURL(cfg.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := sql.Open(driverName, dsn)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
@@ -34,46 +48,39 @@ func New(cfg config.DatabaseConfig, logger *zap.Logger) (*DB, error) {
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	logger.Info("Database connection established")
+	logger.Info("Database connection established", zap.String("dialect", string(dialect)))
 
-	return &DB{DB: db, logger: logger}, nil
+	return &DB{DB: db, dialect: dialect, logger: logger, instanceID: uuid.New().String()}, nil
 }
 
-// Close closes the database connection
-func (db *DB) Close() error {
-	return db.DB.Close()
+// Dialect returns the SQL dialect of the database
+func (db *DB) Dialect() Dialect {
+	return db.dialect
 }
 
-// RunMigrations runs database migrations
-func (db *DB) RunMigrations() error {
-	migrations := []string{
-		`CREATE TABLE IF NOT EXISTS domains (
-			id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
-			tenant_id UUID NOT NULL,
-			domain VARCHAR(255) NOT NULL UNIQUE,
-			type VARCHAR(50) NOT NULL CHECK (type IN ('subdomain', 'custom')),
-			verified BOOLEAN DEFAULT FALSE,
-			verification_token VARCHAR(255),
-			is_primary BOOLEAN DEFAULT FALSE,
-			ssl_issued BOOLEAN DEFAULT FALSE,
-			created_at TIMESTAMPTZ DEFAULT NOW(),
-			updated_at TIMESTAMPTZ DEFAULT NOW(),
-			verified_at TIMESTAMPTZ,
-			CONSTRAINT unique_tenant_domain UNIQUE (tenant_id, domain)
-		)`,
-		`CREATE INDEX IF NOT EXISTS idx_domains_tenant ON domains(tenant_id)`,
-		`CREATE INDEX IF NOT EXISTS idx_domains_verified ON domains(verified)`,
-		`CREATE INDEX IF NOT EXISTS idx_domains_type ON domains(type)`,
-	}
+// ExecContext executes a query written for Postgres in the database's dialect
+func (db *DB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return db.DB.ExecContext(ctx, db.dialect.rebind(query), db.dialect.bindArgs(args)...)
+}
 
-	for _, migration := range migrations {
-		if _, err := db.Exec(migration); err != nil {
-			return fmt.Errorf("migration failed: %w", err)
-		}
-	}
+// QueryContext runs a query written for Postgres in the database's dialect
+func (db *DB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return db.DB.QueryContext(ctx, db.dialect.rebind(query), db.dialect.bindArgs(args)...)
+}
+
+// QueryRowContext runs a single-row query written for Postgres in the database's dialect
+func (db *DB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return db.DB.QueryRowContext(ctx, db.dialect.rebind(query), db.dialect.bindArgs(args)...)
+}
+
+// InstanceID identifies this process in change notifications
+func (db *DB) InstanceID() string {
+	return db.instanceID
+}
 
-	db.logger.Info("Database migrations completed")
-	return nil
+// Close closes the database connection
+func (db *DB) Close() error {
+	return db.DB.Close()
 }
 
 // UpdateTimestamp updates the updated_at timestamp
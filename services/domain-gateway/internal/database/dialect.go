@@ -0,0 +1,115 @@
+package database
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// Dialect is the SQL database a DB talks to
+type Dialect string
+
+const (
+	DialectPostgres Dialect = "postgres"
+	DialectSQLite   Dialect = "sqlite"
+)
+
+// sqliteTimeFormat is how the SQLite driver stores times. Times are stored in
+// UTC so they compare correctly as text.
+const sqliteTimeFormat = "2006-01-02 15:04:05.999999999-07:00"
+
+// sqliteRowLocks matches the row locking clauses SQLite does not support.
+// SQLite locks the whole database for a write transaction instead.
+var sqliteRowLocks = regexp.MustCompile(`(?i)\s+FOR\s+UPDATE(\s+SKIP\s+LOCKED)?`)
+
+func init() {
+	// Queries are written for Postgres, which provides NOW()
+	err := sqlite.RegisterScalarFunction("now", 0, func(*sqlite.FunctionContext, []driver.Value) (driver.Value, error) {
+		return time.Now().UTC().Format(sqliteTimeFormat), nil
+	})
+	if err != nil {
+		panic(fmt.Sprintf("failed to register SQLite NOW(): %v", err))
+	}
+}
+
+// parseDatabaseURL picks the dialect from the URL scheme and returns the
+// driver name and data source for it. sqlite://path/to/file.db and
+// sqlite:///abs/path.db select SQLite; anything else is passed to Postgres.
+func parseDatabaseURL(url string) (Dialect, string, string, error) {
+	path, ok := strings.CutPrefix(url, "sqlite://")
+	if !ok {
+		path, ok = strings.CutPrefix(url, "sqlite:")
+	}
+	if !ok {
+		return DialectPostgres, "postgres", url, nil
+	}
+
+	path, params, _ := strings.Cut(path, "?")
+	if path == "" {
+		return "", "", "", fmt.Errorf("sqlite database URL %q has no file path", url)
+	}
+
+	// Write transactions take the database lock up front so two writers
+	// wait for each other instead of failing to upgrade a read lock
+	dsn := "file:" + path + "?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)" +
+		"&_txlock=immediate&_time_format=sqlite"
+	if params != "" {
+		dsn += "&" + params
+	}
+	return DialectSQLite, "sqlite", dsn, nil
+}
+
+// rebind rewrites a query written for Postgres for the dialect
+func (d Dialect) rebind(query string) string {
+	if d != DialectSQLite {
+		return query
+	}
+	return sqliteRowLocks.ReplaceAllString(query, "")
+}
+
+// bindArgs converts query arguments for the dialect
+func (d Dialect) bindArgs(args []interface{}) []interface{} {
+	if d != DialectSQLite {
+		return args
+	}
+
+	converted := make([]interface{}, len(args))
+	for i, arg := range args {
+		switch v := arg.(type) {
+		case time.Time:
+			converted[i] = v.UTC()
+		case *time.Time:
+			if v != nil {
+				converted[i] = v.UTC()
+			}
+		case sql.NullTime:
+			if v.Valid {
+				converted[i] = v.Time.UTC()
+			}
+		default:
+			converted[i] = arg
+		}
+	}
+	return converted
+}
+
+// isUniqueViolation reports whether err is a unique constraint violation
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code == "23505"
+	}
+	var sqliteErr *sqlite.Error
+	if errors.As(err, &sqliteErr) {
+		return sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE || sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY
+	}
+	return false
+}
@@ -3,7 +3,10 @@ package database
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -11,6 +14,35 @@ import (
 	"github.com/panaroid/domain-gateway/pkg/models"
 )
 
+// ErrDomainNotFound is returned when a domain to update does not exist
+var ErrDomainNotFound = errors.New("domain not found")
+
+// ErrInvalidTransition is returned when a change is not allowed from a domain's current status
+var ErrInvalidTransition = errors.New("invalid domain status transition")
+
+// errNoChange lets a mutate update report that nothing worth an event changed
+var errNoChange = errors.New("no change")
+
+// domainColumns is the column list matching scanDomain
+const domainColumns = `id, tenant_id, domain, type, status, resume_status, verification_token, is_primary, created_at, updated_at, verified_at,
+	verification_attempts, last_checked_at, next_check_at, last_error,
+	pending_since, recheck_failures, misconfigured_at, redirect_url, deleted_at`
+
+// verifiedStatuses is models.VerifiedStatuses as an SQL list
+var verifiedStatuses = statusList(models.VerifiedStatuses...)
+
+// verifiedCondition selects verified domains, including suspended ones
+var verifiedCondition = `(status IN ` + verifiedStatuses + ` OR (status = 'suspended' AND resume_status IN ` + verifiedStatuses + `))`
+
+// pendingCondition selects custom domains whose next verification check is due.
+// A domain left verifying by a lost claim is picked up once its claim expires.
+var pendingCondition = `type = 'custom' AND status IN ` + statusList(models.StatusPendingDNS, models.StatusVerifying) + `
+	AND (next_check_at IS NULL OR next_check_at <= NOW())`
+
+// recheckCondition selects verified custom domains due for re-verification
+var recheckCondition = `type = 'custom' AND status IN ` + verifiedStatuses + `
+	AND (next_check_at IS NULL OR next_check_at <= NOW())`
+
 // DomainRepository handles domain database operations
 type DomainRepository struct {
 	db *DB
@@ -21,66 +53,35 @@ func NewDomainRepository(db *DB) *DomainRepository {
 	return &DomainRepository{db: db}
 }
 
-// Create creates a new domain
+// Create creates a new domain in its initial status, pending DNS if unset.
+// It returns ErrDomainExists if the name is taken.
 func (r *DomainRepository) Create(ctx context.Context, domain *models.Domain) error {
 	if domain.ID == "" {
 		domain.ID = uuid.New().String()
 	}
+	if domain.Status == "" {
+		domain.SetStatus(models.StatusPendingDNS, "")
+	}
 	domain.CreatedAt = time.Now().UTC()
 	domain.UpdatedAt = time.Now().UTC()
 
-	query := `
-		INSERT INTO domains (id, tenant_id, domain, type, verified, verification_token, is_primary, ssl_issued, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
-		RETURNING id
-	`
-
-	err := r.db.QueryRowContext(ctx, query,
-		domain.ID,
-		domain.TenantID,
-		domain.Domain,
-		domain.Type,
-		domain.Verified,
-		domain.VerificationToken,
-		domain.IsPrimary,
-		domain.SSLIssued,
-		domain.CreatedAt,
-		domain.UpdatedAt,
-	).Scan(&domain.ID)
-
-	if err != nil {
-		return fmt.Errorf("failed to create domain: %w", err)
-	}
-
-	return nil
+	return r.withTx(ctx, func(tx *Tx) error {
+		if err := insertDomain(ctx, tx, domain); err != nil {
+			return err
+		}
+		return recordEvent(ctx, tx, models.EventDomainCreated, nil, domain)
+	})
 }
 
 // GetByID retrieves a domain by ID
 func (r *DomainRepository) GetByID(ctx context.Context, id string) (*models.Domain, error) {
 	query := `
-		SELECT id, tenant_id, domain, type, verified, verification_token, is_primary, ssl_issued, created_at, updated_at, verified_at
+		SELECT ` + domainColumns + `
 		FROM domains
 		WHERE id = $1
 	`
 
-	domain := &models.Domain{}
-	var verifiedAt sql.NullTime
-	var verificationToken sql.NullString
-
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&domain.ID,
-		&domain.TenantID,
-		&domain.Domain,
-		&domain.Type,
-		&domain.Verified,
-		&verificationToken,
-		&domain.IsPrimary,
-		&domain.SSLIssued,
-		&domain.CreatedAt,
-		&domain.UpdatedAt,
-		&verifiedAt,
-	)
-
+	domain, err := scanDomain(r.db.QueryRowContext(ctx, query, id))
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -88,42 +89,18 @@ func (r *DomainRepository) GetByID(ctx context.Context, id string) (*models.Doma
 		return nil, fmt.Errorf("failed to get domain: %w", err)
 	}
 
-	if verifiedAt.Valid {
-		domain.VerifiedAt = &verifiedAt.Time
-	}
-	if verificationToken.Valid {
-		domain.VerificationToken = verificationToken.String
-	}
-
 	return domain, nil
 }
 
 // GetByDomain retrieves a domain by domain name
 func (r *DomainRepository) GetByDomain(ctx context.Context, domainName string) (*models.Domain, error) {
 	query := `
-		SELECT id, tenant_id, domain, type, verified, verification_token, is_primary, ssl_issued, created_at, updated_at, verified_at
+		SELECT ` + domainColumns + `
 		FROM domains
 		WHERE domain = $1
 	`
 
-	domain := &models.Domain{}
-	var verifiedAt sql.NullTime
-	var verificationToken sql.NullString
-
-	err := r.db.QueryRowContext(ctx, query, domainName).Scan(
-		&domain.ID,
-		&domain.TenantID,
-		&domain.Domain,
-		&domain.Type,
-		&domain.Verified,
-		&verificationToken,
-		&domain.IsPrimary,
-		&domain.SSLIssued,
-		&domain.CreatedAt,
-		&domain.UpdatedAt,
-		&verifiedAt,
-	)
-
+	domain, err := scanDomain(r.db.QueryRowContext(ctx, query, domainName))
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -131,11 +108,24 @@ func (r *DomainRepository) GetByDomain(ctx context.Context, domainName string) (
 		return nil, fmt.Errorf("failed to get domain: %w", err)
 	}
 
-	if verifiedAt.Valid {
-		domain.VerifiedAt = &verifiedAt.Time
+	return domain, nil
+}
+
+// GetPrimaryByTenant retrieves the primary domain of a tenant
+func (r *DomainRepository) GetPrimaryByTenant(ctx context.Context, tenantID string) (*models.Domain, error) {
+	query := `
+		SELECT ` + domainColumns + `
+		FROM domains
+		WHERE tenant_id = $1 AND is_primary = TRUE
+		LIMIT 1
+	`
+
+	domain, err := scanDomain(r.db.QueryRowContext(ctx, query, tenantID))
+	if err == sql.ErrNoRows {
+		return nil, nil
 	}
-	if verificationToken.Valid {
-		domain.VerificationToken = verificationToken.String
+	if err != nil {
+		return nil, fmt.Errorf("failed to get primary domain: %w", err)
 	}
 
 	return domain, nil
@@ -144,7 +134,7 @@ func (r *DomainRepository) GetByDomain(ctx context.Context, domainName string) (
 // ListByTenant retrieves all domains for a tenant
 func (r *DomainRepository) ListByTenant(ctx context.Context, tenantID string) ([]models.Domain, error) {
 	query := `
-		SELECT id, tenant_id, domain, type, verified, verification_token, is_primary, ssl_issued, created_at, updated_at, verified_at
+		SELECT ` + domainColumns + `
 		FROM domains
 		WHERE tenant_id = $1
 		ORDER BY created_at DESC
@@ -156,47 +146,67 @@ func (r *DomainRepository) ListByTenant(ctx context.Context, tenantID string) ([
 	}
 	defer rows.Close()
 
-	var domains []models.Domain
-	for rows.Next() {
-		var domain models.Domain
-		var verifiedAt sql.NullTime
-		var verificationToken sql.NullString
-
-		if err := rows.Scan(
-			&domain.ID,
-			&domain.TenantID,
-			&domain.Domain,
-			&domain.Type,
-			&domain.Verified,
-			&verificationToken,
-			&domain.IsPrimary,
-			&domain.SSLIssued,
-			&domain.CreatedAt,
-			&domain.UpdatedAt,
-			&verifiedAt,
-		); err != nil {
-			return nil, fmt.Errorf("failed to scan domain: %w", err)
-		}
+	return scanDomains(rows)
+}
 
-		if verifiedAt.Valid {
-			domain.VerifiedAt = &verifiedAt.Time
-		}
-		if verificationToken.Valid {
-			domain.VerificationToken = verificationToken.String
-		}
+// List retrieves a page of a tenant's domains matching filter, newest first.
+// Pages are keyed on (created_at, id) rather than offsets, so they stay
+// consistent while domains are added or removed.
+func (r *DomainRepository) List(ctx context.Context, filter models.DomainFilter) (*DomainPage, error) {
+	cursor, err := decodeCursor(filter.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	limit := pageSize(filter.Limit)
 
-		domains = append(domains, domain)
+	where, args := domainFilterCondition(filter)
+
+	page := &DomainPage{}
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM domains WHERE `+where, args...).Scan(&page.Total); err != nil {
+		return nil, fmt.Errorf("failed to count domains: %w", err)
 	}
 
-	return domains, nil
+	if cursor != nil {
+		args = append(args, cursor.CreatedAt, cursor.ID)
+		where += fmt.Sprintf(` AND (created_at, id) < ($%d, $%d)`, len(args)-1, len(args))
+	}
+	args = append(args, limit+1)
+
+	query := `
+		SELECT ` + domainColumns + `
+		FROM domains
+		WHERE ` + where + `
+		ORDER BY created_at DESC, id DESC
+		LIMIT $` + strconv.Itoa(len(args))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list domains: %w", err)
+	}
+	defer rows.Close()
+
+	domains, err := scanDomains(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	// The extra row only tells whether there is a next page
+	if len(domains) > limit {
+		domains = domains[:limit]
+		last := domains[limit-1]
+		page.NextCursor = encodeCursor(last.CreatedAt, last.ID)
+	}
+	page.Domains = domains
+
+	return page, nil
 }
 
-// GetPendingVerification retrieves all domains pending verification
+// GetPendingVerification retrieves all domains due for a verification check
 func (r *DomainRepository) GetPendingVerification(ctx context.Context) ([]models.Domain, error) {
 	query := `
-		SELECT id, tenant_id, domain, type, verified, verification_token, is_primary, ssl_issued, created_at, updated_at, verified_at
+		SELECT ` + domainColumns + `
 		FROM domains
-		WHERE verified = FALSE AND type = 'custom'
+		WHERE ` + pendingCondition + `
 		ORDER BY created_at ASC
 	`
 
@@ -206,198 +216,954 @@ func (r *DomainRepository) GetPendingVerification(ctx context.Context) ([]models
 	}
 	defer rows.Close()
 
-	var domains []models.Domain
-	for rows.Next() {
-		var domain models.Domain
-		var verifiedAt sql.NullTime
-		var verificationToken sql.NullString
-
-		if err := rows.Scan(
-			&domain.ID,
-			&domain.TenantID,
-			&domain.Domain,
-			&domain.Type,
-			&domain.Verified,
-			&verificationToken,
-			&domain.IsPrimary,
-			&domain.SSLIssued,
-			&domain.CreatedAt,
-			&domain.UpdatedAt,
-			&verifiedAt,
-		); err != nil {
-			return nil, fmt.Errorf("failed to scan domain: %w", err)
+	return scanDomains(rows)
+}
+
+// ClaimPendingVerification claims up to limit unclaimed domains due for a check for
+// this instance until lease expires, moving them to verifying. Rows locked by
+// another claimer are skipped, so concurrent workers never check the same domain.
+func (r *DomainRepository) ClaimPendingVerification(ctx context.Context, limit int, lease time.Duration) ([]models.Domain, error) {
+	return r.claim(ctx, pendingCondition, models.StatusVerifying, limit, lease)
+}
+
+// ClaimDueRecheck claims up to limit verified domains due for re-verification,
+// skipping rows claimed by other instances like ClaimPendingVerification
+func (r *DomainRepository) ClaimDueRecheck(ctx context.Context, limit int, lease time.Duration) ([]models.Domain, error) {
+	return r.claim(ctx, recheckCondition, "", limit, lease)
+}
+
+// GetAllVerified retrieves all verified domains, including suspended ones
+func (r *DomainRepository) GetAllVerified(ctx context.Context) ([]models.Domain, error) {
+	query := `
+		SELECT ` + domainColumns + `
+		FROM domains
+		WHERE ` + verifiedCondition + `
+		ORDER BY domain ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list verified domains: %w", err)
+	}
+	defer rows.Close()
+
+	return scanDomains(rows)
+}
+
+// MarkVerified marks a domain as verified. Marking an already verified domain is a no-op.
+func (r *DomainRepository) MarkVerified(ctx context.Context, id string) error {
+	_, err := r.mutate(ctx, id, models.EventDomainVerified, func(tx *Tx, before *models.Domain) error {
+		if before.Verified {
+			return errNoChange
+		}
+		if err := setStatus(ctx, tx, before, models.StatusCertPending); err != nil {
+			return err
+		}
+		now := time.Now().UTC()
+		_, err := tx.ExecContext(ctx, `
+			UPDATE domains
+			SET verified_at = $2, last_checked_at = $2, next_check_at = NULL, last_error = NULL
+			WHERE id = $1
+		`, id, now)
+		if err != nil {
+			return fmt.Errorf("failed to mark domain verified: %w", err)
 		}
+		return nil
+	})
+	return err
+}
 
-		if verifiedAt.Valid {
-			domain.VerifiedAt = &verifiedAt.Time
+// MarkUnverified clears a domain's verification and restarts its check schedule
+func (r *DomainRepository) MarkUnverified(ctx context.Context, id string) error {
+	_, err := r.mutate(ctx, id, models.EventDomainUnverified, func(tx *Tx, before *models.Domain) error {
+		if err := setStatus(ctx, tx, before, models.StatusPendingDNS); err != nil {
+			return err
+		}
+		_, err := tx.ExecContext(ctx, `
+			UPDATE domains
+			SET verified_at = NULL, is_primary = FALSE, updated_at = $2,
+				verification_attempts = 0, next_check_at = NULL, last_error = NULL,
+				pending_since = $2, recheck_failures = 0, misconfigured_at = NULL
+			WHERE id = $1
+		`, id, time.Now().UTC())
+		if err != nil {
+			return fmt.Errorf("failed to mark domain unverified: %w", err)
 		}
-		if verificationToken.Valid {
-			domain.VerificationToken = verificationToken.String
+		return nil
+	})
+	return err
+}
+
+// MarkSSLIssued activates a domain whose certificate is pending or failed.
+// It is a no-op for any other status.
+func (r *DomainRepository) MarkSSLIssued(ctx context.Context, id string) error {
+	_, err := r.mutate(ctx, id, models.EventCertificateIssued, func(tx *Tx, before *models.Domain) error {
+		if before.Status != models.StatusCertPending && before.Status != models.StatusCertFailed {
+			return errNoChange
 		}
+		return setStatus(ctx, tx, before, models.StatusActive)
+	})
+	return err
+}
 
-		domains = append(domains, domain)
+// MarkCertFailed records that an active or pending domain is not serving a
+// valid certificate. It is a no-op for any other status.
+func (r *DomainRepository) MarkCertFailed(ctx context.Context, id, reason string) error {
+	return r.withTx(ctx, func(tx *Tx) error {
+		before, err := lockDomain(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		if before.Status != models.StatusCertPending && before.Status != models.StatusActive {
+			return nil
+		}
+
+		if err := setStatus(ctx, tx, before, models.StatusCertFailed); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `UPDATE domains SET last_error = $2 WHERE id = $1`, id, reason); err != nil {
+			return fmt.Errorf("failed to mark certificate failed: %w", err)
+		}
+
+		after, err := lockDomain(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		return recordEventData(ctx, tx, models.EventCertificateFailed, before, after, models.CertificateFailure{
+			Domain: *after,
+			Reason: reason,
+		})
+	})
+}
+
+// Delete moves a domain to the trash. It is no longer routed or primary, but
+// its name stays reserved to its tenant until it is restored or purged.
+// Deleting a domain in the trash is a no-op.
+func (r *DomainRepository) Delete(ctx context.Context, id string) error {
+	_, err := r.mutate(ctx, id, models.EventDomainDeleted, func(tx *Tx, before *models.Domain) error {
+		if before.Deleted {
+			return errNoChange
+		}
+		if err := setStatus(ctx, tx, before, models.StatusDeleted); err != nil {
+			return err
+		}
+		_, err := tx.ExecContext(ctx, `
+			UPDATE domains SET deleted_at = $2, is_primary = FALSE, claimed_until = NULL WHERE id = $1
+		`, id, time.Now().UTC())
+		if err != nil {
+			return fmt.Errorf("failed to delete domain: %w", err)
+		}
+		return nil
+	})
+	return err
+}
+
+// Restore takes a domain out of the trash, returning it to the state it was
+// deleted in (suspended if its tenant now is). It returns the restored domain,
+// or ErrInvalidTransition if the domain is not in the trash.
+func (r *DomainRepository) Restore(ctx context.Context, id string) (*models.Domain, error) {
+	return r.mutate(ctx, id, models.EventDomainRestored, func(tx *Tx, before *models.Domain) error {
+		if !before.Deleted {
+			return fmt.Errorf("%w: %s is not deleted", ErrInvalidTransition, before.Status)
+		}
+		to := before.ResumeTarget()
+		suspended, err := tenantSuspended(ctx, tx, before.TenantID)
+		if err != nil {
+			return err
+		}
+		if suspended {
+			to = models.StatusSuspended
+		}
+		if err := setStatus(ctx, tx, before, to); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `UPDATE domains SET deleted_at = NULL WHERE id = $1`, id); err != nil {
+			return fmt.Errorf("failed to restore domain: %w", err)
+		}
+		return nil
+	})
+}
+
+// PurgeDeleted permanently deletes up to limit domains that were moved to the
+// trash before cutoff, oldest first, releasing their names. It returns the
+// purged domains.
+func (r *DomainRepository) PurgeDeleted(ctx context.Context, cutoff time.Time, limit int) ([]models.Domain, error) {
+	var purged []models.Domain
+	err := r.withTx(ctx, func(tx *Tx) error {
+		rows, err := tx.QueryContext(ctx, `
+			SELECT `+domainColumns+`
+			FROM domains
+			WHERE status = $1 AND deleted_at < $2
+			ORDER BY deleted_at ASC
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		`, models.StatusDeleted, cutoff, limit)
+		if err != nil {
+			return fmt.Errorf("failed to load deleted domains: %w", err)
+		}
+		domains, err := scanDomains(rows)
+		rows.Close()
+		if err != nil {
+			return err
+		}
+
+		for i := range domains {
+			before := &domains[i]
+			if _, err := tx.ExecContext(ctx, `DELETE FROM domains WHERE id = $1`, before.ID); err != nil {
+				return fmt.Errorf("failed to purge domain: %w", err)
+			}
+			if err := recordEvent(ctx, tx, models.EventDomainPurged, before, nil); err != nil {
+				return err
+			}
+		}
+		purged = domains
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return domains, nil
+	return purged, nil
 }
 
-// GetAllVerified retrieves all verified domains
-func (r *DomainRepository) GetAllVerified(ctx context.Context) ([]models.Domain, error) {
+// Update updates a domain. Changing Archived archives the domain, or returns
+// it to the state it was archived in (suspended if its tenant now is).
+func (r *DomainRepository) Update(ctx context.Context, domain *models.Domain) error {
+	domain.UpdatedAt = time.Now().UTC()
+
+	_, err := r.mutate(ctx, domain.ID, models.EventDomainUpdated, func(tx *Tx, before *models.Domain) error {
+		if domain.Archived != before.Archived {
+			to := models.StatusArchived
+			if !domain.Archived {
+				to = before.ResumeTarget()
+				suspended, err := tenantSuspended(ctx, tx, before.TenantID)
+				if err != nil {
+					return err
+				}
+				if suspended {
+					to = models.StatusSuspended
+				}
+			}
+			if err := setStatus(ctx, tx, before, to); err != nil {
+				return err
+			}
+		}
+
+		_, err := tx.ExecContext(ctx, `
+			UPDATE domains
+			SET redirect_url = $2, updated_at = $3
+			WHERE id = $1
+		`,
+			domain.ID,
+			domain.RedirectURL,
+			domain.UpdatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to update domain: %w", err)
+		}
+		return nil
+	})
+	return err
+}
+
+// RecordVerificationFailed records a failed manual verification for a domain.
+// The check schedule is left alone.
+func (r *DomainRepository) RecordVerificationFailed(ctx context.Context, id, reason string) error {
+	return r.withTx(ctx, func(tx *Tx) error {
+		domain, err := lockDomain(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now().UTC()
+		_, err = tx.ExecContext(ctx, `
+			UPDATE domains SET last_checked_at = $2, last_error = $3 WHERE id = $1
+		`, id, now, reason)
+		if err != nil {
+			return fmt.Errorf("failed to record verification failure: %w", err)
+		}
+		domain.LastCheckedAt = &now
+		domain.LastError = reason
+
+		return recordEventData(ctx, tx, models.EventDomainVerificationFailed, domain, domain, models.VerificationFailure{
+			Domain: *domain,
+			Reason: reason,
+		})
+	})
+}
+
+// ScheduleRetry records a failed scheduled check, returns the domain from
+// verifying to pending DNS and sets when it is next due. A domain that left
+// verification since it was claimed is left alone. Verifying only marks a
+// check in progress, so the return to pending DNS is not recorded as an event.
+func (r *DomainRepository) ScheduleRetry(ctx context.Context, id, reason string, next time.Time) error {
+	return r.withTx(ctx, func(tx *Tx) error {
+		before, err := lockDomain(ctx, tx, id)
+		if errors.Is(err, ErrDomainNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if before.Status != models.StatusPendingDNS && before.Status != models.StatusVerifying {
+			return nil
+		}
+
+		if err := setStatus(ctx, tx, before, models.StatusPendingDNS); err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, `
+			UPDATE domains
+			SET verification_attempts = verification_attempts + 1,
+				last_checked_at = $2, next_check_at = $3, last_error = $4, claimed_until = NULL
+			WHERE id = $1
+		`, id, time.Now().UTC(), next, reason)
+		if err != nil {
+			return fmt.Errorf("failed to schedule verification retry: %w", err)
+		}
+		return nil
+	})
+}
+
+// ExpireVerification gives up on verifying a domain. It is no longer
+// checked until it is verified manually or its verification is reset.
+func (r *DomainRepository) ExpireVerification(ctx context.Context, id, reason string) error {
+	_, err := r.mutate(ctx, id, models.EventDomainVerificationExpired, func(tx *Tx, before *models.Domain) error {
+		if !before.CanTransition(models.StatusVerificationExpired) {
+			return errNoChange
+		}
+		if err := setStatus(ctx, tx, before, models.StatusVerificationExpired); err != nil {
+			return err
+		}
+		now := time.Now().UTC()
+		_, err := tx.ExecContext(ctx, `
+			UPDATE domains
+			SET verification_attempts = verification_attempts + 1,
+				last_checked_at = $2, next_check_at = NULL, last_error = $3, claimed_until = NULL
+			WHERE id = $1
+		`, id, now, reason)
+		if err != nil {
+			return fmt.Errorf("failed to expire domain verification: %w", err)
+		}
+		return nil
+	})
+	return err
+}
+
+// RecordRecheckPassed records a successful re-verification of a verified
+// domain. A misconfigured domain recovers and a domain.recovered event is recorded.
+func (r *DomainRepository) RecordRecheckPassed(ctx context.Context, id string, next time.Time) error {
+	_, err := r.mutate(ctx, id, models.EventDomainRecovered, func(tx *Tx, before *models.Domain) error {
+		now := time.Now().UTC()
+		_, err := tx.ExecContext(ctx, `
+			UPDATE domains
+			SET recheck_failures = 0, misconfigured_at = NULL, last_error = NULL,
+				last_checked_at = $2, next_check_at = $3, claimed_until = NULL
+			WHERE id = $1
+		`, id, now, next)
+		if err != nil {
+			return fmt.Errorf("failed to record recheck: %w", err)
+		}
+		if before.Status != models.StatusMisconfigured {
+			return errNoChange
+		}
+		return setStatus(ctx, tx, before, models.StatusActive)
+	})
+	return err
+}
+
+// RecordRecheckFailed records a failed re-verification of a verified domain.
+// Once failures reach threshold the domain becomes misconfigured and a
+// domain.misconfigured event is recorded. It returns the updated domain.
+func (r *DomainRepository) RecordRecheckFailed(ctx context.Context, id, reason string, next time.Time, threshold int) (*models.Domain, error) {
+	var misconfigured bool
+	domain, err := r.mutate(ctx, id, models.EventDomainMisconfigured, func(tx *Tx, before *models.Domain) error {
+		now := time.Now().UTC()
+		misconfigured = before.Status != models.StatusMisconfigured && before.RecheckFailures+1 >= threshold
+		_, err := tx.ExecContext(ctx, `
+			UPDATE domains
+			SET recheck_failures = recheck_failures + 1, last_error = $2,
+				last_checked_at = $3, next_check_at = $4, claimed_until = NULL,
+				misconfigured_at = CASE WHEN $5 THEN $3 ELSE misconfigured_at END
+			WHERE id = $1
+		`, id, reason, now, next, misconfigured)
+		if err != nil {
+			return fmt.Errorf("failed to record recheck failure: %w", err)
+		}
+		if !misconfigured {
+			return errNoChange
+		}
+		return setStatus(ctx, tx, before, models.StatusMisconfigured)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !misconfigured {
+		return r.GetByID(ctx, id)
+	}
+	return domain, nil
+}
+
+// Reassign moves a domain to another tenant. The domain is no longer primary
+// for either tenant and takes on the new tenant's suspension state.
+// The event is recorded in both tenants' histories.
+func (r *DomainRepository) Reassign(ctx context.Context, id, tenantID string) error {
+	return r.withTx(ctx, func(tx *Tx) error {
+		before, err := lockDomain(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		after, err := reassignDomain(ctx, tx, before, tenantID)
+		if err != nil {
+			return err
+		}
+		return recordMove(ctx, tx, models.EventDomainReassigned, before, after, nil)
+	})
+}
+
+// Search lists domains across all tenants, optionally filtered by tenant and
+// a substring of the domain name. It returns the page and the total match count.
+func (r *DomainRepository) Search(ctx context.Context, search, tenantID string, limit, offset int) ([]models.Domain, int, error) {
+	where := `WHERE ($1 = '' OR domain LIKE $2 ESCAPE '\') AND ($3 = '' OR CAST(tenant_id AS TEXT) = $3)`
+	pattern := "%" + escapeLike(search) + "%"
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM domains `+where, search, pattern, tenantID).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count domains: %w", err)
+	}
+
 	query := `
-		SELECT id, tenant_id, domain, type, verified, verification_token, is_primary, ssl_issued, created_at, updated_at, verified_at
+		SELECT ` + domainColumns + `
 		FROM domains
-		WHERE verified = TRUE
-		ORDER BY domain ASC
+		` + where + `
+		ORDER BY created_at DESC
+		LIMIT $4 OFFSET $5
 	`
 
-	rows, err := r.db.QueryContext(ctx, query)
+	rows, err := r.db.QueryContext(ctx, query, search, pattern, tenantID, limit, offset)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list verified domains: %w", err)
+		return nil, 0, fmt.Errorf("failed to search domains: %w", err)
 	}
 	defer rows.Close()
 
+	domains, err := scanDomains(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return domains, total, nil
+}
+
+// SetTenantSuspended suspends or unsuspends all domains of a tenant and
+// records the tenant-level suspension. Archived and deleted domains are left alone.
+// It returns the domains whose state changed.
+func (r *DomainRepository) SetTenantSuspended(ctx context.Context, tenantID string, suspended bool, reason string) ([]models.Domain, error) {
+	now := time.Now().UTC()
+
+	var suspendedAt sql.NullTime
+	if suspended {
+		suspendedAt = sql.NullTime{Time: now, Valid: true}
+	}
+
+	action := models.EventDomainUnsuspended
+	if suspended {
+		action = models.EventDomainSuspended
+	}
+
 	var domains []models.Domain
-	for rows.Next() {
-		var domain models.Domain
-		var verifiedAt sql.NullTime
-		var verificationToken sql.NullString
-
-		if err := rows.Scan(
-			&domain.ID,
-			&domain.TenantID,
-			&domain.Domain,
-			&domain.Type,
-			&domain.Verified,
-			&verificationToken,
-			&domain.IsPrimary,
-			&domain.SSLIssued,
-			&domain.CreatedAt,
-			&domain.UpdatedAt,
-			&verifiedAt,
-		); err != nil {
-			return nil, fmt.Errorf("failed to scan domain: %w", err)
+	err := r.withTx(ctx, func(tx *Tx) error {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO tenant_settings (tenant_id, suspended, suspended_at, suspension_reason, updated_at)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (tenant_id) DO UPDATE SET
+				suspended = EXCLUDED.suspended,
+				suspended_at = EXCLUDED.suspended_at,
+				suspension_reason = EXCLUDED.suspension_reason,
+				updated_at = EXCLUDED.updated_at
+		`, tenantID, suspended, suspendedAt, nullString(reason), now)
+		if err != nil {
+			return fmt.Errorf("failed to record tenant suspension: %w", err)
+		}
+		if err := r.db.recordChange(ctx, tx, ChangeTenant, tenantID, "", nil); err != nil {
+			return err
 		}
 
-		if verifiedAt.Valid {
-			domain.VerifiedAt = &verifiedAt.Time
+		rows, err := tx.QueryContext(ctx, `
+			SELECT `+domainColumns+`
+			FROM domains
+			WHERE tenant_id = $1
+			FOR UPDATE
+		`, tenantID)
+		if err != nil {
+			return fmt.Errorf("failed to load tenant domains: %w", err)
 		}
-		if verificationToken.Valid {
-			domain.VerificationToken = verificationToken.String
+		current, err := scanDomains(rows)
+		rows.Close()
+		if err != nil {
+			return err
 		}
 
-		domains = append(domains, domain)
+		for i := range current {
+			before := &current[i]
+			if before.Suspended == suspended || before.Archived || before.Deleted {
+				continue
+			}
+			if err := holdSuspended(ctx, tx, before, suspended); err != nil {
+				return err
+			}
+
+			after, err := lockDomain(ctx, tx, before.ID)
+			if err != nil {
+				return err
+			}
+			if err := recordEvent(ctx, tx, action, before, after); err != nil {
+				return err
+			}
+			domains = append(domains, *after)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return domains, nil
 }
 
-// MarkVerified marks a domain as verified
-func (r *DomainRepository) MarkVerified(ctx context.Context, id string) error {
+// SetPrimary sets a domain as primary for a tenant
+func (r *DomainRepository) SetPrimary(ctx context.Context, tenantID, domainID string) error {
+	return r.withTx(ctx, func(tx *Tx) error {
+		rows, err := tx.QueryContext(ctx, `
+			SELECT `+domainColumns+`
+			FROM domains
+			WHERE tenant_id = $1 AND (is_primary = TRUE OR id = $2)
+			FOR UPDATE
+		`, tenantID, domainID)
+		if err != nil {
+			return fmt.Errorf("failed to load primary domains: %w", err)
+		}
+		affected, err := scanDomains(rows)
+		rows.Close()
+		if err != nil {
+			return err
+		}
+
+		// Unset all primary domains for tenant
+		_, err = tx.ExecContext(ctx, `UPDATE domains SET is_primary = FALSE WHERE tenant_id = $1`, tenantID)
+		if err != nil {
+			return fmt.Errorf("failed to unset primary domains: %w", err)
+		}
+
+		// Set the new primary domain
+		_, err = tx.ExecContext(ctx, `UPDATE domains SET is_primary = TRUE WHERE id = $1 AND tenant_id = $2`, domainID, tenantID)
+		if err != nil {
+			return fmt.Errorf("failed to set primary domain: %w", err)
+		}
+
+		for i := range affected {
+			before := &affected[i]
+			after := *before
+			after.IsPrimary = before.ID == domainID
+			if after.IsPrimary == before.IsPrimary {
+				continue
+			}
+
+			action := models.EventDomainPrimaryUnset
+			if after.IsPrimary {
+				action = models.EventDomainPrimarySet
+			}
+			if err := recordEvent(ctx, tx, action, before, &after); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// claim leases up to limit unclaimed domains matching condition to this
+// instance, moving them to status unless it is empty. The move goes through
+// the state machine; a domain that may not make it is not claimed. Only the
+// first check of a domain is recorded as a verification_started event, so
+// rechecks of an unverified domain do not fill its history.
+func (r *DomainRepository) claim(ctx context.Context, condition string, status models.DomainStatus, limit int, lease time.Duration) ([]models.Domain, error) {
 	now := time.Now().UTC()
+
 	query := `
-		UPDATE domains
-		SET verified = TRUE, verified_at = $2, updated_at = $3
-		WHERE id = $1
+		SELECT ` + domainColumns + `
+		FROM domains
+		WHERE ` + condition + `
+		  AND (claimed_until IS NULL OR claimed_until < $1)
+		ORDER BY created_at ASC
+		LIMIT $2
+		FOR UPDATE SKIP LOCKED
 	`
 
-	result, err := r.db.ExecContext(ctx, query, id, now, now)
+	var claimed []models.Domain
+	err := r.withTx(ctx, func(tx *Tx) error {
+		rows, err := tx.QueryContext(ctx, query, now, limit)
+		if err != nil {
+			return fmt.Errorf("failed to claim domains: %w", err)
+		}
+		domains, err := scanDomains(rows)
+		rows.Close()
+		if err != nil {
+			return err
+		}
+
+		for i := range domains {
+			before := &domains[i]
+			move := status != "" && before.Status != status
+			if move && !before.CanTransition(status) {
+				continue
+			}
+
+			_, err := tx.ExecContext(ctx, `
+				UPDATE domains SET claimed_by = $2, claimed_until = $3 WHERE id = $1
+			`, before.ID, r.db.InstanceID(), now.Add(lease))
+			if err != nil {
+				return fmt.Errorf("failed to claim domain: %w", err)
+			}
+			if move {
+				if err := setStatus(ctx, tx, before, status); err != nil {
+					return err
+				}
+			}
+
+			after, err := lockDomain(ctx, tx, before.ID)
+			if err != nil {
+				return err
+			}
+			if move && before.LastCheckedAt == nil {
+				if err := recordEvent(ctx, tx, models.EventDomainVerificationStarted, before, after); err != nil {
+					return err
+				}
+			}
+			claimed = append(claimed, *after)
+		}
+		return nil
+	})
 	if err != nil {
-		return fmt.Errorf("failed to mark domain verified: %w", err)
+		return nil, err
 	}
 
-	rows, _ := result.RowsAffected()
-	if rows == 0 {
-		return fmt.Errorf("domain not found")
-	}
+	return claimed, nil
+}
 
-	return nil
+// withTx runs fn in a transaction, committing if it returns nil
+func (r *DomainRepository) withTx(ctx context.Context, fn func(tx *Tx) error) error {
+	return r.db.WithTx(ctx, fn)
 }
 
-// MarkSSLIssued marks a domain as having SSL issued
-func (r *DomainRepository) MarkSSLIssued(ctx context.Context, id string) error {
-	query := `
-		UPDATE domains
-		SET ssl_issued = TRUE, updated_at = $2
-		WHERE id = $1
-	`
+// mutate locks a domain, applies update and records the change as an event,
+// all in one transaction. It returns the domain as it is after the update,
+// or nil if the update deleted it. An update returning errNoChange keeps its
+// writes but records no event.
+func (r *DomainRepository) mutate(ctx context.Context, id, action string, update func(tx *Tx, before *models.Domain) error) (*models.Domain, error) {
+	var after *models.Domain
+	err := r.withTx(ctx, func(tx *Tx) error {
+		before, err := lockDomain(ctx, tx, id)
+		if err != nil {
+			return err
+		}
 
-	result, err := r.db.ExecContext(ctx, query, id, time.Now().UTC())
+		if err := update(tx, before); err != nil {
+			if errors.Is(err, errNoChange) {
+				after = before
+				return nil
+			}
+			return err
+		}
+
+		after, err = lockDomain(ctx, tx, id)
+		if err == ErrDomainNotFound {
+			after = nil
+		} else if err != nil {
+			return err
+		}
+
+		return recordEvent(ctx, tx, action, before, after)
+	})
 	if err != nil {
-		return fmt.Errorf("failed to mark SSL issued: %w", err)
+		return nil, err
+	}
+	return after, nil
+}
+
+// setStatus moves a locked domain to status, enforcing the state machine in
+// models. Moving to the current status is a no-op. Holding a domain remembers
+// the state it resumes to.
+func setStatus(ctx context.Context, tx *Tx, domain *models.Domain, to models.DomainStatus) error {
+	if domain.Status == to {
+		return nil
+	}
+	if !domain.CanTransition(to) {
+		return fmt.Errorf("%w: %s to %s", ErrInvalidTransition, domain.Status, to)
 	}
 
-	rows, _ := result.RowsAffected()
-	if rows == 0 {
-		return fmt.Errorf("domain not found")
+	var resume sql.NullString
+	if to.IsHeld() {
+		resume.Valid = true
+		resume.String = string(domain.Status)
+		if domain.Status.IsHeld() {
+			resume.String = string(domain.ResumeStatus)
+		}
 	}
 
+	_, err := tx.ExecContext(ctx, `
+		UPDATE domains SET status = $2, resume_status = $3, updated_at = $4 WHERE id = $1
+	`, domain.ID, to, resume, time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("failed to set domain status: %w", err)
+	}
 	return nil
 }
 
-// Delete deletes a domain
-func (r *DomainRepository) Delete(ctx context.Context, id string) error {
-	query := `DELETE FROM domains WHERE id = $1`
+// holdSuspended suspends a locked domain, or returns a suspended one to the
+// state it was suspended in. Archived and deleted domains are left alone.
+func holdSuspended(ctx context.Context, tx *Tx, domain *models.Domain, suspended bool) error {
+	switch {
+	case domain.Archived, domain.Deleted:
+		return nil
+	case suspended:
+		return setStatus(ctx, tx, domain, models.StatusSuspended)
+	case domain.Suspended:
+		return setStatus(ctx, tx, domain, domain.ResumeTarget())
+	}
+	return nil
+}
 
-	result, err := r.db.ExecContext(ctx, query, id)
+// reassignDomain moves a locked domain to another tenant, unsetting primary
+// and applying the new tenant's suspension state. It returns the moved domain.
+func reassignDomain(ctx context.Context, tx *Tx, before *models.Domain, tenantID string) (*models.Domain, error) {
+	_, err := tx.ExecContext(ctx, `
+		UPDATE domains
+		SET tenant_id = $2, is_primary = FALSE, updated_at = $3
+		WHERE id = $1
+	`, before.ID, tenantID, time.Now().UTC())
 	if err != nil {
-		return fmt.Errorf("failed to delete domain: %w", err)
+		return nil, fmt.Errorf("failed to reassign domain: %w", err)
 	}
 
-	rows, _ := result.RowsAffected()
-	if rows == 0 {
-		return fmt.Errorf("domain not found")
+	suspended, err := tenantSuspended(ctx, tx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if err := holdSuspended(ctx, tx, before, suspended); err != nil {
+		return nil, err
 	}
 
-	return nil
+	return lockDomain(ctx, tx, before.ID)
 }
 
-// Update updates a domain
-func (r *DomainRepository) Update(ctx context.Context, domain *models.Domain) error {
-	domain.UpdatedAt = time.Now().UTC()
+// recordMove records a domain's move between tenants in both tenants'
+// histories. data is the webhook payload, defaulting to the domain.
+func recordMove(ctx context.Context, tx *Tx, action string, before, after *models.Domain, data interface{}) error {
+	if err := recordEventData(ctx, tx, action, before, after, data); err != nil {
+		return err
+	}
+	if before.TenantID == after.TenantID {
+		return nil
+	}
+
+	// Keep the previous owner's history pointing at the move
+	previous := *after
+	previous.TenantID = before.TenantID
+	return recordEventData(ctx, tx, action, before, &previous, data)
+}
 
+// insertDomain inserts a new domain row. It returns ErrDomainExists if the
+// name is taken.
+func insertDomain(ctx context.Context, tx *Tx, domain *models.Domain) error {
 	query := `
-		UPDATE domains
-		SET redirect_url = $2, archived = $3, updated_at = $4
-		WHERE id = $1
+		INSERT INTO domains (id, tenant_id, domain, type, status, verification_token, is_primary, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id
 	`
 
-	result, err := r.db.ExecContext(ctx, query,
+	err := tx.QueryRowContext(ctx, query,
 		domain.ID,
-		domain.RedirectURL,
-		domain.Archived,
+		domain.TenantID,
+		domain.Domain,
+		domain.Type,
+		domain.Status,
+		domain.VerificationToken,
+		domain.IsPrimary,
+		domain.CreatedAt,
 		domain.UpdatedAt,
-	)
+	).Scan(&domain.ID)
+	if isUniqueViolation(err) {
+		return ErrDomainExists
+	}
 	if err != nil {
-		return fmt.Errorf("failed to update domain: %w", err)
+		return fmt.Errorf("failed to create domain: %w", err)
 	}
+	return nil
+}
+
+// tenantSuspended reports whether a tenant is suspended
+func tenantSuspended(ctx context.Context, tx *Tx, tenantID string) (bool, error) {
+	var suspended bool
+	err := tx.QueryRowContext(ctx, `
+		SELECT COALESCE((SELECT suspended FROM tenant_settings WHERE tenant_id = $1), FALSE)
+	`, tenantID).Scan(&suspended)
+	if err != nil {
+		return false, fmt.Errorf("failed to load tenant suspension: %w", err)
+	}
+	return suspended, nil
+}
 
-	rows, _ := result.RowsAffected()
-	if rows == 0 {
-		return fmt.Errorf("domain not found")
+// domainFilterCondition builds the WHERE condition and its arguments for a
+// domain listing filter, without the cursor
+func domainFilterCondition(filter models.DomainFilter) (string, []interface{}) {
+	args := []interface{}{filter.TenantID}
+	conditions := []string{"tenant_id = $1"}
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return "$" + strconv.Itoa(len(args))
 	}
 
-	return nil
+	if filter.Deleted {
+		conditions = append(conditions, "status = 'deleted'")
+	} else {
+		conditions = append(conditions, "status <> 'deleted'")
+	}
+
+	if filter.Type != "" {
+		conditions = append(conditions, "type = "+arg(string(filter.Type)))
+	}
+	if filter.Status != "" {
+		conditions = append(conditions, "status = "+arg(string(filter.Status)))
+	}
+	if filter.Verified != nil {
+		if *filter.Verified {
+			conditions = append(conditions, verifiedCondition)
+		} else {
+			conditions = append(conditions, "NOT "+verifiedCondition)
+		}
+	}
+	if filter.Primary != nil {
+		conditions = append(conditions, "is_primary = "+arg(*filter.Primary))
+	}
+	if filter.Archived != nil {
+		if *filter.Archived {
+			conditions = append(conditions, "status = 'archived'")
+		} else {
+			conditions = append(conditions, "status <> 'archived'")
+		}
+	}
+	if filter.Search != "" {
+		conditions = append(conditions, `domain LIKE `+arg("%"+escapeLike(strings.ToLower(filter.Search))+"%")+` ESCAPE '\'`)
+	}
+
+	return strings.Join(conditions, " AND "), args
 }
 
-// SetPrimary sets a domain as primary for a tenant
-func (r *DomainRepository) SetPrimary(ctx context.Context, tenantID, domainID string) error {
-	tx, err := r.db.BeginTx(ctx, nil)
-	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
+// escapeLike escapes the LIKE wildcards in s
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+// statusList formats statuses as an SQL list for IN
+func statusList(statuses ...models.DomainStatus) string {
+	list := "("
+	for i, status := range statuses {
+		if i > 0 {
+			list += ", "
+		}
+		list += "'" + string(status) + "'"
 	}
-	defer tx.Rollback()
+	return list + ")"
+}
+
+// lockDomain loads a domain within a transaction, locking its row
+func lockDomain(ctx context.Context, tx *Tx, id string) (*models.Domain, error) {
+	query := `
+		SELECT ` + domainColumns + `
+		FROM domains
+		WHERE id = $1
+		FOR UPDATE
+	`
 
-	// Unset all primary domains for tenant
-	_, err = tx.ExecContext(ctx, `UPDATE domains SET is_primary = FALSE WHERE tenant_id = $1`, tenantID)
+	domain, err := scanDomain(tx.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrDomainNotFound
+	}
 	if err != nil {
-		return fmt.Errorf("failed to unset primary domains: %w", err)
+		return nil, fmt.Errorf("failed to get domain: %w", err)
 	}
+	return domain, nil
+}
 
-	// Set the new primary domain
-	_, err = tx.ExecContext(ctx, `UPDATE domains SET is_primary = TRUE WHERE id = $1 AND tenant_id = $2`, domainID, tenantID)
-	if err != nil {
-		return fmt.Errorf("failed to set primary domain: %w", err)
+// scanDomain scans a single domain row selected with domainColumns
+func scanDomain(row rowScanner) (*models.Domain, error) {
+	domain := &models.Domain{}
+	var status string
+	var verifiedAt, lastCheckedAt, nextCheckAt, pendingSince, misconfiguredAt, deletedAt sql.NullTime
+	var resumeStatus, verificationToken, lastError, redirectURL sql.NullString
+
+	if err := row.Scan(
+		&domain.ID,
+		&domain.TenantID,
+		&domain.Domain,
+		&domain.Type,
+		&status,
+		&resumeStatus,
+		&verificationToken,
+		&domain.IsPrimary,
+		&domain.CreatedAt,
+		&domain.UpdatedAt,
+		&verifiedAt,
+		&domain.VerificationAttempts,
+		&lastCheckedAt,
+		&nextCheckAt,
+		&lastError,
+		&pendingSince,
+		&domain.RecheckFailures,
+		&misconfiguredAt,
+		&redirectURL,
+		&deletedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	domain.SetStatus(models.DomainStatus(status), models.DomainStatus(resumeStatus.String))
+	if verifiedAt.Valid {
+		domain.VerifiedAt = &verifiedAt.Time
+	}
+	if verificationToken.Valid {
+		domain.VerificationToken = verificationToken.String
+	}
+	if lastCheckedAt.Valid {
+		domain.LastCheckedAt = &lastCheckedAt.Time
+	}
+	if nextCheckAt.Valid {
+		domain.NextCheckAt = &nextCheckAt.Time
+	}
+	domain.LastError = lastError.String
+	domain.RedirectURL = redirectURL.String
+	if pendingSince.Valid {
+		domain.PendingSince = &pendingSince.Time
+	}
+	if misconfiguredAt.Valid {
+		domain.MisconfiguredAt = &misconfiguredAt.Time
+	}
+	if deletedAt.Valid {
+		domain.DeletedAt = &deletedAt.Time
+	}
+
+	return domain, nil
+}
+
+// scanDomains scans all domain rows selected with domainColumns
+func scanDomains(rows *sql.Rows) ([]models.Domain, error) {
+	var domains []models.Domain
+	for rows.Next() {
+		domain, err := scanDomain(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan domain: %w", err)
+		}
+		domains = append(domains, *domain)
 	}
 
-	return tx.Commit()
+	return domains, rows.Err()
 }
@@ -0,0 +1,135 @@
+package database_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/panaroid/domain-gateway/internal/config"
+	"github.com/panaroid/domain-gateway/internal/database"
+	"github.com/panaroid/domain-gateway/internal/database/storetest"
+	"github.com/panaroid/domain-gateway/pkg/models"
+)
+
+// testDatabaseURL names a Postgres database the repository suite may wipe
+const testDatabaseURL = "GATEWAY_TEST_DATABASE_URL"
+
+func TestDomainRepositorySQLite(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) database.DomainStore {
+		db := openDB(t, "sqlite://"+filepath.Join(t.TempDir(), "gateway.db"))
+		return database.NewDomainRepository(db)
+	})
+}
+
+func TestDomainRepositoryPostgres(t *testing.T) {
+	url := os.Getenv(testDatabaseURL)
+	if url == "" {
+		t.Skip(testDatabaseURL + " not set")
+	}
+
+	storetest.Run(t, func(t *testing.T) database.DomainStore {
+		db := openDB(t, url)
+		truncate(t, db)
+		return database.NewDomainRepository(db)
+	})
+}
+
+// openDB connects to url and migrates it to the latest version
+func openDB(t *testing.T, url string) *database.DB {
+	t.Helper()
+
+	db, err := database.New(config.DatabaseConfig{
+		URL:             url,
+		MaxOpenConns:    5,
+		MaxIdleConns:    5,
+		ConnMaxLifetime: time.Minute,
+	}, zap.NewNop())
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	if err := db.MigrateUp(context.Background(), 0); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+	return db
+}
+
+// truncate empties every table but the migration history, so each subtest
+// starts from an empty store
+func truncate(t *testing.T, db *database.DB) {
+	t.Helper()
+
+	_, err := db.Exec(`
+		DO $$
+		DECLARE tables TEXT;
+		BEGIN
+			SELECT string_agg(quote_ident(tablename), ', ') INTO tables
+			FROM pg_tables
+			WHERE schemaname = current_schema() AND tablename <> 'schema_migrations';
+			IF tables IS NOT NULL THEN
+				EXECUTE 'TRUNCATE ' || tables || ' CASCADE';
+			END IF;
+		END $$
+	`)
+	if err != nil {
+		t.Fatalf("failed to truncate database: %v", err)
+	}
+}
+
+func TestVerificationChecksRecordFirstStartOnly(t *testing.T) {
+	db := openDB(t, "sqlite://"+filepath.Join(t.TempDir(), "gateway.db"))
+	repo := database.NewDomainRepository(db)
+	events := database.NewEventRepository(db)
+	testVerificationCheckEvents(t, repo, func(tenantID, domainID string) []models.DomainEvent {
+		list, _, err := events.ListByDomain(context.Background(), tenantID, domainID, 100, 0)
+		if err != nil {
+			t.Fatalf("ListByDomain: %v", err)
+		}
+		return list
+	})
+
+	memory := database.NewMemoryStore()
+	testVerificationCheckEvents(t, memory, func(string, string) []models.DomainEvent {
+		return memory.Events()
+	})
+}
+
+// testVerificationCheckEvents checks a domain three times and expects only
+// its creation and the first check in its history
+func testVerificationCheckEvents(t *testing.T, store database.DomainStore, history func(tenantID, domainID string) []models.DomainEvent) {
+	t.Helper()
+	ctx := context.Background()
+
+	domain := &models.Domain{TenantID: uuid.New().String(), Domain: "checked.example.com", Type: models.DomainTypeCustom}
+	if err := store.Create(ctx, domain); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		claimed, err := store.ClaimPendingVerification(ctx, 10, time.Minute)
+		if err != nil || len(claimed) != 1 {
+			t.Fatalf("ClaimPendingVerification = %v, %v; want the domain", claimed, err)
+		}
+		if err := store.ScheduleRetry(ctx, domain.ID, "no CNAME", time.Now().Add(-time.Second)); err != nil {
+			t.Fatalf("ScheduleRetry: %v", err)
+		}
+	}
+
+	var actions []string
+	for _, event := range history(domain.TenantID, domain.ID) {
+		actions = append(actions, event.Action)
+	}
+	sort.Strings(actions)
+	want := []string{models.EventDomainCreated, models.EventDomainVerificationStarted}
+	if strings.Join(actions, ",") != strings.Join(want, ",") {
+		t.Errorf("events = %v, want %v", actions, want)
+	}
+}
@@ -0,0 +1,253 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/panaroid/domain-gateway/pkg/models"
+)
+
+// contextKey is a custom type for context keys
+type contextKey string
+
+const (
+	contextKeyActor     contextKey = "actor"
+	contextKeyRequestID contextKey = "request_id"
+)
+
+// WithActor attaches the principal responsible for changes made with ctx
+func WithActor(ctx context.Context, actor models.Actor) context.Context {
+	return context.WithValue(ctx, contextKeyActor, actor)
+}
+
+// ActorFromContext returns the actor attached to ctx, defaulting to the system
+func ActorFromContext(ctx context.Context) models.Actor {
+	if actor, ok := ctx.Value(contextKeyActor).(models.Actor); ok {
+		return actor
+	}
+	return models.Actor{Type: models.ActorSystem}
+}
+
+// WithRequestID attaches the ID of the request making changes with ctx
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, contextKeyRequestID, requestID)
+}
+
+// RequestIDFromContext returns the request ID attached to ctx
+func RequestIDFromContext(ctx context.Context) string {
+	if id, ok := ctx.Value(contextKeyRequestID).(string); ok {
+		return id
+	}
+	return ""
+}
+
+// recordEvent writes a domain event within the caller's transaction.
+// A nil before marks a creation; a nil after marks a deletion.
+func recordEvent(ctx context.Context, tx *Tx, action string, before, after *models.Domain) error {
+	return recordEventData(ctx, tx, action, before, after, nil)
+}
+
+// recordEventData writes a domain event and, for webhook event types, its outbox
+// entry within the caller's transaction. data is the webhook payload, defaulting
+// to the domain itself. The event is published to listeners after commit.
+func recordEventData(ctx context.Context, tx *Tx, action string, before, after *models.Domain, data interface{}) error {
+	subject := after
+	if subject == nil {
+		subject = before
+	}
+
+	event, err := newDomainEvent(ctx, action, before, after)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO domain_events (id, domain_id, tenant_id, domain, action, actor_type, actor_id, request_id, before_state, after_state, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`,
+		event.ID,
+		event.DomainID,
+		event.TenantID,
+		event.Domain,
+		action,
+		string(event.Actor.Type),
+		nullString(event.Actor.ID),
+		nullString(event.RequestID),
+		rawState(event.Before),
+		rawState(event.After),
+		event.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record domain event: %w", err)
+	}
+
+	tx.events = append(tx.events, event)
+
+	if err := tx.db.recordChange(ctx, tx, ChangeDomain, subject.TenantID, subject.ID, &event); err != nil {
+		return err
+	}
+
+	if !models.IsWebhookEvent(action) {
+		return nil
+	}
+
+	if data == nil {
+		data = subject
+	}
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to encode event data: %w", err)
+	}
+
+	// The outbox entry shares the audit event's ID so deliveries can be traced back
+	payload, err := json.Marshal(models.WebhookEvent{
+		ID:        event.ID,
+		Type:      action,
+		TenantID:  subject.TenantID,
+		CreatedAt: event.CreatedAt,
+		Data:      encoded,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode outbox payload: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO outbox (id, tenant_id, event_type, payload, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`, event.ID, subject.TenantID, action, string(payload), event.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to write outbox entry: %w", err)
+	}
+
+	return nil
+}
+
+// newDomainEvent builds the event for a change made with ctx, snapshotting
+// the domain before and after it. A nil before or after is left empty.
+func newDomainEvent(ctx context.Context, action string, before, after *models.Domain) (models.DomainEvent, error) {
+	subject := after
+	if subject == nil {
+		subject = before
+	}
+
+	event := models.DomainEvent{
+		ID:        uuid.New().String(),
+		DomainID:  subject.ID,
+		TenantID:  subject.TenantID,
+		Domain:    subject.Domain,
+		Action:    action,
+		Actor:     ActorFromContext(ctx),
+		RequestID: RequestIDFromContext(ctx),
+		CreatedAt: time.Now().UTC(),
+	}
+
+	var err error
+	if event.Before, err = snapshot(before); err != nil {
+		return models.DomainEvent{}, err
+	}
+	if event.After, err = snapshot(after); err != nil {
+		return models.DomainEvent{}, err
+	}
+	return event, nil
+}
+
+// snapshot serializes a domain for an event, nil for a missing domain
+func snapshot(domain *models.Domain) (json.RawMessage, error) {
+	if domain == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(domain)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode domain snapshot: %w", err)
+	}
+	return data, nil
+}
+
+// rawState stores a snapshot, NULL for a missing domain
+func rawState(state json.RawMessage) sql.NullString {
+	if state == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: string(state), Valid: true}
+}
+
+// EventRepository reads the domain event history
+type EventRepository struct {
+	db *DB
+}
+
+// NewEventRepository creates a new event repository
+func NewEventRepository(db *DB) *EventRepository {
+	return &EventRepository{db: db}
+}
+
+// ListByDomain retrieves a tenant's events for one domain, newest first.
+// Events outlive the domain, so deleted domains keep their history.
+func (r *EventRepository) ListByDomain(ctx context.Context, tenantID, domainID string, limit, offset int) ([]models.DomainEvent, int, error) {
+	return r.list(ctx, `WHERE tenant_id = $1 AND domain_id = $2`, []interface{}{tenantID, domainID}, limit, offset)
+}
+
+// ListByTenant retrieves all of a tenant's domain events, newest first
+func (r *EventRepository) ListByTenant(ctx context.Context, tenantID string, limit, offset int) ([]models.DomainEvent, int, error) {
+	return r.list(ctx, `WHERE tenant_id = $1`, []interface{}{tenantID}, limit, offset)
+}
+
+func (r *EventRepository) list(ctx context.Context, where string, args []interface{}, limit, offset int) ([]models.DomainEvent, int, error) {
+	var total int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM domain_events `+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count domain events: %w", err)
+	}
+
+	n := len(args)
+	query := fmt.Sprintf(`
+		SELECT id, domain_id, tenant_id, domain, action, actor_type, actor_id, request_id, before_state, after_state, created_at
+		FROM domain_events
+		%s
+		ORDER BY created_at DESC, id DESC
+		LIMIT $%d OFFSET $%d
+	`, where, n+1, n+2)
+
+	rows, err := r.db.QueryContext(ctx, query, append(args, limit, offset)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list domain events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []models.DomainEvent
+	for rows.Next() {
+		var event models.DomainEvent
+		var actorType string
+		var actorID, requestID, before, after sql.NullString
+		if err := rows.Scan(
+			&event.ID,
+			&event.DomainID,
+			&event.TenantID,
+			&event.Domain,
+			&event.Action,
+			&actorType,
+			&actorID,
+			&requestID,
+			&before,
+			&after,
+			&event.CreatedAt,
+		); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan domain event: %w", err)
+		}
+		event.Actor = models.Actor{Type: models.ActorType(actorType), ID: actorID.String}
+		event.RequestID = requestID.String
+		if before.Valid {
+			event.Before = json.RawMessage(before.String)
+		}
+		if after.Valid {
+			event.After = json.RawMessage(after.String)
+		}
+		events = append(events, event)
+	}
+
+	return events, total, rows.Err()
+}
@@ -0,0 +1,890 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/panaroid/domain-gateway/pkg/models"
+)
+
+// MemoryStore is a DomainStore held in memory, for tests and tooling that
+// should not need a database. It follows DomainRepository's semantics:
+// domain names are unique, changes record events, and status transitions are
+// enforced. Events are passed to OnDomainEvent listeners after each change.
+type MemoryStore struct {
+	mu        sync.Mutex
+	domains   map[string]*memoryDomain
+	suspended map[string]bool
+	events    []models.DomainEvent
+	listeners []func(models.DomainEvent)
+
+	// settings holds stored tenant settings; suspension lives in suspended
+	settings map[string]models.TenantSettings
+	global   models.GlobalSettings
+}
+
+// memoryDomain is a stored domain with its claim lease
+type memoryDomain struct {
+	domain       models.Domain
+	claimedUntil time.Time
+}
+
+// NewMemoryStore creates an empty in-memory domain store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		domains:   make(map[string]*memoryDomain),
+		suspended: make(map[string]bool),
+		settings:  make(map[string]models.TenantSettings),
+	}
+}
+
+// OnDomainEvent registers fn to receive each domain event once its change is applied
+func (s *MemoryStore) OnDomainEvent(fn func(models.DomainEvent)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.listeners = append(s.listeners, fn)
+}
+
+// Events returns the domain events recorded so far, oldest first
+func (s *MemoryStore) Events() []models.DomainEvent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]models.DomainEvent(nil), s.events...)
+}
+
+// Create creates a new domain in its initial status, pending DNS if unset.
+// It returns ErrDomainExists if the name is taken.
+func (s *MemoryStore) Create(ctx context.Context, domain *models.Domain) error {
+	if domain.ID == "" {
+		domain.ID = uuid.New().String()
+	}
+	if domain.Status == "" {
+		domain.SetStatus(models.StatusPendingDNS, "")
+	}
+	domain.CreatedAt = time.Now().UTC()
+	domain.UpdatedAt = time.Now().UTC()
+
+	return s.apply(func(record recordFunc) error {
+		for _, stored := range s.domains {
+			if stored.domain.Domain == domain.Domain || stored.domain.ID == domain.ID {
+				return ErrDomainExists
+			}
+		}
+
+		// Only the columns DomainRepository inserts are kept
+		stored := models.Domain{
+			ID:                domain.ID,
+			TenantID:          domain.TenantID,
+			Domain:            domain.Domain,
+			Type:              domain.Type,
+			VerificationToken: domain.VerificationToken,
+			IsPrimary:         domain.IsPrimary,
+			CreatedAt:         domain.CreatedAt,
+			UpdatedAt:         domain.UpdatedAt,
+		}
+		stored.SetStatus(domain.Status, "")
+		s.domains[domain.ID] = &memoryDomain{domain: stored}
+		return record(ctx, models.EventDomainCreated, nil, &stored)
+	})
+}
+
+// GetByID retrieves a domain by ID
+func (s *MemoryStore) GetByID(ctx context.Context, id string) (*models.Domain, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if stored, ok := s.domains[id]; ok {
+		domain := stored.domain
+		return &domain, nil
+	}
+	return nil, nil
+}
+
+// GetByDomain retrieves a domain by domain name
+func (s *MemoryStore) GetByDomain(ctx context.Context, domainName string) (*models.Domain, error) {
+	domains := s.filter(func(d *memoryDomain) bool { return d.domain.Domain == domainName })
+	if len(domains) == 0 {
+		return nil, nil
+	}
+	return &domains[0], nil
+}
+
+// GetPrimaryByTenant retrieves the primary domain of a tenant
+func (s *MemoryStore) GetPrimaryByTenant(ctx context.Context, tenantID string) (*models.Domain, error) {
+	domains := s.filter(func(d *memoryDomain) bool {
+		return d.domain.TenantID == tenantID && d.domain.IsPrimary
+	})
+	if len(domains) == 0 {
+		return nil, nil
+	}
+	return &domains[0], nil
+}
+
+// ListByTenant retrieves all domains for a tenant, newest first
+func (s *MemoryStore) ListByTenant(ctx context.Context, tenantID string) ([]models.Domain, error) {
+	domains := s.filter(func(d *memoryDomain) bool { return d.domain.TenantID == tenantID })
+	sortNewestFirst(domains)
+	return domains, nil
+}
+
+// List retrieves a page of a tenant's domains matching filter, newest first
+func (s *MemoryStore) List(ctx context.Context, filter models.DomainFilter) (*DomainPage, error) {
+	cursor, err := decodeCursor(filter.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	limit := pageSize(filter.Limit)
+
+	domains := s.filter(func(d *memoryDomain) bool { return memoryMatches(&d.domain, filter) })
+	sort.Slice(domains, func(i, j int) bool {
+		if !domains[i].CreatedAt.Equal(domains[j].CreatedAt) {
+			return domains[i].CreatedAt.After(domains[j].CreatedAt)
+		}
+		return domains[i].ID > domains[j].ID
+	})
+
+	page := &DomainPage{Total: len(domains)}
+	if cursor != nil {
+		start := sort.Search(len(domains), func(i int) bool {
+			return cursor.follows(domains[i].CreatedAt, domains[i].ID)
+		})
+		domains = domains[start:]
+	}
+	if len(domains) > limit {
+		domains = domains[:limit]
+		last := domains[limit-1]
+		page.NextCursor = encodeCursor(last.CreatedAt, last.ID)
+	}
+	page.Domains = domains
+
+	return page, nil
+}
+
+// GetPendingVerification retrieves all domains due for a verification check
+func (s *MemoryStore) GetPendingVerification(ctx context.Context) ([]models.Domain, error) {
+	now := time.Now()
+	domains := s.filter(func(d *memoryDomain) bool { return memoryPending(&d.domain, now) })
+	sortOldestFirst(domains)
+	return domains, nil
+}
+
+// ClaimPendingVerification claims up to limit unclaimed domains due for a
+// check until lease expires, moving them to verifying
+func (s *MemoryStore) ClaimPendingVerification(ctx context.Context, limit int, lease time.Duration) ([]models.Domain, error) {
+	return s.claim(ctx, memoryPending, models.StatusVerifying, limit, lease)
+}
+
+// ClaimDueRecheck claims up to limit verified domains due for re-verification
+func (s *MemoryStore) ClaimDueRecheck(ctx context.Context, limit int, lease time.Duration) ([]models.Domain, error) {
+	return s.claim(ctx, memoryRecheckDue, "", limit, lease)
+}
+
+// GetAllVerified retrieves all verified domains, including suspended ones,
+// ordered by name
+func (s *MemoryStore) GetAllVerified(ctx context.Context) ([]models.Domain, error) {
+	domains := s.filter(func(d *memoryDomain) bool { return memoryVerified(&d.domain) })
+	sort.Slice(domains, func(i, j int) bool { return domains[i].Domain < domains[j].Domain })
+	return domains, nil
+}
+
+// MarkVerified marks a domain as verified. Marking an already verified domain is a no-op.
+func (s *MemoryStore) MarkVerified(ctx context.Context, id string) error {
+	_, err := s.mutate(ctx, id, models.EventDomainVerified, func(d *memoryDomain, before *models.Domain) error {
+		if before.Verified {
+			return errNoChange
+		}
+		if err := memorySetStatus(&d.domain, models.StatusCertPending); err != nil {
+			return err
+		}
+		now := time.Now().UTC()
+		d.domain.VerifiedAt = &now
+		d.domain.LastCheckedAt = &now
+		d.domain.NextCheckAt = nil
+		d.domain.LastError = ""
+		return nil
+	})
+	return err
+}
+
+// MarkUnverified clears a domain's verification and restarts its check schedule
+func (s *MemoryStore) MarkUnverified(ctx context.Context, id string) error {
+	_, err := s.mutate(ctx, id, models.EventDomainUnverified, func(d *memoryDomain, before *models.Domain) error {
+		if err := memorySetStatus(&d.domain, models.StatusPendingDNS); err != nil {
+			return err
+		}
+		now := time.Now().UTC()
+		d.domain.VerifiedAt = nil
+		d.domain.IsPrimary = false
+		d.domain.UpdatedAt = now
+		d.domain.VerificationAttempts = 0
+		d.domain.NextCheckAt = nil
+		d.domain.LastError = ""
+		d.domain.PendingSince = &now
+		d.domain.RecheckFailures = 0
+		d.domain.MisconfiguredAt = nil
+		return nil
+	})
+	return err
+}
+
+// MarkSSLIssued activates a domain whose certificate is pending or failed.
+// It is a no-op for any other status.
+func (s *MemoryStore) MarkSSLIssued(ctx context.Context, id string) error {
+	_, err := s.mutate(ctx, id, models.EventCertificateIssued, func(d *memoryDomain, before *models.Domain) error {
+		if before.Status != models.StatusCertPending && before.Status != models.StatusCertFailed {
+			return errNoChange
+		}
+		return memorySetStatus(&d.domain, models.StatusActive)
+	})
+	return err
+}
+
+// MarkCertFailed records that an active or pending domain is not serving a
+// valid certificate. It is a no-op for any other status.
+func (s *MemoryStore) MarkCertFailed(ctx context.Context, id, reason string) error {
+	return s.apply(func(record recordFunc) error {
+		d, ok := s.domains[id]
+		if !ok {
+			return ErrDomainNotFound
+		}
+		before := d.domain
+		if before.Status != models.StatusCertPending && before.Status != models.StatusActive {
+			return nil
+		}
+
+		after := before
+		if err := memorySetStatus(&after, models.StatusCertFailed); err != nil {
+			return err
+		}
+		after.LastError = reason
+		d.domain = after
+
+		return record(ctx, models.EventCertificateFailed, &before, &after)
+	})
+}
+
+// Delete moves a domain to the trash, keeping its name reserved to its
+// tenant. Deleting a domain in the trash is a no-op.
+func (s *MemoryStore) Delete(ctx context.Context, id string) error {
+	_, err := s.mutate(ctx, id, models.EventDomainDeleted, func(d *memoryDomain, before *models.Domain) error {
+		if before.Deleted {
+			return errNoChange
+		}
+		if err := memorySetStatus(&d.domain, models.StatusDeleted); err != nil {
+			return err
+		}
+		now := time.Now().UTC()
+		d.domain.DeletedAt = &now
+		d.domain.IsPrimary = false
+		d.claimedUntil = time.Time{}
+		return nil
+	})
+	return err
+}
+
+// Restore takes a domain out of the trash, returning it to the state it was
+// deleted in (suspended if its tenant now is)
+func (s *MemoryStore) Restore(ctx context.Context, id string) (*models.Domain, error) {
+	return s.mutate(ctx, id, models.EventDomainRestored, func(d *memoryDomain, before *models.Domain) error {
+		if !before.Deleted {
+			return fmt.Errorf("%w: %s is not deleted", ErrInvalidTransition, before.Status)
+		}
+		to := before.ResumeTarget()
+		if s.suspended[before.TenantID] {
+			to = models.StatusSuspended
+		}
+		if err := memorySetStatus(&d.domain, to); err != nil {
+			return err
+		}
+		d.domain.DeletedAt = nil
+		return nil
+	})
+}
+
+// PurgeDeleted permanently deletes up to limit domains that were moved to the
+// trash before cutoff, oldest first. It returns the purged domains.
+func (s *MemoryStore) PurgeDeleted(ctx context.Context, cutoff time.Time, limit int) ([]models.Domain, error) {
+	var purged []models.Domain
+	err := s.apply(func(record recordFunc) error {
+		for _, d := range s.domains {
+			if d.domain.Deleted && d.domain.DeletedAt.Before(cutoff) {
+				purged = append(purged, d.domain)
+			}
+		}
+		sort.Slice(purged, func(i, j int) bool { return purged[i].DeletedAt.Before(*purged[j].DeletedAt) })
+		if len(purged) > limit {
+			purged = purged[:limit]
+		}
+
+		for i := range purged {
+			delete(s.domains, purged[i].ID)
+			if err := record(ctx, models.EventDomainPurged, &purged[i], nil); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return purged, nil
+}
+
+// Update updates a domain. Changing Archived archives the domain, or returns
+// it to the state it was archived in (suspended if its tenant now is).
+func (s *MemoryStore) Update(ctx context.Context, domain *models.Domain) error {
+	domain.UpdatedAt = time.Now().UTC()
+
+	_, err := s.mutate(ctx, domain.ID, models.EventDomainUpdated, func(d *memoryDomain, before *models.Domain) error {
+		if domain.Archived != before.Archived {
+			to := models.StatusArchived
+			if !domain.Archived {
+				to = before.ResumeTarget()
+				if s.suspended[before.TenantID] {
+					to = models.StatusSuspended
+				}
+			}
+			if err := memorySetStatus(&d.domain, to); err != nil {
+				return err
+			}
+		}
+
+		d.domain.RedirectURL = domain.RedirectURL
+		d.domain.UpdatedAt = domain.UpdatedAt
+		return nil
+	})
+	return err
+}
+
+// RecordVerificationFailed records a failed manual verification for a domain.
+// The check schedule is left alone.
+func (s *MemoryStore) RecordVerificationFailed(ctx context.Context, id, reason string) error {
+	return s.apply(func(record recordFunc) error {
+		d, ok := s.domains[id]
+		if !ok {
+			return ErrDomainNotFound
+		}
+
+		now := time.Now().UTC()
+		d.domain.LastCheckedAt = &now
+		d.domain.LastError = reason
+		domain := d.domain
+
+		return record(ctx, models.EventDomainVerificationFailed, &domain, &domain)
+	})
+}
+
+// ScheduleRetry records a failed scheduled check, returns the domain from
+// verifying to pending DNS and sets when it is next due. A domain that left
+// verification since it was claimed is left alone. Like
+// DomainRepository.ScheduleRetry it records no event.
+func (s *MemoryStore) ScheduleRetry(ctx context.Context, id, reason string, next time.Time) error {
+	return s.apply(func(recordFunc) error {
+		d, ok := s.domains[id]
+		if !ok || (d.domain.Status != models.StatusPendingDNS && d.domain.Status != models.StatusVerifying) {
+			return nil
+		}
+
+		if err := memorySetStatus(&d.domain, models.StatusPendingDNS); err != nil {
+			return err
+		}
+		now := time.Now().UTC()
+		d.domain.VerificationAttempts++
+		d.domain.LastCheckedAt = &now
+		d.domain.NextCheckAt = &next
+		d.domain.LastError = reason
+		d.claimedUntil = time.Time{}
+		return nil
+	})
+}
+
+// ExpireVerification gives up on verifying a domain. It is no longer
+// checked until it is verified manually or its verification is reset.
+func (s *MemoryStore) ExpireVerification(ctx context.Context, id, reason string) error {
+	_, err := s.mutate(ctx, id, models.EventDomainVerificationExpired, func(d *memoryDomain, before *models.Domain) error {
+		if !before.CanTransition(models.StatusVerificationExpired) {
+			return errNoChange
+		}
+		if err := memorySetStatus(&d.domain, models.StatusVerificationExpired); err != nil {
+			return err
+		}
+		now := time.Now().UTC()
+		d.domain.VerificationAttempts++
+		d.domain.LastCheckedAt = &now
+		d.domain.NextCheckAt = nil
+		d.domain.LastError = reason
+		d.claimedUntil = time.Time{}
+		return nil
+	})
+	return err
+}
+
+// RecordRecheckPassed records a successful re-verification of a verified
+// domain. A misconfigured domain recovers and a domain.recovered event is recorded.
+func (s *MemoryStore) RecordRecheckPassed(ctx context.Context, id string, next time.Time) error {
+	_, err := s.mutate(ctx, id, models.EventDomainRecovered, func(d *memoryDomain, before *models.Domain) error {
+		now := time.Now().UTC()
+		d.domain.RecheckFailures = 0
+		d.domain.MisconfiguredAt = nil
+		d.domain.LastError = ""
+		d.domain.LastCheckedAt = &now
+		d.domain.NextCheckAt = &next
+		d.claimedUntil = time.Time{}
+		if before.Status != models.StatusMisconfigured {
+			return errNoChange
+		}
+		return memorySetStatus(&d.domain, models.StatusActive)
+	})
+	return err
+}
+
+// RecordRecheckFailed records a failed re-verification of a verified domain.
+// Once failures reach threshold the domain becomes misconfigured and a
+// domain.misconfigured event is recorded. It returns the updated domain.
+func (s *MemoryStore) RecordRecheckFailed(ctx context.Context, id, reason string, next time.Time, threshold int) (*models.Domain, error) {
+	return s.mutate(ctx, id, models.EventDomainMisconfigured, func(d *memoryDomain, before *models.Domain) error {
+		now := time.Now().UTC()
+		misconfigured := before.Status != models.StatusMisconfigured && before.RecheckFailures+1 >= threshold
+		d.domain.RecheckFailures++
+		d.domain.LastError = reason
+		d.domain.LastCheckedAt = &now
+		d.domain.NextCheckAt = &next
+		d.claimedUntil = time.Time{}
+		if !misconfigured {
+			return errNoChange
+		}
+		d.domain.MisconfiguredAt = &now
+		return memorySetStatus(&d.domain, models.StatusMisconfigured)
+	})
+}
+
+// Reassign moves a domain to another tenant. The domain is no longer primary
+// for either tenant and takes on the new tenant's suspension state.
+// The event is recorded in both tenants' histories.
+func (s *MemoryStore) Reassign(ctx context.Context, id, tenantID string) error {
+	return s.apply(func(record recordFunc) error {
+		d, ok := s.domains[id]
+		if !ok {
+			return ErrDomainNotFound
+		}
+		before := d.domain
+
+		after := before
+		after.TenantID = tenantID
+		after.IsPrimary = false
+		after.UpdatedAt = time.Now().UTC()
+		if err := memoryHoldSuspended(&after, s.suspended[tenantID]); err != nil {
+			return err
+		}
+		d.domain = after
+
+		if err := record(ctx, models.EventDomainReassigned, &before, &after); err != nil {
+			return err
+		}
+		if before.TenantID != after.TenantID {
+			previous := after
+			previous.TenantID = before.TenantID
+			return record(ctx, models.EventDomainReassigned, &before, &previous)
+		}
+		return nil
+	})
+}
+
+// Search lists domains across all tenants, optionally filtered by tenant and
+// a substring of the domain name. It returns the page and the total match count.
+func (s *MemoryStore) Search(ctx context.Context, search, tenantID string, limit, offset int) ([]models.Domain, int, error) {
+	domains := s.filter(func(d *memoryDomain) bool {
+		return strings.Contains(d.domain.Domain, search) && (tenantID == "" || d.domain.TenantID == tenantID)
+	})
+	sortNewestFirst(domains)
+
+	total := len(domains)
+	if offset > total {
+		offset = total
+	}
+	domains = domains[offset:]
+	if limit >= 0 && limit < len(domains) {
+		domains = domains[:limit]
+	}
+	return domains, total, nil
+}
+
+// SetTenantSuspended suspends or unsuspends all domains of a tenant and
+// records the tenant-level suspension. Archived and deleted domains are left
+// alone. It returns the domains whose state changed.
+func (s *MemoryStore) SetTenantSuspended(ctx context.Context, tenantID string, suspended bool, reason string) ([]models.Domain, error) {
+	action := models.EventDomainUnsuspended
+	if suspended {
+		action = models.EventDomainSuspended
+	}
+
+	var domains []models.Domain
+	err := s.apply(func(record recordFunc) error {
+		s.suspended[tenantID] = suspended
+
+		for _, d := range s.domains {
+			before := d.domain
+			if before.TenantID != tenantID || before.Suspended == suspended || before.Archived || before.Deleted {
+				continue
+			}
+
+			after := before
+			if err := memoryHoldSuspended(&after, suspended); err != nil {
+				return err
+			}
+			d.domain = after
+
+			if err := record(ctx, action, &before, &after); err != nil {
+				return err
+			}
+			domains = append(domains, after)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return domains, nil
+}
+
+// SetPrimary sets a domain as primary for a tenant
+func (s *MemoryStore) SetPrimary(ctx context.Context, tenantID, domainID string) error {
+	return s.apply(func(record recordFunc) error {
+		for _, d := range s.domains {
+			if d.domain.TenantID != tenantID {
+				continue
+			}
+
+			before := d.domain
+			after := before
+			after.IsPrimary = before.ID == domainID
+			if after.IsPrimary == before.IsPrimary {
+				continue
+			}
+			d.domain = after
+
+			action := models.EventDomainPrimaryUnset
+			if after.IsPrimary {
+				action = models.EventDomainPrimarySet
+			}
+			if err := record(ctx, action, &before, &after); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// recordFunc records an event for a change made within apply
+type recordFunc func(ctx context.Context, action string, before, after *models.Domain) error
+
+// apply runs fn with the store locked, as DomainRepository runs a transaction.
+// If fn fails every change it made is rolled back; otherwise the events it
+// recorded are passed to the listeners once the lock is released.
+func (s *MemoryStore) apply(fn func(record recordFunc) error) error {
+	s.mu.Lock()
+
+	saved := make(map[string]memoryDomain, len(s.domains))
+	for id, d := range s.domains {
+		saved[id] = *d
+	}
+	savedSuspended := make(map[string]bool, len(s.suspended))
+	for tenantID, suspended := range s.suspended {
+		savedSuspended[tenantID] = suspended
+	}
+
+	var events []models.DomainEvent
+	err := fn(func(ctx context.Context, action string, before, after *models.Domain) error {
+		event, err := newDomainEvent(ctx, action, before, after)
+		if err != nil {
+			return err
+		}
+		events = append(events, event)
+		return nil
+	})
+	if err != nil {
+		s.domains = make(map[string]*memoryDomain, len(saved))
+		for id, d := range saved {
+			s.domains[id] = &d
+		}
+		s.suspended = savedSuspended
+		s.mu.Unlock()
+		return err
+	}
+
+	s.events = append(s.events, events...)
+	listeners := s.listeners
+	s.mu.Unlock()
+
+	for _, event := range events {
+		for _, fn := range listeners {
+			fn(event)
+		}
+	}
+	return nil
+}
+
+// mutate applies update to a domain and records the change as an event, like
+// DomainRepository.mutate. It returns the domain as it is after the update.
+// An update returning errNoChange keeps its writes but records no event.
+func (s *MemoryStore) mutate(ctx context.Context, id, action string, update func(d *memoryDomain, before *models.Domain) error) (*models.Domain, error) {
+	var after *models.Domain
+	err := s.apply(func(record recordFunc) error {
+		d, ok := s.domains[id]
+		if !ok {
+			return ErrDomainNotFound
+		}
+		before := d.domain
+
+		err := update(d, &before)
+		updated := d.domain
+		after = &updated
+		if errors.Is(err, errNoChange) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		return record(ctx, action, &before, after)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return after, nil
+}
+
+// claim leases up to limit unclaimed domains for which due holds, moving
+// them to status unless it is empty like DomainRepository.claim
+func (s *MemoryStore) claim(ctx context.Context, due func(d *models.Domain, now time.Time) bool, status models.DomainStatus, limit int, lease time.Duration) ([]models.Domain, error) {
+	var domains []models.Domain
+	err := s.apply(func(record recordFunc) error {
+		now := time.Now()
+		var candidates []*memoryDomain
+		for _, d := range s.domains {
+			if due(&d.domain, now) && d.claimedUntil.Before(now) {
+				candidates = append(candidates, d)
+			}
+		}
+		sort.Slice(candidates, func(i, j int) bool {
+			return candidates[i].domain.CreatedAt.Before(candidates[j].domain.CreatedAt)
+		})
+		if len(candidates) > limit {
+			candidates = candidates[:limit]
+		}
+
+		domains = make([]models.Domain, 0, len(candidates))
+		for _, d := range candidates {
+			before := d.domain
+			move := status != "" && before.Status != status
+			if move && !before.CanTransition(status) {
+				continue
+			}
+
+			d.claimedUntil = now.Add(lease)
+			if move {
+				if err := memorySetStatus(&d.domain, status); err != nil {
+					return err
+				}
+			}
+			if move && before.LastCheckedAt == nil {
+				after := d.domain
+				if err := record(ctx, models.EventDomainVerificationStarted, &before, &after); err != nil {
+					return err
+				}
+			}
+			domains = append(domains, d.domain)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return domains, nil
+}
+
+// GetTenant retrieves a tenant's settings, returning defaults if none are stored
+func (s *MemoryStore) GetTenant(ctx context.Context, tenantID string) (*models.TenantSettings, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	settings, ok := s.settings[tenantID]
+	if !ok {
+		settings = models.TenantSettings{TenantID: tenantID}
+	}
+	settings = s.tenantSettings(settings)
+	return &settings, nil
+}
+
+// ListTenants retrieves all stored tenant settings
+func (s *MemoryStore) ListTenants(ctx context.Context) ([]models.TenantSettings, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list := make([]models.TenantSettings, 0, len(s.settings))
+	for _, settings := range s.settings {
+		list = append(list, s.tenantSettings(settings))
+	}
+	// Suspending a tenant stores its settings, as SetTenantSuspended does in the database
+	for tenantID, suspended := range s.suspended {
+		if _, ok := s.settings[tenantID]; suspended && !ok {
+			list = append(list, models.TenantSettings{TenantID: tenantID, Suspended: true})
+		}
+	}
+	return list, nil
+}
+
+// UpsertTenant creates or replaces a tenant's settings. Like
+// SettingsRepository.UpsertTenant it leaves the suspension alone.
+func (s *MemoryStore) UpsertTenant(ctx context.Context, settings *models.TenantSettings) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	settings.UpdatedAt = time.Now().UTC()
+	stored := *settings
+	stored.ErrorPages = copyPages(settings.ErrorPages)
+	s.settings[settings.TenantID] = stored
+	return nil
+}
+
+// GetGlobal retrieves the gateway-wide settings
+func (s *MemoryStore) GetGlobal(ctx context.Context) (*models.GlobalSettings, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	global := s.global
+	return &global, nil
+}
+
+// UpsertGlobal replaces the gateway-wide settings
+func (s *MemoryStore) UpsertGlobal(ctx context.Context, settings *models.GlobalSettings) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	settings.UpdatedAt = time.Now().UTC()
+	s.global = *settings
+	return nil
+}
+
+// tenantSettings returns a copy of stored settings with the tenant's suspension
+func (s *MemoryStore) tenantSettings(settings models.TenantSettings) models.TenantSettings {
+	settings.ErrorPages = copyPages(settings.ErrorPages)
+	settings.Suspended = s.suspended[settings.TenantID]
+	return settings
+}
+
+// copyPages copies an error page map so callers cannot change stored settings
+func copyPages(pages map[string]string) map[string]string {
+	copied := make(map[string]string, len(pages))
+	for code, page := range pages {
+		copied[code] = page
+	}
+	return copied
+}
+
+// filter returns copies of the domains matching match
+func (s *MemoryStore) filter(match func(d *memoryDomain) bool) []models.Domain {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var domains []models.Domain
+	for _, d := range s.domains {
+		if match(d) {
+			domains = append(domains, d.domain)
+		}
+	}
+	return domains
+}
+
+// memorySetStatus moves a domain to status like setStatus
+func memorySetStatus(domain *models.Domain, to models.DomainStatus) error {
+	if domain.Status == to {
+		return nil
+	}
+	if !domain.CanTransition(to) {
+		return fmt.Errorf("%w: %s to %s", ErrInvalidTransition, domain.Status, to)
+	}
+
+	var resume models.DomainStatus
+	if to.IsHeld() {
+		resume = domain.Status
+		if domain.Status.IsHeld() {
+			resume = domain.ResumeStatus
+		}
+	}
+	domain.SetStatus(to, resume)
+	domain.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+// memoryHoldSuspended suspends or resumes a domain like holdSuspended
+func memoryHoldSuspended(domain *models.Domain, suspended bool) error {
+	switch {
+	case domain.Archived, domain.Deleted:
+		return nil
+	case suspended:
+		return memorySetStatus(domain, models.StatusSuspended)
+	case domain.Suspended:
+		return memorySetStatus(domain, domain.ResumeTarget())
+	}
+	return nil
+}
+
+// memoryMatches matches domainFilterCondition
+func memoryMatches(domain *models.Domain, filter models.DomainFilter) bool {
+	switch {
+	case domain.TenantID != filter.TenantID:
+		return false
+	case domain.Deleted != filter.Deleted:
+		return false
+	case filter.Type != "" && domain.Type != filter.Type:
+		return false
+	case filter.Status != "" && domain.Status != filter.Status:
+		return false
+	case filter.Verified != nil && memoryVerified(domain) != *filter.Verified:
+		return false
+	case filter.Primary != nil && domain.IsPrimary != *filter.Primary:
+		return false
+	case filter.Archived != nil && domain.Archived != *filter.Archived:
+		return false
+	}
+	return strings.Contains(domain.Domain, strings.ToLower(filter.Search))
+}
+
+// memoryVerified matches verifiedCondition
+func memoryVerified(domain *models.Domain) bool {
+	return domain.Status.IsVerified() ||
+		(domain.Status == models.StatusSuspended && domain.ResumeStatus.IsVerified())
+}
+
+// memoryPending matches pendingCondition
+func memoryPending(domain *models.Domain, now time.Time) bool {
+	return domain.Type == models.DomainTypeCustom &&
+		(domain.Status == models.StatusPendingDNS || domain.Status == models.StatusVerifying) &&
+		(domain.NextCheckAt == nil || !domain.NextCheckAt.After(now))
+}
+
+// memoryRecheckDue matches recheckCondition
+func memoryRecheckDue(domain *models.Domain, now time.Time) bool {
+	return domain.Type == models.DomainTypeCustom && domain.Status.IsVerified() &&
+		(domain.NextCheckAt == nil || !domain.NextCheckAt.After(now))
+}
+
+// sortNewestFirst orders domains by creation time, newest first
+func sortNewestFirst(domains []models.Domain) {
+	sort.Slice(domains, func(i, j int) bool { return domains[i].CreatedAt.After(domains[j].CreatedAt) })
+}
+
+// sortOldestFirst orders domains by creation time, oldest first
+func sortOldestFirst(domains []models.Domain) {
+	sort.Slice(domains, func(i, j int) bool { return domains[i].CreatedAt.Before(domains[j].CreatedAt) })
+}
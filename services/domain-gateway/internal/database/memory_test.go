@@ -0,0 +1,14 @@
+package database_test
+
+import (
+	"testing"
+
+	"github.com/panaroid/domain-gateway/internal/database"
+	"github.com/panaroid/domain-gateway/internal/database/storetest"
+)
+
+func TestMemoryStore(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) database.DomainStore {
+		return database.NewMemoryStore()
+	})
+}
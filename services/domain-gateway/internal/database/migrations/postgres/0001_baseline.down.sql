@@ -0,0 +1,11 @@
+DROP TABLE IF EXISTS change_outbox;
+DROP TABLE IF EXISTS certificate_checks;
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS outbox;
+DROP TABLE IF EXISTS webhook_endpoints;
+DROP TABLE IF EXISTS domain_events;
+DROP TABLE IF EXISTS access_audit;
+DROP TABLE IF EXISTS api_keys;
+DROP TABLE IF EXISTS global_settings;
+DROP TABLE IF EXISTS tenant_settings;
+DROP TABLE IF EXISTS domains;
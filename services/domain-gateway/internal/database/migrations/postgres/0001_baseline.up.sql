@@ -0,0 +1,221 @@
+-- Schema as built by the inline migrations that preceded versioning.
+-- Every statement is idempotent so existing databases adopt it as a no-op.
+
+CREATE TABLE IF NOT EXISTS domains (
+	id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+	tenant_id UUID NOT NULL,
+	domain VARCHAR(255) NOT NULL UNIQUE,
+	type VARCHAR(50) NOT NULL CHECK (type IN ('subdomain', 'custom')),
+	verified BOOLEAN DEFAULT FALSE,
+	verification_token VARCHAR(255),
+	is_primary BOOLEAN DEFAULT FALSE,
+	ssl_issued BOOLEAN DEFAULT FALSE,
+	created_at TIMESTAMPTZ DEFAULT NOW(),
+	updated_at TIMESTAMPTZ DEFAULT NOW(),
+	verified_at TIMESTAMPTZ,
+	CONSTRAINT unique_tenant_domain UNIQUE (tenant_id, domain)
+);
+
+CREATE INDEX IF NOT EXISTS idx_domains_tenant ON domains(tenant_id);
+
+CREATE INDEX IF NOT EXISTS idx_domains_verified ON domains(verified);
+
+CREATE INDEX IF NOT EXISTS idx_domains_type ON domains(type);
+
+CREATE TABLE IF NOT EXISTS tenant_settings (
+	tenant_id UUID PRIMARY KEY,
+	maintenance_mode BOOLEAN DEFAULT FALSE,
+	maintenance_page TEXT,
+	error_page_404 TEXT,
+	error_page_502 TEXT,
+	error_page_503 TEXT,
+	updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+ALTER TABLE domains ADD COLUMN IF NOT EXISTS suspended BOOLEAN DEFAULT FALSE;
+
+ALTER TABLE tenant_settings ADD COLUMN IF NOT EXISTS suspended BOOLEAN DEFAULT FALSE;
+
+ALTER TABLE tenant_settings ADD COLUMN IF NOT EXISTS suspended_at TIMESTAMPTZ;
+
+ALTER TABLE tenant_settings ADD COLUMN IF NOT EXISTS suspension_reason TEXT;
+
+CREATE TABLE IF NOT EXISTS global_settings (
+	id INTEGER PRIMARY KEY CHECK (id = 1),
+	maintenance_mode BOOLEAN DEFAULT FALSE,
+	maintenance_page TEXT,
+	updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS api_keys (
+	id UUID PRIMARY KEY,
+	tenant_id UUID NOT NULL,
+	name VARCHAR(255) NOT NULL,
+	prefix VARCHAR(32) NOT NULL UNIQUE,
+	key_hash VARCHAR(64) NOT NULL,
+	scopes TEXT NOT NULL DEFAULT '',
+	created_by VARCHAR(255),
+	expires_at TIMESTAMPTZ,
+	last_used_at TIMESTAMPTZ,
+	revoked_at TIMESTAMPTZ,
+	created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_keys_tenant ON api_keys(tenant_id);
+
+CREATE TABLE IF NOT EXISTS access_audit (
+	id UUID PRIMARY KEY,
+	user_id VARCHAR(255) NOT NULL,
+	role VARCHAR(50) NOT NULL,
+	home_tenant_id VARCHAR(255),
+	tenant_id UUID NOT NULL,
+	method VARCHAR(10) NOT NULL,
+	path TEXT NOT NULL,
+	status INTEGER NOT NULL,
+	remote_addr VARCHAR(255),
+	created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_access_audit_tenant ON access_audit(tenant_id, created_at);
+
+CREATE INDEX IF NOT EXISTS idx_access_audit_user ON access_audit(user_id, created_at);
+
+CREATE TABLE IF NOT EXISTS domain_events (
+	id UUID PRIMARY KEY,
+	domain_id UUID NOT NULL,
+	tenant_id UUID NOT NULL,
+	domain VARCHAR(255) NOT NULL,
+	action VARCHAR(50) NOT NULL,
+	actor_type VARCHAR(20) NOT NULL,
+	actor_id VARCHAR(255),
+	request_id VARCHAR(64),
+	before_state TEXT,
+	after_state TEXT,
+	created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_domain_events_domain ON domain_events(domain_id, created_at);
+
+CREATE INDEX IF NOT EXISTS idx_domain_events_tenant ON domain_events(tenant_id, created_at);
+
+CREATE TABLE IF NOT EXISTS webhook_endpoints (
+	id UUID PRIMARY KEY,
+	tenant_id UUID NOT NULL,
+	url TEXT NOT NULL,
+	secret VARCHAR(128) NOT NULL,
+	events TEXT NOT NULL DEFAULT '',
+	active BOOLEAN DEFAULT TRUE,
+	created_at TIMESTAMPTZ DEFAULT NOW(),
+	updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_endpoints_tenant ON webhook_endpoints(tenant_id);
+
+CREATE TABLE IF NOT EXISTS outbox (
+	id UUID PRIMARY KEY,
+	tenant_id UUID NOT NULL,
+	event_type VARCHAR(50) NOT NULL,
+	payload TEXT NOT NULL,
+	created_at TIMESTAMPTZ DEFAULT NOW(),
+	dispatched_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_pending ON outbox(created_at) WHERE dispatched_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+	id UUID PRIMARY KEY,
+	endpoint_id UUID NOT NULL REFERENCES webhook_endpoints(id) ON DELETE CASCADE,
+	tenant_id UUID NOT NULL,
+	event_id UUID NOT NULL,
+	event_type VARCHAR(50) NOT NULL,
+	payload TEXT NOT NULL,
+	status VARCHAR(20) NOT NULL DEFAULT 'pending',
+	attempts INTEGER NOT NULL DEFAULT 0,
+	next_attempt_at TIMESTAMPTZ,
+	last_status_code INTEGER,
+	last_error TEXT,
+	replay_of UUID,
+	created_at TIMESTAMPTZ DEFAULT NOW(),
+	delivered_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due ON webhook_deliveries(next_attempt_at) WHERE status = 'pending';
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_endpoint ON webhook_deliveries(endpoint_id, created_at);
+
+CREATE TABLE IF NOT EXISTS certificate_checks (
+	domain_id UUID PRIMARY KEY,
+	expires_at TIMESTAMPTZ,
+	checked_at TIMESTAMPTZ NOT NULL,
+	notified_expires_at TIMESTAMPTZ
+);
+
+CREATE TABLE IF NOT EXISTS change_outbox (
+	seq BIGSERIAL PRIMARY KEY,
+	kind VARCHAR(20) NOT NULL,
+	tenant_id VARCHAR(255),
+	domain_id VARCHAR(255),
+	event TEXT,
+	instance_id VARCHAR(64) NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT clock_timestamp()
+);
+
+CREATE INDEX IF NOT EXISTS idx_change_outbox_created ON change_outbox(created_at);
+
+ALTER TABLE domains ADD COLUMN IF NOT EXISTS claimed_by VARCHAR(64);
+
+ALTER TABLE domains ADD COLUMN IF NOT EXISTS claimed_until TIMESTAMPTZ;
+
+ALTER TABLE domains ADD COLUMN IF NOT EXISTS verification_attempts INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE domains ADD COLUMN IF NOT EXISTS last_checked_at TIMESTAMPTZ;
+
+ALTER TABLE domains ADD COLUMN IF NOT EXISTS next_check_at TIMESTAMPTZ;
+
+ALTER TABLE domains ADD COLUMN IF NOT EXISTS last_error TEXT;
+
+ALTER TABLE domains ADD COLUMN IF NOT EXISTS verification_expired BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX IF NOT EXISTS idx_domains_next_check ON domains(next_check_at) WHERE verified = FALSE AND type = 'custom';
+
+ALTER TABLE domains ADD COLUMN IF NOT EXISTS pending_since TIMESTAMPTZ;
+
+ALTER TABLE domains ADD COLUMN IF NOT EXISTS recheck_failures INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE domains ADD COLUMN IF NOT EXISTS misconfigured_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_domains_recheck ON domains(next_check_at) WHERE verified = TRUE AND type = 'custom';
+
+ALTER TABLE domains ADD COLUMN IF NOT EXISTS status VARCHAR(32);
+
+ALTER TABLE domains ADD COLUMN IF NOT EXISTS resume_status VARCHAR(32);
+
+-- Backfill status from the legacy flags once; the flags are no longer written
+UPDATE domains SET
+	status = CASE WHEN suspended THEN 'suspended' ELSE
+		CASE
+			WHEN verified AND misconfigured_at IS NOT NULL THEN 'misconfigured'
+			WHEN verified AND ssl_issued THEN 'active'
+			WHEN verified THEN 'cert_pending'
+			WHEN verification_expired THEN 'verification_expired'
+			ELSE 'pending_dns'
+		END
+	END,
+	resume_status = CASE WHEN suspended THEN
+		CASE
+			WHEN verified AND misconfigured_at IS NOT NULL THEN 'misconfigured'
+			WHEN verified AND ssl_issued THEN 'active'
+			WHEN verified THEN 'cert_pending'
+			WHEN verification_expired THEN 'verification_expired'
+			ELSE 'pending_dns'
+		END
+	END
+WHERE status IS NULL;
+
+ALTER TABLE domains ALTER COLUMN status SET DEFAULT 'pending_dns';
+
+ALTER TABLE domains ALTER COLUMN status SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_domains_status ON domains(status);
+
+CREATE INDEX IF NOT EXISTS idx_domains_status_next_check ON domains(status, next_check_at) WHERE type = 'custom';
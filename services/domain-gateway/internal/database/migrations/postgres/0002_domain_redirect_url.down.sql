@@ -0,0 +1 @@
+ALTER TABLE domains DROP COLUMN IF EXISTS redirect_url;
@@ -0,0 +1,3 @@
+-- Domain.RedirectURL had no column, so Update failed on every database.
+-- Archiving needs no column: it is status = 'archived'.
+ALTER TABLE domains ADD COLUMN IF NOT EXISTS redirect_url TEXT;
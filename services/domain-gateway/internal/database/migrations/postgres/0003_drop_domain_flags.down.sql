@@ -0,0 +1,15 @@
+ALTER TABLE domains
+	ADD COLUMN IF NOT EXISTS verified BOOLEAN DEFAULT FALSE,
+	ADD COLUMN IF NOT EXISTS ssl_issued BOOLEAN DEFAULT FALSE,
+	ADD COLUMN IF NOT EXISTS suspended BOOLEAN DEFAULT FALSE,
+	ADD COLUMN IF NOT EXISTS verification_expired BOOLEAN NOT NULL DEFAULT FALSE;
+
+UPDATE domains SET
+	verified = COALESCE(resume_status, status) IN ('cert_pending', 'cert_failed', 'active', 'misconfigured'),
+	ssl_issued = COALESCE(resume_status, status) IN ('active', 'misconfigured'),
+	suspended = status = 'suspended',
+	verification_expired = COALESCE(resume_status, status) = 'verification_expired';
+
+CREATE INDEX IF NOT EXISTS idx_domains_verified ON domains(verified);
+CREATE INDEX IF NOT EXISTS idx_domains_next_check ON domains(next_check_at) WHERE verified = FALSE AND type = 'custom';
+CREATE INDEX IF NOT EXISTS idx_domains_recheck ON domains(next_check_at) WHERE verified = TRUE AND type = 'custom';
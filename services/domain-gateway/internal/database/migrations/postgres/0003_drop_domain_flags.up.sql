@@ -0,0 +1,10 @@
+-- The lifecycle lives in status; the flags it replaced are no longer written
+DROP INDEX IF EXISTS idx_domains_verified;
+DROP INDEX IF EXISTS idx_domains_next_check;
+DROP INDEX IF EXISTS idx_domains_recheck;
+
+ALTER TABLE domains
+	DROP COLUMN IF EXISTS verified,
+	DROP COLUMN IF EXISTS ssl_issued,
+	DROP COLUMN IF EXISTS suspended,
+	DROP COLUMN IF EXISTS verification_expired;
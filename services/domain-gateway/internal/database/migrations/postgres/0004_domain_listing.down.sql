@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_domains_domain_trgm;
+
+CREATE INDEX IF NOT EXISTS idx_domains_tenant ON domains(tenant_id);
+
+DROP INDEX IF EXISTS idx_domains_tenant_created;
@@ -0,0 +1,20 @@
+-- Keyset pagination of a tenant's domains walks (created_at, id) newest first
+CREATE INDEX IF NOT EXISTS idx_domains_tenant_created ON domains(tenant_id, created_at DESC, id DESC);
+
+DROP INDEX IF EXISTS idx_domains_tenant;
+
+-- Substring search on the domain name. pg_trgm needs extension-create rights,
+-- which managed databases often withhold; without it the index is skipped and
+-- search falls back to LIKE over the tenant's rows.
+DO $$
+BEGIN
+	BEGIN
+		CREATE EXTENSION IF NOT EXISTS pg_trgm;
+	EXCEPTION WHEN OTHERS THEN
+		RAISE NOTICE 'pg_trgm unavailable, skipping trigram index: %', SQLERRM;
+	END;
+
+	IF EXISTS (SELECT 1 FROM pg_extension WHERE extname = 'pg_trgm') THEN
+		CREATE INDEX IF NOT EXISTS idx_domains_domain_trgm ON domains USING gin (domain gin_trgm_ops);
+	END IF;
+END $$;
@@ -0,0 +1,6 @@
+-- Without the trash, deleted domains are gone for good
+DELETE FROM domains WHERE status = 'deleted';
+
+DROP INDEX IF EXISTS idx_domains_deleted;
+
+ALTER TABLE domains DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,4 @@
+-- Deleted domains stay in the trash, as status = 'deleted', until purged
+ALTER TABLE domains ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_domains_deleted ON domains(deleted_at) WHERE status = 'deleted';
@@ -0,0 +1,21 @@
+-- Transfers of a domain between tenants; a purged domain takes its transfers with it
+CREATE TABLE IF NOT EXISTS domain_transfers (
+	id UUID PRIMARY KEY,
+	domain_id UUID NOT NULL REFERENCES domains(id) ON DELETE CASCADE,
+	domain VARCHAR(255) NOT NULL,
+	from_tenant_id UUID NOT NULL,
+	to_tenant_id UUID NOT NULL,
+	status VARCHAR(20) NOT NULL DEFAULT 'pending',
+	challenge_token VARCHAR(255),
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	expires_at TIMESTAMPTZ NOT NULL,
+	accepted_at TIMESTAMPTZ,
+	completed_at TIMESTAMPTZ,
+	cancelled_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_domain_transfers_domain ON domain_transfers(domain_id, status);
+
+CREATE INDEX IF NOT EXISTS idx_domain_transfers_from ON domain_transfers(from_tenant_id, created_at);
+
+CREATE INDEX IF NOT EXISTS idx_domain_transfers_to ON domain_transfers(to_tenant_id, created_at);
@@ -0,0 +1,20 @@
+-- Contests of another tenant's unverified claim on a hostname. The domain is
+-- kept by name, as a won contest replaces the contested row.
+CREATE TABLE IF NOT EXISTS domain_contests (
+	id UUID PRIMARY KEY,
+	domain VARCHAR(255) NOT NULL,
+	tenant_id UUID NOT NULL,
+	holder_tenant_id UUID NOT NULL,
+	domain_id UUID,
+	status VARCHAR(20) NOT NULL DEFAULT 'pending',
+	challenge_token VARCHAR(255) NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	expires_at TIMESTAMPTZ NOT NULL,
+	resolved_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_domain_contests_domain ON domain_contests(domain, status);
+
+CREATE INDEX IF NOT EXISTS idx_domain_contests_tenant ON domain_contests(tenant_id, created_at);
+
+CREATE INDEX IF NOT EXISTS idx_domain_contests_holder ON domain_contests(holder_tenant_id, created_at);
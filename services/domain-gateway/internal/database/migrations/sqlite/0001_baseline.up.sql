@@ -0,0 +1,158 @@
+-- The schema the Postgres migrations arrive at, for SQLite. UUIDs are TEXT,
+-- booleans are INTEGER and times are TIMESTAMP text in UTC.
+
+CREATE TABLE domains (
+	id TEXT PRIMARY KEY,
+	tenant_id TEXT NOT NULL,
+	domain VARCHAR(255) NOT NULL UNIQUE,
+	type VARCHAR(50) NOT NULL CHECK (type IN ('subdomain', 'custom')),
+	status VARCHAR(32) NOT NULL DEFAULT 'pending_dns',
+	resume_status VARCHAR(32),
+	verification_token VARCHAR(255),
+	is_primary BOOLEAN NOT NULL DEFAULT FALSE,
+	created_at TIMESTAMP NOT NULL,
+	updated_at TIMESTAMP NOT NULL,
+	verified_at TIMESTAMP,
+	claimed_by VARCHAR(64),
+	claimed_until TIMESTAMP,
+	verification_attempts INTEGER NOT NULL DEFAULT 0,
+	last_checked_at TIMESTAMP,
+	next_check_at TIMESTAMP,
+	last_error TEXT,
+	pending_since TIMESTAMP,
+	recheck_failures INTEGER NOT NULL DEFAULT 0,
+	misconfigured_at TIMESTAMP,
+	redirect_url TEXT,
+	CONSTRAINT unique_tenant_domain UNIQUE (tenant_id, domain)
+);
+
+CREATE INDEX idx_domains_tenant ON domains(tenant_id);
+
+CREATE INDEX idx_domains_type ON domains(type);
+
+CREATE INDEX idx_domains_status ON domains(status);
+
+CREATE INDEX idx_domains_status_next_check ON domains(status, next_check_at) WHERE type = 'custom';
+
+CREATE TABLE tenant_settings (
+	tenant_id TEXT PRIMARY KEY,
+	maintenance_mode BOOLEAN DEFAULT FALSE,
+	maintenance_page TEXT,
+	error_page_404 TEXT,
+	error_page_502 TEXT,
+	error_page_503 TEXT,
+	suspended BOOLEAN DEFAULT FALSE,
+	suspended_at TIMESTAMP,
+	suspension_reason TEXT,
+	updated_at TIMESTAMP
+);
+
+CREATE TABLE global_settings (
+	id INTEGER PRIMARY KEY CHECK (id = 1),
+	maintenance_mode BOOLEAN DEFAULT FALSE,
+	maintenance_page TEXT,
+	updated_at TIMESTAMP
+);
+
+CREATE TABLE api_keys (
+	id TEXT PRIMARY KEY,
+	tenant_id TEXT NOT NULL,
+	name VARCHAR(255) NOT NULL,
+	prefix VARCHAR(32) NOT NULL UNIQUE,
+	key_hash VARCHAR(64) NOT NULL,
+	scopes TEXT NOT NULL DEFAULT '',
+	created_by VARCHAR(255),
+	expires_at TIMESTAMP,
+	last_used_at TIMESTAMP,
+	revoked_at TIMESTAMP,
+	created_at TIMESTAMP
+);
+
+CREATE INDEX idx_api_keys_tenant ON api_keys(tenant_id);
+
+CREATE TABLE access_audit (
+	id TEXT PRIMARY KEY,
+	user_id VARCHAR(255) NOT NULL,
+	role VARCHAR(50) NOT NULL,
+	home_tenant_id VARCHAR(255),
+	tenant_id TEXT NOT NULL,
+	method VARCHAR(10) NOT NULL,
+	path TEXT NOT NULL,
+	status INTEGER NOT NULL,
+	remote_addr VARCHAR(255),
+	created_at TIMESTAMP
+);
+
+CREATE INDEX idx_access_audit_tenant ON access_audit(tenant_id, created_at);
+
+CREATE INDEX idx_access_audit_user ON access_audit(user_id, created_at);
+
+CREATE TABLE domain_events (
+	id TEXT PRIMARY KEY,
+	domain_id TEXT NOT NULL,
+	tenant_id TEXT NOT NULL,
+	domain VARCHAR(255) NOT NULL,
+	action VARCHAR(50) NOT NULL,
+	actor_type VARCHAR(20) NOT NULL,
+	actor_id VARCHAR(255),
+	request_id VARCHAR(64),
+	before_state TEXT,
+	after_state TEXT,
+	created_at TIMESTAMP
+);
+
+CREATE INDEX idx_domain_events_domain ON domain_events(domain_id, created_at);
+
+CREATE INDEX idx_domain_events_tenant ON domain_events(tenant_id, created_at);
+
+CREATE TABLE webhook_endpoints (
+	id TEXT PRIMARY KEY,
+	tenant_id TEXT NOT NULL,
+	url TEXT NOT NULL,
+	secret VARCHAR(128) NOT NULL,
+	events TEXT NOT NULL DEFAULT '',
+	active BOOLEAN DEFAULT TRUE,
+	created_at TIMESTAMP,
+	updated_at TIMESTAMP
+);
+
+CREATE INDEX idx_webhook_endpoints_tenant ON webhook_endpoints(tenant_id);
+
+CREATE TABLE outbox (
+	id TEXT PRIMARY KEY,
+	tenant_id TEXT NOT NULL,
+	event_type VARCHAR(50) NOT NULL,
+	payload TEXT NOT NULL,
+	created_at TIMESTAMP,
+	dispatched_at TIMESTAMP
+);
+
+CREATE INDEX idx_outbox_pending ON outbox(created_at) WHERE dispatched_at IS NULL;
+
+CREATE TABLE webhook_deliveries (
+	id TEXT PRIMARY KEY,
+	endpoint_id TEXT NOT NULL REFERENCES webhook_endpoints(id) ON DELETE CASCADE,
+	tenant_id TEXT NOT NULL,
+	event_id TEXT NOT NULL,
+	event_type VARCHAR(50) NOT NULL,
+	payload TEXT NOT NULL,
+	status VARCHAR(20) NOT NULL DEFAULT 'pending',
+	attempts INTEGER NOT NULL DEFAULT 0,
+	next_attempt_at TIMESTAMP,
+	last_status_code INTEGER,
+	last_error TEXT,
+	replay_of TEXT,
+	created_at TIMESTAMP,
+	delivered_at TIMESTAMP
+);
+
+CREATE INDEX idx_webhook_deliveries_due ON webhook_deliveries(next_attempt_at) WHERE status = 'pending';
+
+CREATE INDEX idx_webhook_deliveries_endpoint ON webhook_deliveries(endpoint_id, created_at);
+
+CREATE TABLE certificate_checks (
+	domain_id TEXT PRIMARY KEY,
+	expires_at TIMESTAMP,
+	checked_at TIMESTAMP NOT NULL,
+	notified_expires_at TIMESTAMP
+);
@@ -0,0 +1,3 @@
+CREATE INDEX idx_domains_tenant ON domains(tenant_id);
+
+DROP INDEX idx_domains_tenant_created;
@@ -0,0 +1,5 @@
+-- Keyset pagination of a tenant's domains walks (created_at, id) newest first.
+-- Substring search filters within the tenant's rows; SQLite has no index for it.
+CREATE INDEX idx_domains_tenant_created ON domains(tenant_id, created_at DESC, id DESC);
+
+DROP INDEX idx_domains_tenant;
@@ -0,0 +1,21 @@
+-- Transfers of a domain between tenants; a purged domain takes its transfers with it
+CREATE TABLE domain_transfers (
+	id TEXT PRIMARY KEY,
+	domain_id TEXT NOT NULL REFERENCES domains(id) ON DELETE CASCADE,
+	domain VARCHAR(255) NOT NULL,
+	from_tenant_id TEXT NOT NULL,
+	to_tenant_id TEXT NOT NULL,
+	status VARCHAR(20) NOT NULL DEFAULT 'pending',
+	challenge_token VARCHAR(255),
+	created_at TIMESTAMP NOT NULL,
+	expires_at TIMESTAMP NOT NULL,
+	accepted_at TIMESTAMP,
+	completed_at TIMESTAMP,
+	cancelled_at TIMESTAMP
+);
+
+CREATE INDEX idx_domain_transfers_domain ON domain_transfers(domain_id, status);
+
+CREATE INDEX idx_domain_transfers_from ON domain_transfers(from_tenant_id, created_at);
+
+CREATE INDEX idx_domain_transfers_to ON domain_transfers(to_tenant_id, created_at);
@@ -0,0 +1 @@
+DROP TABLE domain_contests;
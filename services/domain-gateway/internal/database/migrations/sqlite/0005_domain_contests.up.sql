@@ -0,0 +1,20 @@
+-- Contests of another tenant's unverified claim on a hostname. The domain is
+-- kept by name, as a won contest replaces the contested row.
+CREATE TABLE domain_contests (
+	id TEXT PRIMARY KEY,
+	domain VARCHAR(255) NOT NULL,
+	tenant_id TEXT NOT NULL,
+	holder_tenant_id TEXT NOT NULL,
+	domain_id TEXT,
+	status VARCHAR(20) NOT NULL DEFAULT 'pending',
+	challenge_token VARCHAR(255) NOT NULL,
+	created_at TIMESTAMP NOT NULL,
+	expires_at TIMESTAMP NOT NULL,
+	resolved_at TIMESTAMP
+);
+
+CREATE INDEX idx_domain_contests_domain ON domain_contests(domain, status);
+
+CREATE INDEX idx_domain_contests_tenant ON domain_contests(tenant_id, created_at);
+
+CREATE INDEX idx_domain_contests_holder ON domain_contests(holder_tenant_id, created_at);
@@ -0,0 +1,199 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/panaroid/domain-gateway/pkg/models"
+)
+
+// SettingsRepository handles tenant and global settings database operations
+type SettingsRepository struct {
+	db *DB
+}
+
+// NewSettingsRepository creates a new settings repository
+func NewSettingsRepository(db *DB) *SettingsRepository {
+	return &SettingsRepository{db: db}
+}
+
+// GetTenant retrieves a tenant's settings, returning defaults if none are stored
+func (r *SettingsRepository) GetTenant(ctx context.Context, tenantID string) (*models.TenantSettings, error) {
+	query := `
+		SELECT tenant_id, maintenance_mode, maintenance_page, error_page_404, error_page_502, error_page_503, suspended, suspended_at, suspension_reason, updated_at
+		FROM tenant_settings
+		WHERE tenant_id = $1
+	`
+
+	settings, err := scanTenantSettings(r.db.QueryRowContext(ctx, query, tenantID))
+	if err == sql.ErrNoRows {
+		return &models.TenantSettings{TenantID: tenantID}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tenant settings: %w", err)
+	}
+
+	return settings, nil
+}
+
+// ListTenants retrieves all stored tenant settings
+func (r *SettingsRepository) ListTenants(ctx context.Context) ([]models.TenantSettings, error) {
+	query := `
+		SELECT tenant_id, maintenance_mode, maintenance_page, error_page_404, error_page_502, error_page_503, suspended, suspended_at, suspension_reason, updated_at
+		FROM tenant_settings
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tenant settings: %w", err)
+	}
+	defer rows.Close()
+
+	var settings []models.TenantSettings
+	for rows.Next() {
+		s, err := scanTenantSettings(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan tenant settings: %w", err)
+		}
+		settings = append(settings, *s)
+	}
+
+	return settings, rows.Err()
+}
+
+// UpsertTenant creates or replaces a tenant's settings
+func (r *SettingsRepository) UpsertTenant(ctx context.Context, settings *models.TenantSettings) error {
+	settings.UpdatedAt = time.Now().UTC()
+
+	query := `
+		INSERT INTO tenant_settings (tenant_id, maintenance_mode, maintenance_page, error_page_404, error_page_502, error_page_503, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (tenant_id) DO UPDATE SET
+			maintenance_mode = EXCLUDED.maintenance_mode,
+			maintenance_page = EXCLUDED.maintenance_page,
+			error_page_404 = EXCLUDED.error_page_404,
+			error_page_502 = EXCLUDED.error_page_502,
+			error_page_503 = EXCLUDED.error_page_503,
+			updated_at = EXCLUDED.updated_at
+	`
+
+	return r.db.WithTx(ctx, func(tx *Tx) error {
+		_, err := tx.ExecContext(ctx, query,
+			settings.TenantID,
+			settings.MaintenanceMode,
+			nullString(settings.MaintenancePage),
+			nullString(settings.ErrorPages["404"]),
+			nullString(settings.ErrorPages["502"]),
+			nullString(settings.ErrorPages["503"]),
+			settings.UpdatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to save tenant settings: %w", err)
+		}
+
+		return r.db.recordChange(ctx, tx, ChangeTenant, settings.TenantID, "", nil)
+	})
+}
+
+// GetGlobal retrieves the gateway-wide settings, returning defaults if none are stored
+func (r *SettingsRepository) GetGlobal(ctx context.Context) (*models.GlobalSettings, error) {
+	query := `
+		SELECT maintenance_mode, maintenance_page, updated_at
+		FROM global_settings
+		WHERE id = 1
+	`
+
+	settings := &models.GlobalSettings{}
+	var page sql.NullString
+
+	err := r.db.QueryRowContext(ctx, query).Scan(
+		&settings.MaintenanceMode,
+		&page,
+		&settings.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return settings, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get global settings: %w", err)
+	}
+
+	settings.MaintenancePage = page.String
+	return settings, nil
+}
+
+// UpsertGlobal creates or replaces the gateway-wide settings
+func (r *SettingsRepository) UpsertGlobal(ctx context.Context, settings *models.GlobalSettings) error {
+	settings.UpdatedAt = time.Now().UTC()
+
+	query := `
+		INSERT INTO global_settings (id, maintenance_mode, maintenance_page, updated_at)
+		VALUES (1, $1, $2, $3)
+		ON CONFLICT (id) DO UPDATE SET
+			maintenance_mode = EXCLUDED.maintenance_mode,
+			maintenance_page = EXCLUDED.maintenance_page,
+			updated_at = EXCLUDED.updated_at
+	`
+
+	return r.db.WithTx(ctx, func(tx *Tx) error {
+		_, err := tx.ExecContext(ctx, query,
+			settings.MaintenanceMode,
+			nullString(settings.MaintenancePage),
+			settings.UpdatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to save global settings: %w", err)
+		}
+
+		return r.db.recordChange(ctx, tx, ChangeGlobal, "", "", nil)
+	})
+}
+
+// rowScanner is satisfied by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanTenantSettings(row rowScanner) (*models.TenantSettings, error) {
+	settings := &models.TenantSettings{}
+	var maintenancePage, page404, page502, page503, suspensionReason sql.NullString
+	var suspended sql.NullBool
+	var suspendedAt sql.NullTime
+
+	if err := row.Scan(
+		&settings.TenantID,
+		&settings.MaintenanceMode,
+		&maintenancePage,
+		&page404,
+		&page502,
+		&page503,
+		&suspended,
+		&suspendedAt,
+		&suspensionReason,
+		&settings.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	settings.MaintenancePage = maintenancePage.String
+	settings.Suspended = suspended.Bool
+	settings.SuspensionReason = suspensionReason.String
+	if suspendedAt.Valid {
+		settings.SuspendedAt = &suspendedAt.Time
+	}
+	settings.ErrorPages = make(map[string]string)
+	for code, page := range map[string]sql.NullString{"404": page404, "502": page502, "503": page503} {
+		if page.Valid && page.String != "" {
+			settings.ErrorPages[code] = page.String
+		}
+	}
+
+	return settings, nil
+}
+
+// nullString converts an empty string to SQL NULL
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
@@ -0,0 +1,67 @@
+package database_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/panaroid/domain-gateway/internal/database"
+	"github.com/panaroid/domain-gateway/pkg/models"
+)
+
+func TestSettingsStore(t *testing.T) {
+	db := openDB(t, "sqlite://"+filepath.Join(t.TempDir(), "gateway.db"))
+	t.Run("sqlite", func(t *testing.T) {
+		testSettingsStore(t, database.NewSettingsRepository(db), database.NewDomainRepository(db))
+	})
+
+	memory := database.NewMemoryStore()
+	t.Run("memory", func(t *testing.T) {
+		testSettingsStore(t, memory, memory)
+	})
+}
+
+// testSettingsStore checks the behaviour both settings stores share. Suspension
+// goes through the domain store and shows in the tenant's settings.
+func testSettingsStore(t *testing.T, settings database.SettingsStore, domains database.DomainStore) {
+	ctx := context.Background()
+	tenantID := uuid.New().String()
+
+	defaults, err := settings.GetTenant(ctx, tenantID)
+	if err != nil || defaults.TenantID != tenantID || defaults.MaintenanceMode || defaults.Suspended {
+		t.Fatalf("GetTenant = %+v, %v; want defaults", defaults, err)
+	}
+
+	saved := &models.TenantSettings{TenantID: tenantID, MaintenanceMode: true, ErrorPages: map[string]string{"404": "gone"}}
+	if err := settings.UpsertTenant(ctx, saved); err != nil {
+		t.Fatalf("UpsertTenant: %v", err)
+	}
+	if _, err := domains.SetTenantSuspended(ctx, tenantID, true, "unpaid"); err != nil {
+		t.Fatalf("SetTenantSuspended: %v", err)
+	}
+
+	got, err := settings.GetTenant(ctx, tenantID)
+	if err != nil || !got.MaintenanceMode || got.ErrorPages["404"] != "gone" || !got.Suspended {
+		t.Errorf("GetTenant = %+v, %v; want the saved settings, suspended", got, err)
+	}
+
+	// Saving settings keeps the suspension
+	got.MaintenanceMode = false
+	if err := settings.UpsertTenant(ctx, got); err != nil {
+		t.Fatalf("UpsertTenant: %v", err)
+	}
+	list, err := settings.ListTenants(ctx)
+	if err != nil || len(list) != 1 || list[0].MaintenanceMode || !list[0].Suspended {
+		t.Errorf("ListTenants = %+v, %v; want the tenant, suspended", list, err)
+	}
+
+	if err := settings.UpsertGlobal(ctx, &models.GlobalSettings{MaintenanceMode: true, MaintenancePage: "soon"}); err != nil {
+		t.Fatalf("UpsertGlobal: %v", err)
+	}
+	global, err := settings.GetGlobal(ctx)
+	if err != nil || !global.MaintenanceMode || global.MaintenancePage != "soon" {
+		t.Errorf("GetGlobal = %+v, %v; want the saved settings", global, err)
+	}
+}
@@ -0,0 +1,88 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/panaroid/domain-gateway/pkg/models"
+)
+
+// ErrDomainExists is returned when creating a domain whose name is taken
+var ErrDomainExists = errors.New("domain already exists")
+
+// DomainStore is the domain persistence used by the API, the workers and the
+// resolver. DomainRepository implements it over the database and MemoryStore
+// in memory; storetest checks that both behave the same.
+type DomainStore interface {
+	Create(ctx context.Context, domain *models.Domain) error
+	GetByID(ctx context.Context, id string) (*models.Domain, error)
+	GetByDomain(ctx context.Context, domainName string) (*models.Domain, error)
+	GetPrimaryByTenant(ctx context.Context, tenantID string) (*models.Domain, error)
+	ListByTenant(ctx context.Context, tenantID string) ([]models.Domain, error)
+	List(ctx context.Context, filter models.DomainFilter) (*DomainPage, error)
+	GetPendingVerification(ctx context.Context) ([]models.Domain, error)
+	ClaimPendingVerification(ctx context.Context, limit int, lease time.Duration) ([]models.Domain, error)
+	ClaimDueRecheck(ctx context.Context, limit int, lease time.Duration) ([]models.Domain, error)
+	GetAllVerified(ctx context.Context) ([]models.Domain, error)
+	MarkVerified(ctx context.Context, id string) error
+	MarkUnverified(ctx context.Context, id string) error
+	MarkSSLIssued(ctx context.Context, id string) error
+	MarkCertFailed(ctx context.Context, id, reason string) error
+	Delete(ctx context.Context, id string) error
+	Restore(ctx context.Context, id string) (*models.Domain, error)
+	PurgeDeleted(ctx context.Context, cutoff time.Time, limit int) ([]models.Domain, error)
+	Update(ctx context.Context, domain *models.Domain) error
+	RecordVerificationFailed(ctx context.Context, id, reason string) error
+	ScheduleRetry(ctx context.Context, id, reason string, next time.Time) error
+	ExpireVerification(ctx context.Context, id, reason string) error
+	RecordRecheckPassed(ctx context.Context, id string, next time.Time) error
+	RecordRecheckFailed(ctx context.Context, id, reason string, next time.Time, threshold int) (*models.Domain, error)
+	Reassign(ctx context.Context, id, tenantID string) error
+	Search(ctx context.Context, search, tenantID string, limit, offset int) ([]models.Domain, int, error)
+	SetTenantSuspended(ctx context.Context, tenantID string, suspended bool, reason string) ([]models.Domain, error)
+	SetPrimary(ctx context.Context, tenantID, domainID string) error
+}
+
+// SettingsStore is the tenant and global settings persistence.
+// SettingsRepository implements it over the database and MemoryStore in
+// memory, next to the tenant suspensions it already tracks.
+type SettingsStore interface {
+	GetTenant(ctx context.Context, tenantID string) (*models.TenantSettings, error)
+	ListTenants(ctx context.Context) ([]models.TenantSettings, error)
+	UpsertTenant(ctx context.Context, settings *models.TenantSettings) error
+	GetGlobal(ctx context.Context) (*models.GlobalSettings, error)
+	UpsertGlobal(ctx context.Context, settings *models.GlobalSettings) error
+}
+
+// DomainPage is one page of a domain listing. Total counts every domain
+// matching the filter; NextCursor is empty on the last page.
+type DomainPage struct {
+	Domains    []models.Domain
+	Total      int
+	NextCursor string
+}
+
+// Listing page sizes
+const (
+	DefaultPageSize = 50
+	MaxPageSize     = 200
+)
+
+// pageSize clamps a requested page size
+func pageSize(limit int) int {
+	if limit <= 0 {
+		return DefaultPageSize
+	}
+	if limit > MaxPageSize {
+		return MaxPageSize
+	}
+	return limit
+}
+
+var (
+	_ DomainStore   = (*DomainRepository)(nil)
+	_ DomainStore   = (*MemoryStore)(nil)
+	_ SettingsStore = (*SettingsRepository)(nil)
+	_ SettingsStore = (*MemoryStore)(nil)
+)
@@ -0,0 +1,659 @@
+// Package storetest is a conformance suite for database.DomainStore. Every
+// implementation must pass it, so code tested against the in-memory store
+// behaves the same over the database:
+//
+//	func TestMemoryStore(t *testing.T) {
+//		storetest.Run(t, func(t *testing.T) database.DomainStore {
+//			return database.NewMemoryStore()
+//		})
+//	}
+//
+// newStore must return an empty store for each call.
+package storetest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/panaroid/domain-gateway/internal/database"
+	"github.com/panaroid/domain-gateway/pkg/models"
+)
+
+// Run runs the conformance suite against stores created by newStore
+func Run(t *testing.T, newStore func(t *testing.T) database.DomainStore) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, store database.DomainStore)
+	}{
+		{"CreateAndGet", testCreateAndGet},
+		{"UniqueDomain", testUniqueDomain},
+		{"TenantScoping", testTenantScoping},
+		{"List", testList},
+		{"SetPrimary", testSetPrimary},
+		{"Verification", testVerification},
+		{"InvalidTransition", testInvalidTransition},
+		{"Archive", testArchive},
+		{"Claims", testClaims},
+		{"Recheck", testRecheck},
+		{"TenantSuspension", testTenantSuspension},
+		{"Reassign", testReassign},
+		{"Search", testSearch},
+		{"Delete", testDelete},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, newStore(t))
+		})
+	}
+}
+
+func testCreateAndGet(t *testing.T, store database.DomainStore) {
+	ctx := context.Background()
+	domain := create(t, store, uuid.New().String(), "shop.example.com", models.DomainTypeCustom)
+
+	if domain.ID == "" || domain.CreatedAt.IsZero() {
+		t.Fatalf("Create did not set ID and CreatedAt: %+v", domain)
+	}
+	if domain.Status != models.StatusPendingDNS {
+		t.Errorf("new domain status = %q, want %q", domain.Status, models.StatusPendingDNS)
+	}
+
+	byID := get(t, store, domain.ID)
+	if byID.Domain != domain.Domain || byID.TenantID != domain.TenantID {
+		t.Errorf("GetByID = %+v, want %+v", byID, domain)
+	}
+
+	byName, err := store.GetByDomain(ctx, domain.Domain)
+	if err != nil {
+		t.Fatalf("GetByDomain: %v", err)
+	}
+	if byName == nil || byName.ID != domain.ID {
+		t.Errorf("GetByDomain = %+v, want ID %s", byName, domain.ID)
+	}
+
+	missing, err := store.GetByID(ctx, uuid.New().String())
+	if err != nil || missing != nil {
+		t.Errorf("GetByID of a missing domain = %+v, %v; want nil, nil", missing, err)
+	}
+	missing, err = store.GetByDomain(ctx, "missing.example.com")
+	if err != nil || missing != nil {
+		t.Errorf("GetByDomain of a missing domain = %+v, %v; want nil, nil", missing, err)
+	}
+}
+
+func testUniqueDomain(t *testing.T, store database.DomainStore) {
+	create(t, store, uuid.New().String(), "taken.example.com", models.DomainTypeCustom)
+
+	err := store.Create(context.Background(), &models.Domain{
+		TenantID: uuid.New().String(),
+		Domain:   "taken.example.com",
+		Type:     models.DomainTypeCustom,
+	})
+	if !errors.Is(err, database.ErrDomainExists) {
+		t.Errorf("Create of a taken domain = %v, want ErrDomainExists", err)
+	}
+}
+
+func testTenantScoping(t *testing.T, store database.DomainStore) {
+	ctx := context.Background()
+	tenantA, tenantB := uuid.New().String(), uuid.New().String()
+	first := create(t, store, tenantA, "a1.example.com", models.DomainTypeCustom)
+	second := create(t, store, tenantA, "a2.example.com", models.DomainTypeCustom)
+	create(t, store, tenantB, "b1.example.com", models.DomainTypeCustom)
+
+	domains, err := store.ListByTenant(ctx, tenantA)
+	if err != nil {
+		t.Fatalf("ListByTenant: %v", err)
+	}
+	if len(domains) != 2 {
+		t.Fatalf("ListByTenant returned %d domains, want 2", len(domains))
+	}
+	if domains[0].ID != second.ID || domains[1].ID != first.ID {
+		t.Errorf("ListByTenant is not newest first: %s, %s", domains[0].Domain, domains[1].Domain)
+	}
+
+	none, err := store.ListByTenant(ctx, uuid.New().String())
+	if err != nil || len(none) != 0 {
+		t.Errorf("ListByTenant of an unknown tenant = %d domains, %v; want none", len(none), err)
+	}
+}
+
+func testList(t *testing.T, store database.DomainStore) {
+	ctx := context.Background()
+	tenantID := uuid.New().String()
+	for _, name := range []string{"a.shop.com", "b.shop.com", "c.blog.com", "d.shop.com", "e_x.shop.com"} {
+		create(t, store, tenantID, name, models.DomainTypeCustom)
+	}
+	sub := create(t, store, tenantID, "f.example.com", models.DomainTypeSubdomain)
+	create(t, store, uuid.New().String(), "g.shop.com", models.DomainTypeCustom)
+
+	// Walk every page and check the pages are newest first without gaps
+	var names []string
+	filter := models.DomainFilter{TenantID: tenantID, Limit: 4}
+	for pages := 0; ; pages++ {
+		if pages > 2 {
+			t.Fatalf("List did not stop paging")
+		}
+		page, err := store.List(ctx, filter)
+		if err != nil {
+			t.Fatalf("List: %v", err)
+		}
+		if page.Total != 6 {
+			t.Errorf("List total = %d, want 6", page.Total)
+		}
+		for _, d := range page.Domains {
+			names = append(names, d.Domain)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		filter.Cursor = page.NextCursor
+	}
+	want := []string{"f.example.com", "e_x.shop.com", "d.shop.com", "c.blog.com", "b.shop.com", "a.shop.com"}
+	if len(names) != len(want) {
+		t.Fatalf("List pages = %v, want %v", names, want)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Fatalf("List pages = %v, want %v", names, want)
+		}
+	}
+
+	yes, no := true, false
+	filters := []struct {
+		name   string
+		filter models.DomainFilter
+		want   int
+	}{
+		{"search", models.DomainFilter{Search: "SHOP"}, 4},
+		{"search wildcard", models.DomainFilter{Search: "_x"}, 1},
+		{"type", models.DomainFilter{Type: models.DomainTypeSubdomain}, 1},
+		{"status", models.DomainFilter{Status: models.StatusPendingDNS}, 5},
+		{"verified", models.DomainFilter{Verified: &yes}, 1},
+		{"unverified", models.DomainFilter{Verified: &no}, 5},
+		{"primary", models.DomainFilter{Primary: &yes}, 0},
+		{"not archived", models.DomainFilter{Archived: &no}, 6},
+	}
+	for _, tt := range filters {
+		tt.filter.TenantID = tenantID
+		page, err := store.List(ctx, tt.filter)
+		if err != nil {
+			t.Fatalf("List %s: %v", tt.name, err)
+		}
+		if page.Total != tt.want || len(page.Domains) != tt.want {
+			t.Errorf("List %s = %d domains of %d, want %d", tt.name, len(page.Domains), page.Total, tt.want)
+		}
+	}
+
+	if err := store.SetPrimary(ctx, tenantID, sub.ID); err != nil {
+		t.Fatalf("SetPrimary: %v", err)
+	}
+	page, err := store.List(ctx, models.DomainFilter{TenantID: tenantID, Primary: &yes})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if page.Total != 1 || page.Domains[0].ID != sub.ID {
+		t.Errorf("List primary = %d domains, want the primary one", page.Total)
+	}
+
+	if _, err := store.List(ctx, models.DomainFilter{TenantID: tenantID, Cursor: "not a cursor"}); !errors.Is(err, database.ErrInvalidCursor) {
+		t.Errorf("List with a bad cursor = %v, want ErrInvalidCursor", err)
+	}
+}
+
+func testSetPrimary(t *testing.T, store database.DomainStore) {
+	ctx := context.Background()
+	tenantID := uuid.New().String()
+	first := create(t, store, tenantID, "p1.example.com", models.DomainTypeSubdomain)
+	second := create(t, store, tenantID, "p2.example.com", models.DomainTypeSubdomain)
+	other := create(t, store, uuid.New().String(), "p3.example.com", models.DomainTypeSubdomain)
+
+	if err := store.SetPrimary(ctx, tenantID, first.ID); err != nil {
+		t.Fatalf("SetPrimary: %v", err)
+	}
+	if err := store.SetPrimary(ctx, other.TenantID, other.ID); err != nil {
+		t.Fatalf("SetPrimary: %v", err)
+	}
+	if err := store.SetPrimary(ctx, tenantID, second.ID); err != nil {
+		t.Fatalf("SetPrimary: %v", err)
+	}
+
+	primary, err := store.GetPrimaryByTenant(ctx, tenantID)
+	if err != nil {
+		t.Fatalf("GetPrimaryByTenant: %v", err)
+	}
+	if primary == nil || primary.ID != second.ID {
+		t.Fatalf("GetPrimaryByTenant = %+v, want %s", primary, second.Domain)
+	}
+	if get(t, store, first.ID).IsPrimary {
+		t.Errorf("previous primary is still primary")
+	}
+	if !get(t, store, other.ID).IsPrimary {
+		t.Errorf("another tenant's primary was unset")
+	}
+
+	// Another tenant's domain cannot be made primary
+	if err := store.SetPrimary(ctx, tenantID, other.ID); err != nil {
+		t.Fatalf("SetPrimary: %v", err)
+	}
+	if !get(t, store, other.ID).IsPrimary {
+		t.Errorf("SetPrimary changed another tenant's domain")
+	}
+}
+
+func testVerification(t *testing.T, store database.DomainStore) {
+	ctx := context.Background()
+	domain := create(t, store, uuid.New().String(), "verify.example.com", models.DomainTypeCustom)
+
+	// A pending domain has no certificate to issue
+	if err := store.MarkSSLIssued(ctx, domain.ID); err != nil {
+		t.Fatalf("MarkSSLIssued: %v", err)
+	}
+	expectStatus(t, store, domain.ID, models.StatusPendingDNS)
+
+	if err := store.MarkVerified(ctx, domain.ID); err != nil {
+		t.Fatalf("MarkVerified: %v", err)
+	}
+	verified := expectStatus(t, store, domain.ID, models.StatusCertPending)
+	if !verified.Verified || verified.VerifiedAt == nil {
+		t.Errorf("verified domain = %+v, want Verified with VerifiedAt", verified)
+	}
+
+	if err := store.MarkVerified(ctx, domain.ID); err != nil {
+		t.Errorf("MarkVerified of a verified domain: %v", err)
+	}
+
+	if err := store.MarkSSLIssued(ctx, domain.ID); err != nil {
+		t.Fatalf("MarkSSLIssued: %v", err)
+	}
+	active := expectStatus(t, store, domain.ID, models.StatusActive)
+	if !active.SSLIssued {
+		t.Errorf("active domain has SSLIssued unset")
+	}
+
+	if err := store.MarkCertFailed(ctx, domain.ID, "certificate expired"); err != nil {
+		t.Fatalf("MarkCertFailed: %v", err)
+	}
+	failed := expectStatus(t, store, domain.ID, models.StatusCertFailed)
+	if failed.LastError != "certificate expired" {
+		t.Errorf("LastError = %q, want the failure reason", failed.LastError)
+	}
+
+	verifiedDomains, err := store.GetAllVerified(ctx)
+	if err != nil {
+		t.Fatalf("GetAllVerified: %v", err)
+	}
+	if len(verifiedDomains) != 1 || verifiedDomains[0].ID != domain.ID {
+		t.Errorf("GetAllVerified returned %d domains, want the verified one", len(verifiedDomains))
+	}
+
+	if err := store.MarkUnverified(ctx, domain.ID); err != nil {
+		t.Fatalf("MarkUnverified: %v", err)
+	}
+	unverified := expectStatus(t, store, domain.ID, models.StatusPendingDNS)
+	if unverified.Verified || unverified.VerifiedAt != nil || unverified.PendingSince == nil {
+		t.Errorf("unverified domain = %+v, want verification cleared and PendingSince set", unverified)
+	}
+}
+
+func testInvalidTransition(t *testing.T, store database.DomainStore) {
+	ctx := context.Background()
+	domain := create(t, store, uuid.New().String(), "expire.example.com", models.DomainTypeCustom)
+
+	if err := store.ExpireVerification(ctx, domain.ID, "timed out"); err != nil {
+		t.Fatalf("ExpireVerification: %v", err)
+	}
+	expectStatus(t, store, domain.ID, models.StatusVerificationExpired)
+
+	// An expired domain is never checked, so it cannot be misconfigured
+	_, err := store.RecordRecheckFailed(ctx, domain.ID, "moved", time.Now().Add(time.Hour), 1)
+	if !errors.Is(err, database.ErrInvalidTransition) {
+		t.Errorf("RecordRecheckFailed of an expired domain = %v, want ErrInvalidTransition", err)
+	}
+	expectStatus(t, store, domain.ID, models.StatusVerificationExpired)
+}
+
+func testArchive(t *testing.T, store database.DomainStore) {
+	ctx := context.Background()
+	domain := create(t, store, uuid.New().String(), "archive.example.com", models.DomainTypeSubdomain)
+
+	domain.Archived = true
+	domain.RedirectURL = "https://example.com"
+	if err := store.Update(ctx, domain); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	archived := expectStatus(t, store, domain.ID, models.StatusArchived)
+	if archived.RedirectURL != "https://example.com" || archived.Verified {
+		t.Errorf("archived domain = %+v, want the redirect kept and Verified unset", archived)
+	}
+
+	archived.Archived = false
+	if err := store.Update(ctx, archived); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	expectStatus(t, store, domain.ID, models.StatusActive)
+}
+
+func testClaims(t *testing.T, store database.DomainStore) {
+	ctx := context.Background()
+	tenantID := uuid.New().String()
+	pending := create(t, store, tenantID, "claim.example.com", models.DomainTypeCustom)
+	create(t, store, tenantID, "sub.example.com", models.DomainTypeSubdomain)
+
+	due, err := store.GetPendingVerification(ctx)
+	if err != nil {
+		t.Fatalf("GetPendingVerification: %v", err)
+	}
+	if len(due) != 1 || due[0].ID != pending.ID {
+		t.Fatalf("GetPendingVerification returned %d domains, want the custom one", len(due))
+	}
+
+	claimed, err := store.ClaimPendingVerification(ctx, 10, time.Minute)
+	if err != nil {
+		t.Fatalf("ClaimPendingVerification: %v", err)
+	}
+	if len(claimed) != 1 || claimed[0].Status != models.StatusVerifying {
+		t.Fatalf("ClaimPendingVerification = %+v, want the pending domain verifying", claimed)
+	}
+
+	again, err := store.ClaimPendingVerification(ctx, 10, time.Minute)
+	if err != nil {
+		t.Fatalf("ClaimPendingVerification: %v", err)
+	}
+	if len(again) != 0 {
+		t.Errorf("a claimed domain was claimed again")
+	}
+
+	next := time.Now().Add(time.Hour)
+	if err := store.ScheduleRetry(ctx, pending.ID, "no CNAME", next); err != nil {
+		t.Fatalf("ScheduleRetry: %v", err)
+	}
+	retried := expectStatus(t, store, pending.ID, models.StatusPendingDNS)
+	if retried.VerificationAttempts != 1 || retried.NextCheckAt == nil || retried.LastError != "no CNAME" {
+		t.Errorf("retried domain = %+v, want one attempt scheduled with the error", retried)
+	}
+
+	due, err = store.GetPendingVerification(ctx)
+	if err != nil {
+		t.Fatalf("GetPendingVerification: %v", err)
+	}
+	if len(due) != 0 {
+		t.Errorf("a domain scheduled for later is due")
+	}
+
+	// A retry landing after the domain left verification does not move it back
+	if err := store.MarkVerified(ctx, pending.ID); err != nil {
+		t.Fatalf("MarkVerified: %v", err)
+	}
+	if err := store.ScheduleRetry(ctx, pending.ID, "late", next); err != nil {
+		t.Fatalf("ScheduleRetry: %v", err)
+	}
+	expectStatus(t, store, pending.ID, models.StatusCertPending)
+}
+
+func testRecheck(t *testing.T, store database.DomainStore) {
+	ctx := context.Background()
+	domain := create(t, store, uuid.New().String(), "recheck.example.com", models.DomainTypeCustom)
+	if err := store.MarkVerified(ctx, domain.ID); err != nil {
+		t.Fatalf("MarkVerified: %v", err)
+	}
+	if err := store.MarkSSLIssued(ctx, domain.ID); err != nil {
+		t.Fatalf("MarkSSLIssued: %v", err)
+	}
+
+	claimed, err := store.ClaimDueRecheck(ctx, 10, time.Minute)
+	if err != nil {
+		t.Fatalf("ClaimDueRecheck: %v", err)
+	}
+	if len(claimed) != 1 || claimed[0].Status != models.StatusActive {
+		t.Fatalf("ClaimDueRecheck = %+v, want the active domain unchanged", claimed)
+	}
+
+	next := time.Now().Add(-time.Second)
+	failed, err := store.RecordRecheckFailed(ctx, domain.ID, "moved", next, 2)
+	if err != nil {
+		t.Fatalf("RecordRecheckFailed: %v", err)
+	}
+	if failed.Status != models.StatusActive || failed.RecheckFailures != 1 {
+		t.Errorf("after one failure = %s with %d failures, want active with 1", failed.Status, failed.RecheckFailures)
+	}
+
+	failed, err = store.RecordRecheckFailed(ctx, domain.ID, "moved", next, 2)
+	if err != nil {
+		t.Fatalf("RecordRecheckFailed: %v", err)
+	}
+	if failed.Status != models.StatusMisconfigured || failed.MisconfiguredAt == nil {
+		t.Errorf("after reaching the threshold = %+v, want misconfigured", failed)
+	}
+
+	if err := store.RecordRecheckPassed(ctx, domain.ID, time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("RecordRecheckPassed: %v", err)
+	}
+	recovered := expectStatus(t, store, domain.ID, models.StatusActive)
+	if recovered.RecheckFailures != 0 || recovered.MisconfiguredAt != nil {
+		t.Errorf("recovered domain = %+v, want failures cleared", recovered)
+	}
+}
+
+func testTenantSuspension(t *testing.T, store database.DomainStore) {
+	ctx := context.Background()
+	tenantID := uuid.New().String()
+	active := create(t, store, tenantID, "live.example.com", models.DomainTypeSubdomain)
+	archived := create(t, store, tenantID, "old.example.com", models.DomainTypeSubdomain)
+	archived.Archived = true
+	if err := store.Update(ctx, archived); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	changed, err := store.SetTenantSuspended(ctx, tenantID, true, "unpaid")
+	if err != nil {
+		t.Fatalf("SetTenantSuspended: %v", err)
+	}
+	if len(changed) != 1 || changed[0].ID != active.ID {
+		t.Fatalf("SetTenantSuspended changed %d domains, want the active one", len(changed))
+	}
+	suspended := expectStatus(t, store, active.ID, models.StatusSuspended)
+	if !suspended.Suspended || !suspended.Verified {
+		t.Errorf("suspended domain = %+v, want Suspended and still Verified", suspended)
+	}
+	expectStatus(t, store, archived.ID, models.StatusArchived)
+
+	// Unarchiving under a suspended tenant leaves the domain suspended
+	unarchived := get(t, store, archived.ID)
+	unarchived.Archived = false
+	if err := store.Update(ctx, unarchived); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	expectStatus(t, store, archived.ID, models.StatusSuspended)
+
+	if _, err := store.SetTenantSuspended(ctx, tenantID, false, ""); err != nil {
+		t.Fatalf("SetTenantSuspended: %v", err)
+	}
+	expectStatus(t, store, active.ID, models.StatusActive)
+	expectStatus(t, store, archived.ID, models.StatusActive)
+}
+
+func testReassign(t *testing.T, store database.DomainStore) {
+	ctx := context.Background()
+	from, to := uuid.New().String(), uuid.New().String()
+	domain := create(t, store, from, "move.example.com", models.DomainTypeSubdomain)
+	if err := store.SetPrimary(ctx, from, domain.ID); err != nil {
+		t.Fatalf("SetPrimary: %v", err)
+	}
+	if _, err := store.SetTenantSuspended(ctx, to, true, ""); err != nil {
+		t.Fatalf("SetTenantSuspended: %v", err)
+	}
+
+	if err := store.Reassign(ctx, domain.ID, to); err != nil {
+		t.Fatalf("Reassign: %v", err)
+	}
+	moved := expectStatus(t, store, domain.ID, models.StatusSuspended)
+	if moved.TenantID != to || moved.IsPrimary {
+		t.Errorf("reassigned domain = %+v, want the new tenant and not primary", moved)
+	}
+
+	if err := store.Reassign(ctx, uuid.New().String(), to); !errors.Is(err, database.ErrDomainNotFound) {
+		t.Errorf("Reassign of a missing domain = %v, want ErrDomainNotFound", err)
+	}
+}
+
+func testSearch(t *testing.T, store database.DomainStore) {
+	ctx := context.Background()
+	tenantID := uuid.New().String()
+	create(t, store, tenantID, "alpha.example.com", models.DomainTypeCustom)
+	create(t, store, tenantID, "beta.example.com", models.DomainTypeCustom)
+	create(t, store, uuid.New().String(), "alpha.example.org", models.DomainTypeCustom)
+
+	domains, total, err := store.Search(ctx, "alpha", "", 1, 0)
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if total != 2 || len(domains) != 1 || domains[0].Domain != "alpha.example.org" {
+		t.Errorf("Search = %d of %d, want the newest of 2 matches", len(domains), total)
+	}
+
+	domains, total, err = store.Search(ctx, "", tenantID, 10, 1)
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if total != 2 || len(domains) != 1 || domains[0].Domain != "alpha.example.com" {
+		t.Errorf("Search by tenant = %d of %d, want the second of 2", len(domains), total)
+	}
+
+	// LIKE wildcards in the search are matched literally
+	for _, search := range []string{"_", "%"} {
+		if _, total, err := store.Search(ctx, search, "", 10, 0); err != nil || total != 0 {
+			t.Errorf("Search(%q) = %d matches, %v; want none", search, total, err)
+		}
+	}
+}
+
+func testDelete(t *testing.T, store database.DomainStore) {
+	ctx := context.Background()
+	tenantID := uuid.New().String()
+	domain := create(t, store, tenantID, "delete.example.com", models.DomainTypeSubdomain)
+	if err := store.SetPrimary(ctx, tenantID, domain.ID); err != nil {
+		t.Fatalf("SetPrimary: %v", err)
+	}
+
+	// Deleting moves the domain to the trash
+	if err := store.Delete(ctx, domain.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	deleted := expectStatus(t, store, domain.ID, models.StatusDeleted)
+	if !deleted.Deleted || deleted.DeletedAt == nil || deleted.Verified || deleted.IsPrimary {
+		t.Errorf("deleted domain = %+v, want deleted, unverified and not primary", deleted)
+	}
+	if err := store.Delete(ctx, domain.ID); err != nil {
+		t.Errorf("Delete of a deleted domain: %v", err)
+	}
+	if verified, _ := store.GetAllVerified(ctx); len(verified) != 0 {
+		t.Errorf("GetAllVerified returned %d domains, want the deleted one left out", len(verified))
+	}
+
+	live, err := store.List(ctx, models.DomainFilter{TenantID: tenantID})
+	if err != nil || live.Total != 0 {
+		t.Errorf("List of live domains = %+v, %v; want none", live, err)
+	}
+	trash, err := store.List(ctx, models.DomainFilter{TenantID: tenantID, Deleted: true})
+	if err != nil || trash.Total != 1 {
+		t.Errorf("List of the trash = %+v, %v; want the deleted domain", trash, err)
+	}
+
+	// The name stays reserved, and a deleted domain is not suspended
+	err = store.Create(ctx, &models.Domain{TenantID: uuid.New().String(), Domain: "delete.example.com", Type: models.DomainTypeCustom})
+	if !errors.Is(err, database.ErrDomainExists) {
+		t.Errorf("Create of a deleted domain's name = %v, want ErrDomainExists", err)
+	}
+	if _, err := store.SetTenantSuspended(ctx, tenantID, true, "billing"); err != nil {
+		t.Fatalf("SetTenantSuspended: %v", err)
+	}
+	expectStatus(t, store, domain.ID, models.StatusDeleted)
+	if _, err := store.SetTenantSuspended(ctx, tenantID, false, ""); err != nil {
+		t.Fatalf("SetTenantSuspended: %v", err)
+	}
+
+	// Restoring returns the domain to the state it was deleted in
+	restored, err := store.Restore(ctx, domain.ID)
+	if err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if restored.Status != models.StatusActive || !restored.Verified || restored.DeletedAt != nil {
+		t.Errorf("restored domain = %+v, want active and verified", restored)
+	}
+	if _, err := store.Restore(ctx, domain.ID); !errors.Is(err, database.ErrInvalidTransition) {
+		t.Errorf("Restore of a live domain = %v, want ErrInvalidTransition", err)
+	}
+
+	// Only domains deleted before the cutoff are purged
+	if err := store.Delete(ctx, domain.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	purged, err := store.PurgeDeleted(ctx, time.Now().Add(-time.Hour), 10)
+	if err != nil || len(purged) != 0 {
+		t.Errorf("PurgeDeleted before retention = %d domains, %v; want none", len(purged), err)
+	}
+	purged, err = store.PurgeDeleted(ctx, time.Now().Add(time.Second), 10)
+	if err != nil || len(purged) != 1 || purged[0].ID != domain.ID {
+		t.Fatalf("PurgeDeleted = %d domains, %v; want the deleted domain", len(purged), err)
+	}
+	if got, _ := store.GetByID(ctx, domain.ID); got != nil {
+		t.Errorf("purged domain is still stored")
+	}
+	if err := store.Delete(ctx, domain.ID); !errors.Is(err, database.ErrDomainNotFound) {
+		t.Errorf("Delete of a missing domain = %v, want ErrDomainNotFound", err)
+	}
+
+	// The name is free again
+	create(t, store, uuid.New().String(), "delete.example.com", models.DomainTypeCustom)
+}
+
+// create stores a domain in the status CreateDomain gives its type
+func create(t *testing.T, store database.DomainStore, tenantID, name string, domainType models.DomainType) *models.Domain {
+	t.Helper()
+
+	domain := &models.Domain{TenantID: tenantID, Domain: name, Type: domainType}
+	if domainType == models.DomainTypeSubdomain {
+		domain.SetStatus(models.StatusActive, "")
+	} else {
+		domain.SetStatus(models.StatusPendingDNS, "")
+		domain.VerificationToken = uuid.New().String()
+	}
+	if err := store.Create(context.Background(), domain); err != nil {
+		t.Fatalf("Create %s: %v", name, err)
+	}
+
+	// Keep creation times distinct so orderings are stable
+	time.Sleep(2 * time.Millisecond)
+	return domain
+}
+
+// get loads a domain that must exist
+func get(t *testing.T, store database.DomainStore, id string) *models.Domain {
+	t.Helper()
+
+	domain, err := store.GetByID(context.Background(), id)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if domain == nil {
+		t.Fatalf("domain %s not found", id)
+	}
+	return domain
+}
+
+// expectStatus loads a domain and checks its status
+func expectStatus(t *testing.T, store database.DomainStore, id string, want models.DomainStatus) *models.Domain {
+	t.Helper()
+
+	domain := get(t, store, id)
+	if domain.Status != want {
+		t.Errorf("%s status = %q, want %q", domain.Domain, domain.Status, want)
+	}
+	return domain
+}
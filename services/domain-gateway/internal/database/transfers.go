@@ -0,0 +1,366 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/panaroid/domain-gateway/pkg/models"
+)
+
+// TransferWindow is how long a domain transfer stays open after it is initiated
+const TransferWindow = 7 * 24 * time.Hour
+
+// transferColumns is the column list matching scanTransfer
+const transferColumns = `id, domain_id, domain, from_tenant_id, to_tenant_id, status, challenge_token,
+	created_at, expires_at, accepted_at, completed_at, cancelled_at`
+
+var (
+	// ErrTransferNotFound is returned when a domain transfer does not exist
+	ErrTransferNotFound = errors.New("domain transfer not found")
+
+	// ErrTransferExists is returned when a domain already has an open transfer
+	ErrTransferExists = errors.New("domain transfer already open")
+
+	// ErrTransferNotOpen is returned when a transfer is not in the state a
+	// step requires, has expired, or its domain changed hands since it began
+	ErrTransferNotOpen = errors.New("domain transfer is not open")
+)
+
+// TransferRepository handles domain transfer database operations
+type TransferRepository struct {
+	db *DB
+}
+
+// NewTransferRepository creates a new transfer repository
+func NewTransferRepository(db *DB) *TransferRepository {
+	return &TransferRepository{db: db}
+}
+
+// Create opens a transfer of a domain from its current tenant. It returns
+// ErrTransferExists if the domain already has an open transfer.
+func (r *TransferRepository) Create(ctx context.Context, transfer *models.DomainTransfer) error {
+	if transfer.ID == "" {
+		transfer.ID = uuid.New().String()
+	}
+	transfer.Status = models.TransferPending
+	transfer.CreatedAt = time.Now().UTC()
+	transfer.ExpiresAt = transfer.CreatedAt.Add(TransferWindow)
+
+	return r.db.WithTx(ctx, func(tx *Tx) error {
+		// Locking the domain serialises transfers of it
+		domain, err := lockDomain(ctx, tx, transfer.DomainID)
+		if err != nil {
+			return err
+		}
+		if domain.Deleted {
+			return fmt.Errorf("%w: %s is deleted", ErrInvalidTransition, domain.Domain)
+		}
+
+		var open int
+		err = tx.QueryRowContext(ctx, `
+			SELECT COUNT(*) FROM domain_transfers
+			WHERE domain_id = $1 AND status IN ($2, $3) AND expires_at > $4
+		`, transfer.DomainID, models.TransferPending, models.TransferAccepted, transfer.CreatedAt).Scan(&open)
+		if err != nil {
+			return fmt.Errorf("failed to check open transfers: %w", err)
+		}
+		if open > 0 {
+			return ErrTransferExists
+		}
+
+		transfer.Domain = domain.Domain
+		transfer.FromTenantID = domain.TenantID
+
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO domain_transfers (id, domain_id, domain, from_tenant_id, to_tenant_id, status, created_at, expires_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		`,
+			transfer.ID,
+			transfer.DomainID,
+			transfer.Domain,
+			transfer.FromTenantID,
+			transfer.ToTenantID,
+			transfer.Status,
+			transfer.CreatedAt,
+			transfer.ExpiresAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to create domain transfer: %w", err)
+		}
+		return nil
+	})
+}
+
+// Get retrieves a transfer by ID
+func (r *TransferRepository) Get(ctx context.Context, id string) (*models.DomainTransfer, error) {
+	query := `
+		SELECT ` + transferColumns + `
+		FROM domain_transfers
+		WHERE id = $1
+	`
+
+	transfer, err := scanTransfer(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get domain transfer: %w", err)
+	}
+
+	return transfer, nil
+}
+
+// ListByTenant retrieves the transfers a tenant is giving or receiving, newest first
+func (r *TransferRepository) ListByTenant(ctx context.Context, tenantID string) ([]models.DomainTransfer, error) {
+	query := `
+		SELECT ` + transferColumns + `
+		FROM domain_transfers
+		WHERE from_tenant_id = $1 OR to_tenant_id = $1
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list domain transfers: %w", err)
+	}
+	defer rows.Close()
+
+	var transfers []models.DomainTransfer
+	for rows.Next() {
+		transfer, err := scanTransfer(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan domain transfer: %w", err)
+		}
+		transfers = append(transfers, *transfer)
+	}
+
+	return transfers, rows.Err()
+}
+
+// Accept records that the receiving tenant accepted a pending transfer and
+// sets the challenge token it must publish to complete it
+func (r *TransferRepository) Accept(ctx context.Context, id, challengeToken string) (*models.DomainTransfer, error) {
+	now := time.Now().UTC()
+	return r.advance(ctx, id, models.TransferPending, `
+		UPDATE domain_transfers
+		SET status = $2, challenge_token = $3, accepted_at = $4
+		WHERE id = $1
+	`, models.TransferAccepted, challengeToken, now)
+}
+
+// Cancel cancels an open transfer
+func (r *TransferRepository) Cancel(ctx context.Context, id string) (*models.DomainTransfer, error) {
+	now := time.Now().UTC()
+	return r.advance(ctx, id, "", `
+		UPDATE domain_transfers
+		SET status = $2, cancelled_at = $3
+		WHERE id = $1
+	`, models.TransferCancelled, now)
+}
+
+// Complete moves the domain of an accepted transfer to the receiving tenant
+// once it has proven control of the domain's DNS. The domain keeps its
+// verification under the challenge token, is no longer primary, and takes on
+// the new tenant's suspension state; ownership and routing switch in one
+// transaction. The event is recorded in both tenants' histories. It returns
+// the moved domain.
+func (r *TransferRepository) Complete(ctx context.Context, id string) (*models.Domain, error) {
+	var after *models.Domain
+	err := r.db.WithTx(ctx, func(tx *Tx) error {
+		transfer, err := lockTransfer(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		if transfer.Status != models.TransferAccepted {
+			return fmt.Errorf("%w: transfer is %s", ErrTransferNotOpen, transfer.Status)
+		}
+
+		before, err := lockDomain(ctx, tx, transfer.DomainID)
+		if err != nil {
+			return err
+		}
+		if before.Deleted || before.TenantID != transfer.FromTenantID {
+			return fmt.Errorf("%w: %s changed since the transfer began", ErrTransferNotOpen, before.Domain)
+		}
+
+		// The receiving tenant's challenge becomes the domain's proof of ownership
+		_, err = tx.ExecContext(ctx, `UPDATE domains SET verification_token = $2 WHERE id = $1`, before.ID, transfer.ChallengeToken)
+		if err != nil {
+			return fmt.Errorf("failed to update verification token: %w", err)
+		}
+
+		after, err = reassignDomain(ctx, tx, before, transfer.ToTenantID)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			UPDATE domain_transfers SET status = $2, completed_at = $3 WHERE id = $1
+		`, id, models.TransferCompleted, time.Now().UTC())
+		if err != nil {
+			return fmt.Errorf("failed to complete domain transfer: %w", err)
+		}
+
+		return recordMove(ctx, tx, models.EventDomainTransferred, before, after, models.DomainTransferred{
+			Domain:       *after,
+			TransferID:   transfer.ID,
+			FromTenantID: transfer.FromTenantID,
+			ToTenantID:   transfer.ToTenantID,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return after, nil
+}
+
+// advance applies update to an open transfer in status from, or in any open
+// status if from is empty, and returns the updated transfer
+func (r *TransferRepository) advance(ctx context.Context, id, from, update string, args ...interface{}) (*models.DomainTransfer, error) {
+	var transfer *models.DomainTransfer
+	err := r.db.WithTx(ctx, func(tx *Tx) error {
+		current, err := lockTransfer(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		if !current.IsOpen() || (from != "" && current.Status != from) {
+			return fmt.Errorf("%w: transfer is %s", ErrTransferNotOpen, current.Status)
+		}
+
+		if _, err := tx.ExecContext(ctx, update, append([]interface{}{id}, args...)...); err != nil {
+			return fmt.Errorf("failed to update domain transfer: %w", err)
+		}
+
+		transfer, err = lockTransfer(ctx, tx, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return transfer, nil
+}
+
+// cancelOpenTransfers cancels the open transfers of a domain that is leaving
+// its tenant some other way, recording the cancellation in the histories of
+// both tenants of each transfer
+func cancelOpenTransfers(ctx context.Context, tx *Tx, domain *models.Domain, reason string) error {
+	query := `
+		SELECT ` + transferColumns + `
+		FROM domain_transfers
+		WHERE domain_id = $1 AND status IN ($2, $3)
+		FOR UPDATE
+	`
+
+	rows, err := tx.QueryContext(ctx, query, domain.ID, models.TransferPending, models.TransferAccepted)
+	if err != nil {
+		return fmt.Errorf("failed to list open domain transfers: %w", err)
+	}
+	var open []*models.DomainTransfer
+	for rows.Next() {
+		transfer, err := scanTransfer(rows)
+		if err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan domain transfer: %w", err)
+		}
+		open = append(open, transfer)
+	}
+	if err := rows.Close(); err != nil {
+		return fmt.Errorf("failed to list open domain transfers: %w", err)
+	}
+
+	now := time.Now().UTC()
+	for _, transfer := range open {
+		_, err := tx.ExecContext(ctx, `
+			UPDATE domain_transfers SET status = $2, cancelled_at = $3 WHERE id = $1
+		`, transfer.ID, models.TransferCancelled, now)
+		if err != nil {
+			return fmt.Errorf("failed to cancel domain transfer: %w", err)
+		}
+
+		data := models.DomainTransferCancelled{
+			Domain:       *domain,
+			TransferID:   transfer.ID,
+			FromTenantID: transfer.FromTenantID,
+			ToTenantID:   transfer.ToTenantID,
+			Reason:       reason,
+		}
+		if err := recordEventData(ctx, tx, models.EventDomainTransferCancelled, domain, domain, data); err != nil {
+			return err
+		}
+
+		// The receiving tenant never owned the domain but is owed the news
+		receiving := *domain
+		receiving.TenantID = transfer.ToTenantID
+		if err := recordEventData(ctx, tx, models.EventDomainTransferCancelled, &receiving, &receiving, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lockTransfer loads a transfer within a transaction, locking its row
+func lockTransfer(ctx context.Context, tx *Tx, id string) (*models.DomainTransfer, error) {
+	query := `
+		SELECT ` + transferColumns + `
+		FROM domain_transfers
+		WHERE id = $1
+		FOR UPDATE
+	`
+
+	transfer, err := scanTransfer(tx.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrTransferNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get domain transfer: %w", err)
+	}
+	return transfer, nil
+}
+
+// scanTransfer scans a single transfer row selected with transferColumns.
+// An open transfer past its expiry is reported as expired.
+func scanTransfer(row rowScanner) (*models.DomainTransfer, error) {
+	transfer := &models.DomainTransfer{}
+	var challengeToken sql.NullString
+	var acceptedAt, completedAt, cancelledAt sql.NullTime
+
+	if err := row.Scan(
+		&transfer.ID,
+		&transfer.DomainID,
+		&transfer.Domain,
+		&transfer.FromTenantID,
+		&transfer.ToTenantID,
+		&transfer.Status,
+		&challengeToken,
+		&transfer.CreatedAt,
+		&transfer.ExpiresAt,
+		&acceptedAt,
+		&completedAt,
+		&cancelledAt,
+	); err != nil {
+		return nil, err
+	}
+
+	transfer.ChallengeToken = challengeToken.String
+	if acceptedAt.Valid {
+		transfer.AcceptedAt = &acceptedAt.Time
+	}
+	if completedAt.Valid {
+		transfer.CompletedAt = &completedAt.Time
+	}
+	if cancelledAt.Valid {
+		transfer.CancelledAt = &cancelledAt.Time
+	}
+	if transfer.IsOpen() && time.Now().After(transfer.ExpiresAt) {
+		transfer.Status = models.TransferExpired
+	}
+
+	return transfer, nil
+}
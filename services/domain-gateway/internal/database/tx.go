@@ -0,0 +1,70 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/panaroid/domain-gateway/pkg/models"
+)
+
+// Tx is a transaction that collects the domain events recorded in it
+type Tx struct {
+	*sql.Tx
+	db     *DB
+	events []models.DomainEvent
+}
+
+// OnDomainEvent registers fn to receive each domain event once its
+// transaction has committed
+func (db *DB) OnDomainEvent(fn func(models.DomainEvent)) {
+	db.listenersMu.Lock()
+	defer db.listenersMu.Unlock()
+	db.listeners = append(db.listeners, fn)
+}
+
+// WithTx runs fn in a transaction, committing if it returns nil and then
+// passing the recorded domain events to the registered listeners
+func (db *DB) WithTx(ctx context.Context, fn func(tx *Tx) error) error {
+	sqlTx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer sqlTx.Rollback()
+
+	tx := &Tx{Tx: sqlTx, db: db}
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	if err := sqlTx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	db.listenersMu.RLock()
+	listeners := db.listeners
+	db.listenersMu.RUnlock()
+
+	for _, event := range tx.events {
+		for _, fn := range listeners {
+			fn(event)
+		}
+	}
+
+	return nil
+}
+
+// ExecContext executes a query written for Postgres in the database's dialect
+func (tx *Tx) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return tx.Tx.ExecContext(ctx, tx.db.dialect.rebind(query), tx.db.dialect.bindArgs(args)...)
+}
+
+// QueryContext runs a query written for Postgres in the database's dialect
+func (tx *Tx) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return tx.Tx.QueryContext(ctx, tx.db.dialect.rebind(query), tx.db.dialect.bindArgs(args)...)
+}
+
+// QueryRowContext runs a single-row query written for Postgres in the database's dialect
+func (tx *Tx) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return tx.Tx.QueryRowContext(ctx, tx.db.dialect.rebind(query), tx.db.dialect.bindArgs(args)...)
+}
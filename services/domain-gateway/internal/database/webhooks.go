@@ -0,0 +1,402 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/panaroid/domain-gateway/pkg/models"
+)
+
+// webhookDeliveryColumns is the column list matching scanWebhookDelivery
+const webhookDeliveryColumns = `id, endpoint_id, tenant_id, event_id, event_type, payload, status, attempts, next_attempt_at, last_status_code, last_error, replay_of, created_at, delivered_at`
+
+var (
+	// ErrWebhookNotFound is returned when a webhook endpoint does not exist for the tenant
+	ErrWebhookNotFound = errors.New("webhook endpoint not found")
+
+	// ErrDeliveryNotFound is returned when a webhook delivery does not exist for the tenant
+	ErrDeliveryNotFound = errors.New("webhook delivery not found")
+)
+
+// DueDelivery is a delivery claimed for sending, with its endpoint's target
+type DueDelivery struct {
+	models.WebhookDelivery
+	URL    string
+	Secret string
+}
+
+// WebhookRepository handles webhook endpoint, outbox and delivery database operations
+type WebhookRepository struct {
+	db *DB
+}
+
+// NewWebhookRepository creates a new webhook repository
+func NewWebhookRepository(db *DB) *WebhookRepository {
+	return &WebhookRepository{db: db}
+}
+
+// CreateEndpoint registers a webhook endpoint
+func (r *WebhookRepository) CreateEndpoint(ctx context.Context, endpoint *models.WebhookEndpoint) error {
+	if endpoint.ID == "" {
+		endpoint.ID = uuid.New().String()
+	}
+	endpoint.Active = true
+	endpoint.CreatedAt = time.Now().UTC()
+	endpoint.UpdatedAt = endpoint.CreatedAt
+
+	query := `
+		INSERT INTO webhook_endpoints (id, tenant_id, url, secret, events, active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		endpoint.ID,
+		endpoint.TenantID,
+		endpoint.URL,
+		endpoint.Secret,
+		strings.Join(endpoint.Events, ","),
+		endpoint.Active,
+		endpoint.CreatedAt,
+		endpoint.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create webhook endpoint: %w", err)
+	}
+
+	return nil
+}
+
+// ListEndpoints retrieves a tenant's webhook endpoints
+func (r *WebhookRepository) ListEndpoints(ctx context.Context, tenantID string) ([]models.WebhookEndpoint, error) {
+	query := `
+		SELECT id, tenant_id, url, secret, events, active, created_at, updated_at
+		FROM webhook_endpoints
+		WHERE tenant_id = $1
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook endpoints: %w", err)
+	}
+	defer rows.Close()
+
+	return scanWebhookEndpoints(rows)
+}
+
+// DeleteEndpoint removes a tenant's webhook endpoint and its delivery log
+func (r *WebhookRepository) DeleteEndpoint(ctx context.Context, tenantID, id string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM webhook_endpoints WHERE id = $1 AND tenant_id = $2`, id, tenantID)
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook endpoint: %w", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return ErrWebhookNotFound
+	}
+
+	return nil
+}
+
+// FanOut turns undispatched outbox entries into deliveries for each subscribed
+// endpoint. Entries are locked so concurrent dispatchers never fan out twice.
+func (r *WebhookRepository) FanOut(ctx context.Context, limit int) (int, error) {
+	sqlTx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer sqlTx.Rollback()
+	tx := &Tx{Tx: sqlTx, db: r.db}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, tenant_id, event_type, payload
+		FROM outbox
+		WHERE dispatched_at IS NULL
+		ORDER BY created_at ASC
+		LIMIT $1
+		FOR UPDATE SKIP LOCKED
+	`, limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read outbox: %w", err)
+	}
+
+	type entry struct {
+		id, tenantID, eventType, payload string
+	}
+	var entries []entry
+	for rows.Next() {
+		var e entry
+		if err := rows.Scan(&e.id, &e.tenantID, &e.eventType, &e.payload); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan outbox entry: %w", err)
+		}
+		entries = append(entries, e)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	now := time.Now().UTC()
+	endpoints := make(map[string][]models.WebhookEndpoint)
+
+	for _, e := range entries {
+		tenantEndpoints, ok := endpoints[e.tenantID]
+		if !ok {
+			epRows, err := tx.QueryContext(ctx, `
+				SELECT id, tenant_id, url, secret, events, active, created_at, updated_at
+				FROM webhook_endpoints
+				WHERE tenant_id = $1 AND active = TRUE
+			`, e.tenantID)
+			if err != nil {
+				return 0, fmt.Errorf("failed to load webhook endpoints: %w", err)
+			}
+			tenantEndpoints, err = scanWebhookEndpoints(epRows)
+			epRows.Close()
+			if err != nil {
+				return 0, err
+			}
+			endpoints[e.tenantID] = tenantEndpoints
+		}
+
+		for i := range tenantEndpoints {
+			if !tenantEndpoints[i].Subscribed(e.eventType) {
+				continue
+			}
+			_, err := tx.ExecContext(ctx, `
+				INSERT INTO webhook_deliveries (id, endpoint_id, tenant_id, event_id, event_type, payload, status, attempts, next_attempt_at, created_at)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, 0, $8, $8)
+			`, uuid.New().String(), tenantEndpoints[i].ID, e.tenantID, e.id, e.eventType, e.payload, models.DeliveryPending, now)
+			if err != nil {
+				return 0, fmt.Errorf("failed to create webhook delivery: %w", err)
+			}
+		}
+
+		if _, err := tx.ExecContext(ctx, `UPDATE outbox SET dispatched_at = $2 WHERE id = $1`, e.id, now); err != nil {
+			return 0, fmt.Errorf("failed to mark outbox entry dispatched: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit fan-out: %w", err)
+	}
+
+	return len(entries), nil
+}
+
+// ClaimDue claims pending deliveries whose next attempt is due by pushing their
+// next attempt out by lease, so no other dispatcher picks them up meanwhile
+func (r *WebhookRepository) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]DueDelivery, error) {
+	now := time.Now().UTC()
+
+	query := `
+		UPDATE webhook_deliveries
+		SET next_attempt_at = $2
+		WHERE id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE status = 'pending' AND next_attempt_at <= $1
+			ORDER BY next_attempt_at ASC
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + webhookDeliveryColumns + `,
+			(SELECT url FROM webhook_endpoints e WHERE e.id = endpoint_id),
+			(SELECT secret FROM webhook_endpoints e WHERE e.id = endpoint_id)
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, now, now.Add(lease), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var due []DueDelivery
+	for rows.Next() {
+		var d DueDelivery
+		delivery, err := scanWebhookDelivery(rows, &d.URL, &d.Secret)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook delivery: %w", err)
+		}
+		d.WebhookDelivery = *delivery
+		due = append(due, d)
+	}
+
+	return due, rows.Err()
+}
+
+// MarkDelivered records a successful delivery attempt
+func (r *WebhookRepository) MarkDelivered(ctx context.Context, id string, statusCode int) error {
+	now := time.Now().UTC()
+
+	query := `
+		UPDATE webhook_deliveries
+		SET status = $2, attempts = attempts + 1, last_status_code = $3, last_error = NULL,
+			next_attempt_at = NULL, delivered_at = $4
+		WHERE id = $1
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, id, models.DeliverySucceeded, statusCode, now); err != nil {
+		return fmt.Errorf("failed to mark webhook delivered: %w", err)
+	}
+	return nil
+}
+
+// MarkAttemptFailed records a failed delivery attempt. A nil nextAttempt means
+// retries are exhausted and the delivery is marked failed.
+func (r *WebhookRepository) MarkAttemptFailed(ctx context.Context, id string, statusCode int, reason string, nextAttempt *time.Time) error {
+	status := models.DeliveryPending
+	var next sql.NullTime
+	if nextAttempt != nil {
+		next = sql.NullTime{Time: *nextAttempt, Valid: true}
+	} else {
+		status = models.DeliveryFailed
+	}
+
+	query := `
+		UPDATE webhook_deliveries
+		SET status = $2, attempts = attempts + 1, last_status_code = $3, last_error = $4, next_attempt_at = $5
+		WHERE id = $1
+	`
+
+	_, err := r.db.ExecContext(ctx, query, id, status, sql.NullInt64{Int64: int64(statusCode), Valid: statusCode != 0}, nullString(reason), next)
+	if err != nil {
+		return fmt.Errorf("failed to record webhook attempt: %w", err)
+	}
+	return nil
+}
+
+// ListDeliveries retrieves the delivery log of a tenant's endpoint, newest first
+func (r *WebhookRepository) ListDeliveries(ctx context.Context, tenantID, endpointID string, limit, offset int) ([]models.WebhookDelivery, int, error) {
+	var total int
+	err := r.db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM webhook_deliveries WHERE tenant_id = $1 AND endpoint_id = $2`,
+		tenantID, endpointID,
+	).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count webhook deliveries: %w", err)
+	}
+
+	query := `
+		SELECT ` + webhookDeliveryColumns + `
+		FROM webhook_deliveries
+		WHERE tenant_id = $1 AND endpoint_id = $2
+		ORDER BY created_at DESC, id DESC
+		LIMIT $3 OFFSET $4
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, tenantID, endpointID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var deliveries []models.WebhookDelivery
+	for rows.Next() {
+		delivery, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan webhook delivery: %w", err)
+		}
+		deliveries = append(deliveries, *delivery)
+	}
+
+	return deliveries, total, rows.Err()
+}
+
+// Replay queues a fresh delivery of a past delivery's event, keeping the original in the log
+func (r *WebhookRepository) Replay(ctx context.Context, tenantID, endpointID, deliveryID string) (*models.WebhookDelivery, error) {
+	now := time.Now().UTC()
+
+	query := `
+		INSERT INTO webhook_deliveries (id, endpoint_id, tenant_id, event_id, event_type, payload, status, attempts, next_attempt_at, replay_of, created_at)
+		SELECT $4, endpoint_id, tenant_id, event_id, event_type, payload, $5, 0, $6, id, $6
+		FROM webhook_deliveries
+		WHERE id = $1 AND tenant_id = $2 AND endpoint_id = $3
+		RETURNING ` + webhookDeliveryColumns
+
+	delivery, err := scanWebhookDelivery(r.db.QueryRowContext(ctx, query,
+		deliveryID, tenantID, endpointID, uuid.New().String(), models.DeliveryPending, now,
+	))
+	if err == sql.ErrNoRows {
+		return nil, ErrDeliveryNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to replay webhook delivery: %w", err)
+	}
+
+	return delivery, nil
+}
+
+func scanWebhookEndpoints(rows *sql.Rows) ([]models.WebhookEndpoint, error) {
+	var endpoints []models.WebhookEndpoint
+	for rows.Next() {
+		var endpoint models.WebhookEndpoint
+		var events string
+		if err := rows.Scan(
+			&endpoint.ID,
+			&endpoint.TenantID,
+			&endpoint.URL,
+			&endpoint.Secret,
+			&events,
+			&endpoint.Active,
+			&endpoint.CreatedAt,
+			&endpoint.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan webhook endpoint: %w", err)
+		}
+		endpoint.Events = []string{}
+		if events != "" {
+			endpoint.Events = strings.Split(events, ",")
+		}
+		endpoints = append(endpoints, endpoint)
+	}
+
+	return endpoints, rows.Err()
+}
+
+// scanWebhookDelivery scans a delivery selected with webhookDeliveryColumns,
+// followed by any extra destinations
+func scanWebhookDelivery(row rowScanner, extra ...interface{}) (*models.WebhookDelivery, error) {
+	delivery := &models.WebhookDelivery{}
+	var nextAttemptAt, deliveredAt sql.NullTime
+	var lastStatusCode sql.NullInt64
+	var lastError, replayOf sql.NullString
+
+	dest := []interface{}{
+		&delivery.ID,
+		&delivery.EndpointID,
+		&delivery.TenantID,
+		&delivery.EventID,
+		&delivery.EventType,
+		&delivery.Payload,
+		&delivery.Status,
+		&delivery.Attempts,
+		&nextAttemptAt,
+		&lastStatusCode,
+		&lastError,
+		&replayOf,
+		&delivery.CreatedAt,
+		&deliveredAt,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+
+	if nextAttemptAt.Valid {
+		delivery.NextAttemptAt = &nextAttemptAt.Time
+	}
+	if deliveredAt.Valid {
+		delivery.DeliveredAt = &deliveredAt.Time
+	}
+	delivery.LastStatusCode = int(lastStatusCode.Int64)
+	delivery.LastError = lastError.String
+	delivery.ReplayOf = replayOf.String
+
+	return delivery, nil
+}
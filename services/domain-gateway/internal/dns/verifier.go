@@ -2,6 +2,8 @@ package dns
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
 	"net"
 	"strings"
@@ -15,6 +17,7 @@ import (
 // Verifier handles DNS verification for custom domains
 type Verifier struct {
 	logger      *zap.Logger
+	resolver    *net.Resolver
 	cnameTarget string
 }
 
@@ -22,6 +25,7 @@ type Verifier struct {
 func NewVerifier(logger *zap.Logger) *Verifier {
 	return &Verifier{
 		logger:      logger,
+		resolver:    net.DefaultResolver,
 		cnameTarget: "cname.panaroid.com",
 	}
 }
@@ -31,6 +35,71 @@ func (v *Verifier) GenerateToken() string {
 	return fmt.Sprintf("panaroid-verify-%d", time.Now().UnixNano())
 }
 
+// ChallengePrefix is the label under a domain where ownership challenges
+// are published as TXT records
+const ChallengePrefix = "_panaroid-challenge"
+
+// GenerateChallenge generates an unguessable token for proving control of a
+// domain's DNS, as a tenant taking over a domain must
+func (v *Verifier) GenerateChallenge() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate challenge token: %w", err)
+	}
+	return "panaroid-challenge-" + hex.EncodeToString(b), nil
+}
+
+// GetChallengeInstructions returns instructions for publishing a challenge token
+func (v *Verifier) GetChallengeInstructions(domain, token string) *models.VerificationInfo {
+	recordName := ChallengePrefix + "." + domain
+
+	return &models.VerificationInfo{
+		RecordType:  "TXT",
+		RecordName:  recordName,
+		RecordValue: token,
+		Instructions: fmt.Sprintf(
+			"أضف سجل TXT إلى DNS الخاص بنطاقك لإثبات ملكيته:\n\nاسم السجل: %s\nالنوع: TXT\nالقيمة: %s",
+			recordName,
+			token,
+		),
+	}
+}
+
+// VerifyChallenge checks whether token is published as a TXT record under the
+// domain's challenge name. The lookup is bounded by ctx like Verify.
+func (v *Verifier) VerifyChallenge(ctx context.Context, domain, token string) (bool, error) {
+	lookupName := ChallengePrefix + "." + domain
+
+	records, err := v.resolver.LookupTXT(ctx, lookupName)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return false, fmt.Errorf("lookup %s: %w", lookupName, ctxErr)
+		}
+		if dnsErr, ok := err.(*net.DNSError); ok && dnsErr.IsNotFound {
+			v.logger.Debug("Challenge record not found", zap.String("domain", domain))
+			return false, nil
+		}
+		v.logger.Warn("DNS lookup error",
+			zap.String("domain", domain),
+			zap.Error(err),
+		)
+		return false, nil
+	}
+
+	for _, record := range records {
+		if strings.TrimSpace(record) == token {
+			v.logger.Info("Domain challenge verified", zap.String("domain", domain))
+			return true, nil
+		}
+	}
+
+	v.logger.Debug("Challenge token not published",
+		zap.String("domain", domain),
+		zap.Int("records", len(records)),
+	)
+	return false, nil
+}
+
 // GetVerificationInstructions returns instructions for DNS verification
 func (v *Verifier) GetVerificationInstructions(domain, token string) *models.VerificationInfo {
 	parts := strings.Split(domain, ".")
@@ -52,7 +121,8 @@ func (v *Verifier) GetVerificationInstructions(domain, token string) *models.Ver
 	}
 }
 
-// Verify checks if the CNAME record points to our target
+// Verify checks if the CNAME record points to our target. The lookup is
+// bounded by ctx; a lookup cut short by ctx is returned as an error.
 func (v *Verifier) Verify(ctx context.Context, domain *models.Domain) (bool, error) {
 	// For CNAME verification, we check if the domain resolves to our CNAME target
 	lookupName := domain.Domain
@@ -68,8 +138,11 @@ func (v *Verifier) Verify(ctx context.Context, domain *models.Domain) (bool, err
 		zap.String("lookup", lookupName),
 	)
 
-	cname, err := net.LookupCNAME(lookupName)
+	cname, err := v.resolver.LookupCNAME(ctx, lookupName)
 	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return false, fmt.Errorf("lookup %s: %w", lookupName, ctxErr)
+		}
 		if dnsErr, ok := err.(*net.DNSError); ok && dnsErr.IsNotFound {
 			v.logger.Debug("CNAME record not found", zap.String("domain", domain.Domain))
 			return false, nil
@@ -0,0 +1,131 @@
+package events
+
+import (
+	"sync"
+
+	"go.uber.org/zap"
+
+	"github.com/panaroid/domain-gateway/pkg/models"
+)
+
+// subscriptionBuffer is how many events a subscriber may fall behind before it is dropped
+const subscriptionBuffer = 64
+
+// Broker fans domain events out to per-tenant subscribers
+type Broker struct {
+	logger *zap.Logger
+	mu     sync.RWMutex
+	subs   map[string]map[*Subscription]struct{}
+	closed bool
+}
+
+// Subscription receives a tenant's domain events until closed. Its channel is
+// closed if the subscriber falls too far behind, when it should reload state,
+// and when the broker is closed.
+type Subscription struct {
+	broker   *Broker
+	tenantID string
+	events   chan models.DomainEvent
+	once     sync.Once
+}
+
+// NewBroker creates a new event broker
+func NewBroker(logger *zap.Logger) *Broker {
+	return &Broker{
+		logger: logger,
+		subs:   make(map[string]map[*Subscription]struct{}),
+	}
+}
+
+// Subscribe starts receiving a tenant's domain events
+func (b *Broker) Subscribe(tenantID string) *Subscription {
+	sub := &Subscription{
+		broker:   b,
+		tenantID: tenantID,
+		events:   make(chan models.DomainEvent, subscriptionBuffer),
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		sub.once.Do(func() { close(sub.events) })
+		return sub
+	}
+
+	if b.subs[tenantID] == nil {
+		b.subs[tenantID] = make(map[*Subscription]struct{})
+	}
+	b.subs[tenantID][sub] = struct{}{}
+
+	return sub
+}
+
+// Publish delivers an event to the tenant's subscribers without blocking
+func (b *Broker) Publish(event models.DomainEvent) {
+	b.mu.RLock()
+	var lagging []*Subscription
+	for sub := range b.subs[event.TenantID] {
+		select {
+		case sub.events <- event:
+		default:
+			lagging = append(lagging, sub)
+		}
+	}
+	b.mu.RUnlock()
+
+	for _, sub := range lagging {
+		b.logger.Warn("Dropping slow event subscriber", zap.String("tenant_id", sub.tenantID))
+		sub.Close()
+	}
+}
+
+// Close ends every subscription, and any made later, so open event streams
+// return when the server shuts down
+func (b *Broker) Close() {
+	b.mu.Lock()
+	b.closed = true
+	var subs []*Subscription
+	for _, tenantSubs := range b.subs {
+		for sub := range tenantSubs {
+			subs = append(subs, sub)
+		}
+	}
+	b.mu.Unlock()
+
+	for _, sub := range subs {
+		sub.Close()
+	}
+}
+
+// Subscribers returns the number of open subscriptions
+func (b *Broker) Subscribers() int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	n := 0
+	for _, subs := range b.subs {
+		n += len(subs)
+	}
+	return n
+}
+
+// Events returns the subscription's event channel
+func (s *Subscription) Events() <-chan models.DomainEvent {
+	return s.events
+}
+
+// Close unsubscribes and closes the event channel
+func (s *Subscription) Close() {
+	s.once.Do(func() {
+		b := s.broker
+		b.mu.Lock()
+		delete(b.subs[s.tenantID], s)
+		if len(b.subs[s.tenantID]) == 0 {
+			delete(b.subs, s.tenantID)
+		}
+		b.mu.Unlock()
+
+		close(s.events)
+	})
+}
@@ -0,0 +1,34 @@
+package events
+
+import (
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/panaroid/domain-gateway/pkg/models"
+)
+
+func TestBrokerClose(t *testing.T) {
+	b := NewBroker(zap.NewNop())
+	open := b.Subscribe("tenant-1")
+
+	b.Close()
+
+	if _, ok := <-open.Events(); ok {
+		t.Error("open subscription still receives after Close")
+	}
+	if n := b.Subscribers(); n != 0 {
+		t.Errorf("Subscribers = %d, want 0", n)
+	}
+
+	late := b.Subscribe("tenant-1")
+	if _, ok := <-late.Events(); ok {
+		t.Error("subscription made after Close is open")
+	}
+
+	// Neither publishing nor closing again may panic
+	b.Publish(models.DomainEvent{TenantID: "tenant-1"})
+	late.Close()
+	open.Close()
+	b.Close()
+}
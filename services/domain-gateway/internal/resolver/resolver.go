@@ -0,0 +1,185 @@
+package resolver
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/panaroid/domain-gateway/internal/config"
+	"github.com/panaroid/domain-gateway/internal/database"
+	"github.com/panaroid/domain-gateway/pkg/models"
+)
+
+// Resolver maps request hosts to tenants from an in-memory index,
+// falling back to the database on a miss
+type Resolver struct {
+	repo   database.DomainStore
+	cfg    config.ResolverConfig
+	logger *zap.Logger
+	mu     sync.RWMutex
+	hosts  map[string]*entry
+}
+
+// entry is a cached resolution; a nil resolution marks an unknown host
+type entry struct {
+	resolution *models.HostResolution
+	expiresAt  time.Time
+}
+
+// NewResolver creates a new host resolver
+func NewResolver(repo database.DomainStore, cfg config.ResolverConfig, logger *zap.Logger) *Resolver {
+	return &Resolver{
+		repo:   repo,
+		cfg:    cfg,
+		logger: logger,
+		hosts:  make(map[string]*entry),
+	}
+}
+
+// Resolve returns the tenant resolution for a host, or nil if the host is unknown
+func (r *Resolver) Resolve(ctx context.Context, host string) (*models.HostResolution, error) {
+	host = NormalizeHost(host)
+	if host == "" {
+		return nil, nil
+	}
+
+	r.mu.RLock()
+	cached, ok := r.hosts[host]
+	r.mu.RUnlock()
+
+	if ok && time.Now().Before(cached.expiresAt) {
+		return cached.resolution, nil
+	}
+
+	resolution, err := r.load(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	ttl := r.cfg.CacheTTL
+	if resolution == nil {
+		ttl = r.cfg.NegativeCacheTTL
+	}
+
+	r.mu.Lock()
+	r.hosts[host] = &entry{resolution: resolution, expiresAt: time.Now().Add(ttl)}
+	r.mu.Unlock()
+
+	return resolution, nil
+}
+
+// Warm populates the index from a set of domains, e.g. on startup
+func (r *Resolver) Warm(domains []models.Domain) {
+	primaries := make(map[string]string)
+	for _, domain := range domains {
+		if domain.IsPrimary {
+			primaries[domain.TenantID] = domain.Domain
+		}
+	}
+
+	expiresAt := time.Now().Add(r.cfg.CacheTTL)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range domains {
+		domain := &domains[i]
+		r.hosts[domain.Domain] = &entry{
+			resolution: newResolution(domain, primaries[domain.TenantID]),
+			expiresAt:  expiresAt,
+		}
+	}
+
+	r.logger.Info("Resolver index warmed", zap.Int("hosts", len(domains)))
+}
+
+// Invalidate drops a host from the index
+func (r *Resolver) Invalidate(host string) {
+	host = NormalizeHost(host)
+
+	r.mu.Lock()
+	delete(r.hosts, host)
+	r.mu.Unlock()
+}
+
+// InvalidateTenant drops every host belonging to a tenant from the index
+func (r *Resolver) InvalidateTenant(tenantID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for host, cached := range r.hosts {
+		if cached.resolution != nil && cached.resolution.TenantID == tenantID {
+			delete(r.hosts, host)
+		}
+	}
+}
+
+// Size returns the number of indexed hosts
+func (r *Resolver) Size() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.hosts)
+}
+
+// load resolves a host from the database
+func (r *Resolver) load(ctx context.Context, host string) (*models.HostResolution, error) {
+	domain, err := r.repo.GetByDomain(ctx, host)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve host: %w", err)
+	}
+	// A deleted domain keeps its name reserved but is not served
+	if domain == nil || domain.Deleted {
+		return nil, nil
+	}
+
+	primary := ""
+	if domain.IsPrimary {
+		primary = domain.Domain
+	} else {
+		primaryDomain, err := r.repo.GetPrimaryByTenant(ctx, domain.TenantID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve primary domain: %w", err)
+		}
+		if primaryDomain != nil {
+			primary = primaryDomain.Domain
+		}
+	}
+
+	return newResolution(domain, primary), nil
+}
+
+// newResolution builds a resolution for a domain
+func newResolution(domain *models.Domain, primary string) *models.HostResolution {
+	status := models.ResolutionStatusPending
+	if domain.Verified {
+		status = models.ResolutionStatusActive
+	}
+	if domain.Suspended {
+		status = models.ResolutionStatusSuspended
+	}
+	if primary == "" {
+		primary = domain.Domain
+	}
+
+	return &models.HostResolution{
+		Host:          domain.Domain,
+		TenantID:      domain.TenantID,
+		DomainID:      domain.ID,
+		PrimaryDomain: primary,
+		Status:        status,
+	}
+}
+
+// NormalizeHost lowercases a host and strips any port and trailing dot
+func NormalizeHost(host string) string {
+	host = strings.ToLower(strings.TrimSpace(host))
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.TrimSuffix(host, ".")
+}
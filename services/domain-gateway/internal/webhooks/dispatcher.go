@@ -0,0 +1,227 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math"
+	mathrand "math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/panaroid/domain-gateway/internal/config"
+	"github.com/panaroid/domain-gateway/internal/database"
+)
+
+// Headers sent with every webhook request
+const (
+	HeaderEventID   = "X-Webhook-Id"
+	HeaderEventType = "X-Webhook-Event"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// SecretPrefix marks webhook signing secrets
+const SecretPrefix = "whsec_"
+
+// maxErrorBody caps how much of a failed response is kept in the delivery log
+const maxErrorBody = 512
+
+// Dispatcher fans outbox entries out to webhook endpoints and delivers them
+// with exponential backoff
+type Dispatcher struct {
+	repo   *database.WebhookRepository
+	cfg    config.WebhookConfig
+	client *http.Client
+	logger *zap.Logger
+	stopCh chan struct{}
+	wg     sync.WaitGroup
+}
+
+// NewDispatcher creates a new webhook dispatcher
+func NewDispatcher(repo *database.WebhookRepository, cfg config.WebhookConfig, logger *zap.Logger) *Dispatcher {
+	return &Dispatcher{
+		repo: repo,
+		cfg:  cfg,
+		client: &http.Client{
+			Timeout:   cfg.Timeout,
+			Transport: newTransport(cfg.Timeout),
+			// Endpoints must answer directly; redirects are treated as failures
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		logger: logger,
+		stopCh: make(chan struct{}),
+	}
+}
+
+// newTransport dials endpoints directly, refusing blocked addresses at
+// connect time so a host that resolves to a public address when registered
+// cannot be pointed at the internal network later. Proxies are not used
+// because the check would then apply to the proxy instead of the endpoint.
+func newTransport(timeout time.Duration) *http.Transport {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: dialControl,
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return transport
+}
+
+// Start starts the dispatcher
+func (d *Dispatcher) Start(ctx context.Context) {
+	d.wg.Add(1)
+	go d.run(ctx)
+	d.logger.Info("Webhook dispatcher started", zap.Duration("interval", d.cfg.PollInterval))
+}
+
+// Stop stops the dispatcher
+func (d *Dispatcher) Stop() {
+	close(d.stopCh)
+	d.wg.Wait()
+	d.logger.Info("Webhook dispatcher stopped")
+}
+
+func (d *Dispatcher) run(ctx context.Context) {
+	defer d.wg.Done()
+
+	ticker := time.NewTicker(d.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-d.stopCh:
+			return
+		case <-ticker.C:
+			d.Poll(ctx)
+		}
+	}
+}
+
+// Poll fans out new outbox entries and sends every delivery that is due
+func (d *Dispatcher) Poll(ctx context.Context) {
+	if _, err := d.repo.FanOut(ctx, d.cfg.BatchSize); err != nil {
+		d.logger.Error("Failed to fan out webhook events", zap.Error(err))
+	}
+
+	// Claimed deliveries are hidden from other dispatchers for longer than one attempt can take
+	due, err := d.repo.ClaimDue(ctx, d.cfg.BatchSize, 2*d.cfg.Timeout+time.Minute)
+	if err != nil {
+		d.logger.Error("Failed to claim webhook deliveries", zap.Error(err))
+		return
+	}
+
+	for i := range due {
+		d.deliver(ctx, &due[i])
+	}
+}
+
+// deliver sends one delivery and records the outcome
+func (d *Dispatcher) deliver(ctx context.Context, delivery *database.DueDelivery) {
+	logger := d.logger.With(
+		zap.String("delivery_id", delivery.ID),
+		zap.String("event_type", delivery.EventType),
+		zap.String("url", delivery.URL),
+	)
+
+	statusCode, err := d.send(ctx, delivery)
+	if err == nil {
+		if err := d.repo.MarkDelivered(ctx, delivery.ID, statusCode); err != nil {
+			logger.Error("Failed to record webhook delivery", zap.Error(err))
+		}
+		logger.Debug("Webhook delivered", zap.Int("status", statusCode))
+		return
+	}
+
+	attempts := delivery.Attempts + 1
+	var next *time.Time
+	if attempts < d.cfg.MaxAttempts {
+		at := time.Now().UTC().Add(d.backoff(attempts))
+		next = &at
+	}
+
+	if err := d.repo.MarkAttemptFailed(ctx, delivery.ID, statusCode, err.Error(), next); err != nil {
+		logger.Error("Failed to record webhook attempt", zap.Error(err))
+	}
+
+	if next == nil {
+		logger.Warn("Webhook delivery failed permanently", zap.Int("attempts", attempts), zap.Error(err))
+	} else {
+		logger.Debug("Webhook delivery failed, will retry", zap.Int("attempts", attempts), zap.Time("next_attempt_at", *next), zap.Error(err))
+	}
+}
+
+// send posts the signed payload, returning the response status code
+func (d *Dispatcher) send(ctx context.Context, delivery *database.DueDelivery) (int, error) {
+	body := []byte(delivery.Payload)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("invalid webhook request: %w", err)
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "domain-gateway-webhooks/1.0")
+	req.Header.Set(HeaderEventID, delivery.EventID)
+	req.Header.Set(HeaderEventType, delivery.EventType)
+	req.Header.Set(HeaderSignature, "t="+timestamp+",v1="+Sign(delivery.Secret, timestamp, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		io.Copy(io.Discard, resp.Body)
+		return resp.StatusCode, nil
+	}
+
+	snippet, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	return resp.StatusCode, fmt.Errorf("endpoint returned status %d: %s", resp.StatusCode, bytes.TrimSpace(snippet))
+}
+
+// backoff returns the delay before the next attempt: BackoffBase doubled per
+// attempt, capped at BackoffMax, with up to 10% jitter
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	delay := float64(d.cfg.BackoffBase) * math.Pow(2, float64(attempts-1))
+	if max := float64(d.cfg.BackoffMax); delay > max {
+		delay = max
+	}
+	return time.Duration(delay + delay*0.1*mathrand.Float64())
+}
+
+// Sign computes the hex HMAC-SHA256 of "<timestamp>.<body>" with the endpoint secret
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// GenerateSecret creates a new endpoint signing secret
+func GenerateSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	return SecretPrefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,110 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/panaroid/domain-gateway/internal/config"
+)
+
+func TestSign(t *testing.T) {
+	body := []byte(`{"id":"evt"}`)
+	const want = "a94cea056df1fbb92eadafcf2c5cd541dbe0c6ef736e4748202dd53f86694a3e"
+
+	if got := Sign("whsec_test", "1700000000", body); got != want {
+		t.Errorf("Sign = %s, want %s", got, want)
+	}
+	if got := Sign("whsec_other", "1700000000", body); got == want {
+		t.Error("Sign does not depend on the secret")
+	}
+	if got := Sign("whsec_test", "1700000001", body); got == want {
+		t.Error("Sign does not depend on the timestamp")
+	}
+}
+
+func TestBlockedIP(t *testing.T) {
+	tests := []struct {
+		ip      string
+		blocked bool
+	}{
+		{"127.0.0.1", true},
+		{"::1", true},
+		{"10.1.2.3", true},
+		{"172.16.0.1", true},
+		{"192.168.1.1", true},
+		{"169.254.169.254", true},
+		{"fe80::1", true},
+		{"fd00::1", true},
+		{"0.0.0.0", true},
+		{"::", true},
+		{"::ffff:127.0.0.1", true},
+		{"224.0.0.1", true},
+		{"93.184.216.34", false},
+		{"2606:4700::1111", false},
+	}
+
+	for _, tt := range tests {
+		if got := blockedIP(net.ParseIP(tt.ip)); got != tt.blocked {
+			t.Errorf("blockedIP(%s) = %v, want %v", tt.ip, got, tt.blocked)
+		}
+	}
+}
+
+func TestValidateURL(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		url     string
+		blocked bool
+		invalid bool
+	}{
+		{url: "https://93.184.216.34/hooks"},
+		{url: "http://localhost:2019/load", blocked: true},
+		{url: "http://127.0.0.1:2019/load", blocked: true},
+		{url: "http://169.254.169.254/latest/meta-data", blocked: true},
+		{url: "http://[::1]/hooks", blocked: true},
+		{url: "http://10.0.0.5/hooks", blocked: true},
+		{url: "ftp://93.184.216.34/hooks", invalid: true},
+		{url: "/hooks", invalid: true},
+	}
+
+	for _, tt := range tests {
+		err := ValidateURL(ctx, tt.url)
+		switch {
+		case tt.blocked && !errors.Is(err, ErrBlockedTarget):
+			t.Errorf("ValidateURL(%s) = %v, want ErrBlockedTarget", tt.url, err)
+		case tt.invalid && (err == nil || errors.Is(err, ErrBlockedTarget)):
+			t.Errorf("ValidateURL(%s) = %v, want an invalid URL error", tt.url, err)
+		case !tt.blocked && !tt.invalid && err != nil:
+			t.Errorf("ValidateURL(%s) = %v, want nil", tt.url, err)
+		}
+	}
+}
+
+func TestDispatcherRefusesBlockedAddresses(t *testing.T) {
+	called := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer server.Close()
+
+	d := NewDispatcher(nil, config.WebhookConfig{Timeout: time.Second}, zap.NewNop())
+
+	// The server listens on loopback, as a rebound hostname would resolve
+	url := strings.Replace(server.URL, "127.0.0.1", "localhost", 1)
+	_, err := d.client.Post(url, "application/json", strings.NewReader("{}"))
+	if !errors.Is(err, ErrBlockedTarget) {
+		t.Errorf("Post = %v, want ErrBlockedTarget", err)
+	}
+	if called {
+		t.Error("request reached a loopback endpoint")
+	}
+}
@@ -0,0 +1,68 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"syscall"
+)
+
+// ErrBlockedTarget is returned for webhook URLs that reach into the gateway's
+// own network: loopback, private, link-local and unspecified addresses
+var ErrBlockedTarget = errors.New("webhook target is not a public address")
+
+// ValidateURL checks that raw is an absolute http(s) URL whose host resolves
+// only to public addresses. The dispatcher checks the address again when it
+// dials, so a host that later resolves elsewhere is still refused.
+func ValidateURL(ctx context.Context, raw string) error {
+	target, err := url.Parse(raw)
+	if err != nil || (target.Scheme != "https" && target.Scheme != "http") || target.Hostname() == "" {
+		return errors.New("URL must be an absolute http(s) URL")
+	}
+
+	host := target.Hostname()
+	if ip := net.ParseIP(host); ip != nil {
+		if blockedIP(ip) {
+			return fmt.Errorf("%w: %s", ErrBlockedTarget, host)
+		}
+		return nil
+	}
+
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return fmt.Errorf("cannot resolve %s", host)
+	}
+	for _, addr := range addrs {
+		if blockedIP(addr.IP) {
+			return fmt.Errorf("%w: %s resolves to %s", ErrBlockedTarget, host, addr.IP)
+		}
+	}
+	return nil
+}
+
+// blockedIP reports whether ip is an address webhooks may not be sent to
+func blockedIP(ip net.IP) bool {
+	return ip.IsLoopback() ||
+		ip.IsPrivate() ||
+		ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() ||
+		ip.IsMulticast() ||
+		ip.IsUnspecified()
+}
+
+// dialControl refuses connections to blocked addresses. It runs after name
+// resolution, so it sees the address actually dialled.
+func dialControl(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrBlockedTarget, address)
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || blockedIP(ip) {
+		return fmt.Errorf("%w: %s", ErrBlockedTarget, host)
+	}
+	return nil
+}
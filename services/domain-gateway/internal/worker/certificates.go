@@ -0,0 +1,164 @@
+package worker
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/panaroid/domain-gateway/internal/database"
+	"github.com/panaroid/domain-gateway/pkg/models"
+)
+
+// probeTimeout bounds a single TLS handshake
+const probeTimeout = 10 * time.Second
+
+// CertificateMonitor periodically probes served certificates and records a
+// certificate.expiring event when one is close to expiry
+type CertificateMonitor struct {
+	repo     database.DomainStore
+	certs    *database.CertificateRepository
+	logger   *zap.Logger
+	interval time.Duration
+	warning  time.Duration
+	leader   Leadership
+	stopCh   chan struct{}
+	wg       sync.WaitGroup
+}
+
+// NewCertificateMonitor creates a new certificate monitor
+func NewCertificateMonitor(
+	repo database.DomainStore,
+	certs *database.CertificateRepository,
+	logger *zap.Logger,
+	interval time.Duration,
+	warning time.Duration,
+	leader Leadership,
+) *CertificateMonitor {
+	return &CertificateMonitor{
+		repo:     repo,
+		certs:    certs,
+		logger:   logger,
+		interval: interval,
+		warning:  warning,
+		leader:   leader,
+		stopCh:   make(chan struct{}),
+	}
+}
+
+// Start starts the certificate monitor
+func (m *CertificateMonitor) Start(ctx context.Context) {
+	ctx = database.WithActor(ctx, models.Actor{Type: models.ActorWorker, ID: "certificates"})
+
+	m.wg.Add(1)
+	go m.run(ctx)
+	m.logger.Info("Certificate monitor started", zap.Duration("interval", m.interval))
+}
+
+// Stop stops the certificate monitor
+func (m *CertificateMonitor) Stop() {
+	close(m.stopCh)
+	m.wg.Wait()
+	m.logger.Info("Certificate monitor stopped")
+}
+
+func (m *CertificateMonitor) run(ctx context.Context) {
+	defer m.wg.Done()
+
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	m.checkCertificates(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-m.stopCh:
+			return
+		case <-ticker.C:
+			m.checkCertificates(ctx)
+		}
+	}
+}
+
+func (m *CertificateMonitor) checkCertificates(ctx context.Context) {
+	// Probing is cluster-wide work, so only the leader does it
+	if m.leader != nil && !m.leader.IsLeader() {
+		return
+	}
+
+	domains, err := m.repo.GetAllVerified(ctx)
+	if err != nil {
+		m.logger.Error("Failed to list verified domains", zap.Error(err))
+		return
+	}
+
+	for i := range domains {
+		domain := &domains[i]
+		if domain.Suspended || domain.Status == models.StatusMisconfigured {
+			continue
+		}
+
+		expiresAt, err := probeCertificate(ctx, domain.Domain)
+		if err != nil {
+			m.logger.Debug("Certificate probe failed", zap.String("domain", domain.Domain), zap.Error(err))
+			continue
+		}
+
+		// An expired certificate means renewal failed; a valid one clears that
+		if !expiresAt.After(time.Now()) {
+			if err := m.repo.MarkCertFailed(ctx, domain.ID, "certificate expired"); err != nil {
+				m.logger.Error("Failed to mark certificate failed", zap.String("domain", domain.Domain), zap.Error(err))
+			}
+		} else if domain.Status == models.StatusCertFailed {
+			if err := m.repo.MarkSSLIssued(ctx, domain.ID); err != nil {
+				m.logger.Error("Failed to mark certificate issued", zap.String("domain", domain.Domain), zap.Error(err))
+			}
+		}
+
+		notified, err := m.certs.RecordCheck(ctx, domain, expiresAt, m.warning)
+		if err != nil {
+			m.logger.Error("Failed to record certificate check", zap.String("domain", domain.Domain), zap.Error(err))
+			continue
+		}
+		if notified {
+			m.logger.Warn("Certificate expiring soon",
+				zap.String("domain", domain.Domain),
+				zap.Time("expires_at", expiresAt),
+			)
+		}
+	}
+}
+
+// probeCertificate returns the expiry of the certificate served for host
+func probeCertificate(ctx context.Context, host string) (time.Time, error) {
+	ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+
+	dialer := &tls.Dialer{
+		NetDialer: &net.Dialer{},
+		Config: &tls.Config{
+			ServerName: host,
+			// Only the expiry is read; an invalid chain must still be reported
+			InsecureSkipVerify: true,
+		},
+	}
+
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(host, "443"))
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer conn.Close()
+
+	certs := conn.(*tls.Conn).ConnectionState().PeerCertificates
+	if len(certs) == 0 {
+		return time.Time{}, fmt.Errorf("no certificate presented")
+	}
+
+	return certs[0].NotAfter, nil
+}
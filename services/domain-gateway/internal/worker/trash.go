@@ -0,0 +1,111 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/panaroid/domain-gateway/internal/database"
+	"github.com/panaroid/domain-gateway/pkg/models"
+)
+
+// purgeBatch is how many deleted domains are purged per transaction
+const purgeBatch = 100
+
+// TrashPurger periodically purges domains that have been in the trash
+// longer than the retention period, releasing their names
+type TrashPurger struct {
+	repo      database.DomainStore
+	logger    *zap.Logger
+	interval  time.Duration
+	retention time.Duration
+	leader    Leadership
+	stopCh    chan struct{}
+	wg        sync.WaitGroup
+}
+
+// NewTrashPurger creates a new trash purger
+func NewTrashPurger(
+	repo database.DomainStore,
+	logger *zap.Logger,
+	interval time.Duration,
+	retention time.Duration,
+	leader Leadership,
+) *TrashPurger {
+	return &TrashPurger{
+		repo:      repo,
+		logger:    logger,
+		interval:  interval,
+		retention: retention,
+		leader:    leader,
+		stopCh:    make(chan struct{}),
+	}
+}
+
+// Start starts the trash purger
+func (p *TrashPurger) Start(ctx context.Context) {
+	ctx = database.WithActor(ctx, models.Actor{Type: models.ActorWorker, ID: "trash"})
+
+	p.wg.Add(1)
+	go p.run(ctx)
+	p.logger.Info("Trash purger started",
+		zap.Duration("interval", p.interval),
+		zap.Duration("retention", p.retention),
+	)
+}
+
+// Stop stops the trash purger
+func (p *TrashPurger) Stop() {
+	close(p.stopCh)
+	p.wg.Wait()
+	p.logger.Info("Trash purger stopped")
+}
+
+func (p *TrashPurger) run(ctx context.Context) {
+	defer p.wg.Done()
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	p.purge(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-p.stopCh:
+			return
+		case <-ticker.C:
+			p.purge(ctx)
+		}
+	}
+}
+
+func (p *TrashPurger) purge(ctx context.Context) {
+	// Purging is cluster-wide work, so only the leader does it
+	if p.leader != nil && !p.leader.IsLeader() {
+		return
+	}
+
+	cutoff := time.Now().Add(-p.retention)
+	for {
+		domains, err := p.repo.PurgeDeleted(ctx, cutoff, purgeBatch)
+		if err != nil {
+			p.logger.Error("Failed to purge deleted domains", zap.Error(err))
+			return
+		}
+
+		for _, domain := range domains {
+			p.logger.Info("Domain purged from trash",
+				zap.String("domain", domain.Domain),
+				zap.String("tenant_id", domain.TenantID),
+			)
+		}
+
+		if len(domains) < purgeBatch {
+			return
+		}
+	}
+}
@@ -3,66 +3,103 @@ package worker
 import (
 	"context"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"go.uber.org/zap"
 
 	"github.com/panaroid/domain-gateway/internal/caddy"
+	"github.com/panaroid/domain-gateway/internal/config"
 	"github.com/panaroid/domain-gateway/internal/database"
 	"github.com/panaroid/domain-gateway/internal/dns"
+	"github.com/panaroid/domain-gateway/internal/resolver"
 	"github.com/panaroid/domain-gateway/pkg/models"
 )
 
+// claimBatch is how many pending domains are claimed per query
+const claimBatch = 100
+
+// Leadership reports whether this instance should run cluster-wide scans
+type Leadership interface {
+	IsLeader() bool
+}
+
 // VerificationWorker periodically checks pending domain verifications
 type VerificationWorker struct {
-	repo         *database.DomainRepository
+	repo         database.DomainStore
 	verifier     *dns.Verifier
 	caddyManager *caddy.Manager
+	resolver     *resolver.Resolver
+	leader       Leadership
+	cfg          config.WorkerConfig
 	logger       *zap.Logger
-	interval     time.Duration
-	maxRetries   int
 	stopCh       chan struct{}
 	wg           sync.WaitGroup
+	statusMu     sync.RWMutex
+	status       models.WorkerStatus
 }
 
-// NewVerificationWorker creates a new verification worker
+// NewVerificationWorker creates a new verification worker. A nil leader runs
+// every cycle, as does a sharded worker.
 func NewVerificationWorker(
-	repo *database.DomainRepository,
+	repo database.DomainStore,
 	verifier *dns.Verifier,
 	caddyManager *caddy.Manager,
+	resolver *resolver.Resolver,
+	leader Leadership,
+	cfg config.WorkerConfig,
 	logger *zap.Logger,
-	interval time.Duration,
-	maxRetries int,
 ) *VerificationWorker {
+	if cfg.Concurrency < 1 {
+		cfg.Concurrency = 1
+	}
+
 	return &VerificationWorker{
 		repo:         repo,
 		verifier:     verifier,
 		caddyManager: caddyManager,
+		resolver:     resolver,
+		leader:       leader,
+		cfg:          cfg,
 		logger:       logger,
-		interval:     interval,
-		maxRetries:   maxRetries,
 		stopCh:       make(chan struct{}),
 	}
 }
 
 // Start starts the verification worker
 func (w *VerificationWorker) Start(ctx context.Context) {
+	w.statusMu.Lock()
+	w.status.Running = true
+	w.statusMu.Unlock()
+
+	// Changes made by the background loop are attributed to the worker
+	ctx = database.WithActor(ctx, models.Actor{Type: models.ActorWorker, ID: "verification"})
+
 	w.wg.Add(1)
 	go w.run(ctx)
-	w.logger.Info("Verification worker started", zap.Duration("interval", w.interval))
+	w.logger.Info("Verification worker started",
+		zap.Duration("interval", w.cfg.VerificationInterval),
+		zap.Int("concurrency", w.cfg.Concurrency),
+		zap.Bool("sharded", w.cfg.Sharded),
+	)
 }
 
 // Stop stops the verification worker
 func (w *VerificationWorker) Stop() {
 	close(w.stopCh)
 	w.wg.Wait()
+
+	w.statusMu.Lock()
+	w.status.Running = false
+	w.statusMu.Unlock()
+
 	w.logger.Info("Verification worker stopped")
 }
 
 func (w *VerificationWorker) run(ctx context.Context) {
 	defer w.wg.Done()
 
-	ticker := time.NewTicker(w.interval)
+	ticker := time.NewTicker(w.cfg.VerificationInterval)
 	defer ticker.Stop()
 
 	// Run immediately on start
@@ -80,56 +117,203 @@ func (w *VerificationWorker) run(ctx context.Context) {
 	}
 }
 
+// active reports whether this instance should scan this cycle. Sharded
+// workers all scan and split domains through claims; otherwise only the leader does.
+func (w *VerificationWorker) active() bool {
+	return w.cfg.Sharded || w.leader == nil || w.leader.IsLeader()
+}
+
+// checkPendingDomains runs one cycle: pending domains are verified, then
+// verified domains due for re-verification are checked again
 func (w *VerificationWorker) checkPendingDomains(ctx context.Context) {
+	if !w.active() {
+		w.logger.Debug("Not the leader, skipping verification cycle")
+		return
+	}
+
 	w.logger.Debug("Checking pending domain verifications")
 
-	domains, err := w.repo.GetPendingVerification(ctx)
-	if err != nil {
-		w.logger.Error("Failed to get pending domains", zap.Error(err))
-		return
+	start := time.Now()
+	pending, verified := 0, 0
+	var rechecked int64
+	var cycleErr error
+	defer func() {
+		w.recordCycle(start, pending, verified, int(rechecked), cycleErr)
+	}()
+
+	// Lookups stop being started once the cycle budget is spent; domains
+	// not reached are picked up again next cycle
+	cycleCtx := ctx
+	if w.cfg.CycleBudget > 0 {
+		var cancel context.CancelFunc
+		cycleCtx, cancel = context.WithTimeout(ctx, w.cfg.CycleBudget)
+		defer cancel()
+	}
+
+	jobs := make(chan job)
+	var activated int64
+	var pool sync.WaitGroup
+	for i := 0; i < w.cfg.Concurrency; i++ {
+		pool.Add(1)
+		go func() {
+			defer pool.Done()
+			for j := range jobs {
+				if j.recheck {
+					w.recheckDomain(ctx, &j.domain)
+					atomic.AddInt64(&rechecked, 1)
+				} else if w.processDomain(ctx, &j.domain) {
+					atomic.AddInt64(&activated, 1)
+				}
+			}
+		}()
+	}
+
+	// Claims last one interval, so each domain is checked once per cycle
+	// across the cluster even if several instances scan. Pending domains
+	// go first; re-verification gets whatever is left of the cycle.
+	var dispatched int
+	cycleErr = w.dispatch(cycleCtx, jobs, w.repo.ClaimPendingVerification, false, &dispatched)
+	pending = dispatched
+	if cycleErr == nil && w.cfg.ReverifyInterval > 0 {
+		cycleErr = w.dispatch(cycleCtx, jobs, w.repo.ClaimDueRecheck, true, &dispatched)
 	}
+	close(jobs)
+	pool.Wait()
+	verified = int(activated)
 
-	if len(domains) == 0 {
-		w.logger.Debug("No pending domains to verify")
+	if cycleErr != nil {
+		w.logger.Error("Failed to claim domains", zap.Error(cycleErr))
+	}
+
+	if dispatched == 0 {
+		w.logger.Debug("No domains to verify")
 		return
 	}
 
-	w.logger.Info("Found pending domains", zap.Int("count", len(domains)))
+	w.logger.Info("Checked domains",
+		zap.Int("pending", pending),
+		zap.Int("verified", verified),
+		zap.Int64("rechecked", atomic.LoadInt64(&rechecked)),
+	)
+}
+
+// job is one domain handed to the verification pool
+type job struct {
+	domain  models.Domain
+	recheck bool
+}
+
+// claimFunc leases up to limit domains to this instance
+type claimFunc func(ctx context.Context, limit int, lease time.Duration) ([]models.Domain, error)
+
+// dispatch claims domains and feeds them to the pool until none are left,
+// the per-cycle limit is reached or the cycle budget runs out. dispatched
+// counts domains handed out across calls in one cycle.
+func (w *VerificationWorker) dispatch(ctx context.Context, jobs chan<- job, claim claimFunc, recheck bool, dispatched *int) error {
+	for {
+		batch := claimBatch
+		if w.cfg.MaxPerCycle > 0 {
+			if remaining := w.cfg.MaxPerCycle - *dispatched; remaining < batch {
+				batch = remaining
+			}
+		}
+		if batch <= 0 {
+			w.logger.Info("Verification cycle limit reached", zap.Int("limit", w.cfg.MaxPerCycle))
+			return nil
+		}
+
+		domains, err := claim(ctx, batch, w.cfg.VerificationInterval)
+		if err != nil {
+			if ctx.Err() != nil {
+				w.logger.Warn("Verification cycle budget exhausted", zap.Duration("budget", w.cfg.CycleBudget))
+				return nil
+			}
+			return err
+		}
+
+		for _, domain := range domains {
+			select {
+			case jobs <- job{domain: domain, recheck: recheck}:
+				*dispatched++
+			case <-ctx.Done():
+				w.logger.Warn("Verification cycle budget exhausted", zap.Duration("budget", w.cfg.CycleBudget))
+				return nil
+			}
+		}
+
+		if len(domains) < batch {
+			return nil
+		}
+	}
+}
+
+// recordCycle stores the outcome of a verification cycle for Status
+func (w *VerificationWorker) recordCycle(start time.Time, pending, verified, rechecked int, err error) {
+	w.statusMu.Lock()
+	defer w.statusMu.Unlock()
 
-	for _, domain := range domains {
-		w.processDomain(ctx, &domain)
+	w.status.Cycles++
+	w.status.LastRunAt = &start
+	w.status.LastDuration = time.Since(start).String()
+	w.status.LastPending = pending
+	w.status.LastVerified = verified
+	w.status.LastRechecked = rechecked
+	w.status.LastError = ""
+	if err != nil {
+		w.status.LastError = err.Error()
 	}
 }
 
-func (w *VerificationWorker) processDomain(ctx context.Context, domain *models.Domain) {
+// Status returns a snapshot of the worker's recent activity
+func (w *VerificationWorker) Status() models.WorkerStatus {
+	w.statusMu.RLock()
+	defer w.statusMu.RUnlock()
+
+	status := w.status
+	status.Interval = w.cfg.VerificationInterval.String()
+	status.Active = w.active()
+	return status
+}
+
+// processDomain verifies and activates a domain, reporting whether it was activated
+func (w *VerificationWorker) processDomain(ctx context.Context, domain *models.Domain) bool {
 	logger := w.logger.With(
 		zap.String("domain", domain.Domain),
 		zap.String("domain_id", domain.ID),
 	)
 
 	// Verify DNS record
-	verified, err := w.verifier.Verify(ctx, domain)
+	verified, err := w.verify(ctx, domain)
 	if err != nil {
 		logger.Error("Verification failed", zap.Error(err))
-		return
+		w.scheduleRetry(ctx, logger, domain, err.Error())
+		return false
 	}
 
 	if !verified {
 		logger.Debug("Domain not yet verified")
-		return
+		w.scheduleRetry(ctx, logger, domain, "DNS record not found")
+		return false
 	}
 
+	return w.activate(ctx, logger, domain)
+}
+
+// activate marks a verified domain as such and routes it
+func (w *VerificationWorker) activate(ctx context.Context, logger *zap.Logger, domain *models.Domain) bool {
 	// Mark as verified in database
 	if err := w.repo.MarkVerified(ctx, domain.ID); err != nil {
 		logger.Error("Failed to mark domain as verified", zap.Error(err))
-		return
+		return false
 	}
 
+	w.resolver.Invalidate(domain.Domain)
+
 	// Add route to Caddy
-	domain.Verified = true
+	domain.SetStatus(models.StatusCertPending, "")
 	if err := w.caddyManager.AddDomain(ctx, domain); err != nil {
 		logger.Error("Failed to add domain to Caddy", zap.Error(err))
-		return
+		return false
 	}
 
 	// Mark SSL as issued (Caddy will handle cert automatically)
@@ -138,6 +322,114 @@ func (w *VerificationWorker) processDomain(ctx context.Context, domain *models.D
 	}
 
 	logger.Info("Domain verified and activated successfully")
+	return true
+}
+
+// scheduleRetry pushes a failed domain's next check out with exponential
+// backoff, or gives up once the verification window has passed
+func (w *VerificationWorker) scheduleRetry(ctx context.Context, logger *zap.Logger, domain *models.Domain, reason string) {
+	since := domain.CreatedAt
+	if domain.PendingSince != nil {
+		since = *domain.PendingSince
+	}
+	if w.cfg.VerificationWindow > 0 && time.Since(since) > w.cfg.VerificationWindow {
+		if err := w.repo.ExpireVerification(ctx, domain.ID, reason); err != nil {
+			logger.Error("Failed to expire domain verification", zap.Error(err))
+			return
+		}
+		logger.Info("Domain verification expired", zap.Int("attempts", domain.VerificationAttempts+1))
+		return
+	}
+
+	next := time.Now().UTC().Add(w.backoff(domain.VerificationAttempts + 1))
+	if err := w.repo.ScheduleRetry(ctx, domain.ID, reason, next); err != nil {
+		logger.Error("Failed to schedule verification retry", zap.Error(err))
+	}
+}
+
+// recheckDomain re-verifies an active domain. Sustained failures mark it
+// misconfigured; once misconfigured for longer than the grace period its
+// verification is cleared and its route removed.
+func (w *VerificationWorker) recheckDomain(ctx context.Context, domain *models.Domain) {
+	logger := w.logger.With(
+		zap.String("domain", domain.Domain),
+		zap.String("domain_id", domain.ID),
+	)
+
+	ok, err := w.verify(ctx, domain)
+	if err == nil && ok {
+		next := time.Now().UTC().Add(w.cfg.ReverifyInterval)
+		if err := w.repo.RecordRecheckPassed(ctx, domain.ID, next); err != nil {
+			logger.Error("Failed to record re-verification", zap.Error(err))
+		} else if domain.Status == models.StatusMisconfigured {
+			logger.Info("Domain is correctly configured again")
+		}
+		return
+	}
+
+	reason := "DNS record not found"
+	if err != nil {
+		reason = err.Error()
+	}
+
+	next := time.Now().UTC().Add(w.backoff(domain.RecheckFailures + 1))
+	if next.After(time.Now().Add(w.cfg.ReverifyInterval)) {
+		next = time.Now().UTC().Add(w.cfg.ReverifyInterval)
+	}
+	updated, err := w.repo.RecordRecheckFailed(ctx, domain.ID, reason, next, w.cfg.MisconfiguredAfter)
+	if err != nil {
+		logger.Error("Failed to record re-verification failure", zap.Error(err))
+		return
+	}
+	if updated == nil || updated.Status != models.StatusMisconfigured {
+		logger.Debug("Re-verification failed", zap.String("reason", reason))
+		return
+	}
+	if domain.Status != models.StatusMisconfigured {
+		logger.Warn("Domain is misconfigured", zap.String("reason", reason), zap.Int("failures", updated.RecheckFailures))
+		return
+	}
+
+	if w.cfg.MisconfiguredGrace <= 0 || updated.MisconfiguredAt == nil || time.Since(*updated.MisconfiguredAt) < w.cfg.MisconfiguredGrace {
+		return
+	}
+
+	if err := w.repo.MarkUnverified(ctx, domain.ID); err != nil {
+		logger.Error("Failed to clear misconfigured domain", zap.Error(err))
+		return
+	}
+	if err := w.caddyManager.RemoveDomain(ctx, domain.ID); err != nil {
+		logger.Warn("Failed to remove domain from Caddy", zap.Error(err))
+	}
+	w.resolver.InvalidateTenant(domain.TenantID)
+	w.resolver.Invalidate(domain.Domain)
+
+	logger.Warn("Removed route for misconfigured domain",
+		zap.Duration("grace", w.cfg.MisconfiguredGrace),
+	)
+}
+
+// backoff returns the delay before the next check after attempts failures.
+// The first MaxRetries checks run every interval, then the delay doubles up to BackoffMax.
+func (w *VerificationWorker) backoff(attempts int) time.Duration {
+	delay := w.cfg.VerificationInterval
+	for i := w.cfg.MaxRetries; i < attempts; i++ {
+		delay *= 2
+		if w.cfg.BackoffMax > 0 && delay >= w.cfg.BackoffMax {
+			return w.cfg.BackoffMax
+		}
+	}
+	return delay
+}
+
+// verify runs a single DNS check bounded by the lookup timeout
+func (w *VerificationWorker) verify(ctx context.Context, domain *models.Domain) (bool, error) {
+	if w.cfg.LookupTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, w.cfg.LookupTimeout)
+		defer cancel()
+	}
+	return w.verifier.Verify(ctx, domain)
 }
 
 // VerifyNow triggers immediate verification for a specific domain
@@ -150,13 +442,15 @@ func (w *VerificationWorker) VerifyNow(ctx context.Context, domainID string) (bo
 		return false, nil
 	}
 
-	verified, err := w.verifier.Verify(ctx, domain)
+	verified, err := w.verify(ctx, domain)
 	if err != nil {
 		return false, err
 	}
 
 	if verified {
-		w.processDomain(ctx, domain)
+		w.activate(ctx, w.logger.With(zap.String("domain", domain.Domain), zap.String("domain_id", domain.ID)), domain)
+	} else if err := w.repo.RecordVerificationFailed(ctx, domain.ID, "DNS record not found"); err != nil {
+		w.logger.Warn("Failed to record verification failure", zap.String("domain", domain.Domain), zap.Error(err))
 	}
 
 	return verified, nil
@@ -0,0 +1,66 @@
+package models
+
+import (
+	"time"
+)
+
+// API key scopes
+const (
+	ScopeDomainsRead   = "domains:read"
+	ScopeDomainsWrite  = "domains:write"
+	ScopeDomainsVerify = "domains:verify"
+	ScopeSettingsRead  = "settings:read"
+	ScopeSettingsWrite = "settings:write"
+)
+
+// APIKeyScopes lists every scope an API key may be granted
+var APIKeyScopes = []string{
+	ScopeDomainsRead,
+	ScopeDomainsWrite,
+	ScopeDomainsVerify,
+	ScopeSettingsRead,
+	ScopeSettingsWrite,
+}
+
+// APIKey represents a tenant-scoped API key. The secret is only ever
+// returned once, at creation; the database stores its SHA-256 hash.
+type APIKey struct {
+	ID         string     `json:"id"`
+	TenantID   string     `json:"tenant_id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	KeyHash    string     `json:"-"`
+	Scopes     []string   `json:"scopes"`
+	CreatedBy  string     `json:"created_by,omitempty"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// Active reports whether the key is neither revoked nor expired
+func (k *APIKey) Active(now time.Time) bool {
+	if k.RevokedAt != nil {
+		return false
+	}
+	return k.ExpiresAt == nil || now.Before(*k.ExpiresAt)
+}
+
+// CreateAPIKeyRequest is the request body for creating an API key
+type CreateAPIKeyRequest struct {
+	Name      string     `json:"name"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// CreateAPIKeyResponse is the response after creating an API key
+type CreateAPIKeyResponse struct {
+	APIKey *APIKey `json:"api_key"`
+	Key    string  `json:"key"`
+}
+
+// APIKeyListResponse is the response for listing API keys
+type APIKeyListResponse struct {
+	Keys  []APIKey `json:"keys"`
+	Total int      `json:"total"`
+}
@@ -0,0 +1,79 @@
+package models
+
+import "time"
+
+// Domain contest states. A pending contest past its expiry is reported as
+// expired.
+const (
+	ContestPending   = "pending"
+	ContestWon       = "won"
+	ContestLost      = "lost"
+	ContestCancelled = "cancelled"
+	ContestExpired   = "expired"
+)
+
+// DomainContest is a tenant's challenge to another tenant's unverified claim
+// on a hostname. The challenger proves control of the domain's DNS with a
+// challenge token, which displaces the pending claim; a claim that passes
+// verification first can no longer be displaced and the contest is lost.
+type DomainContest struct {
+	ID             string     `json:"id"`
+	Domain         string     `json:"domain"`
+	TenantID       string     `json:"tenant_id"`
+	HolderTenantID string     `json:"holder_tenant_id"`
+	DomainID       string     `json:"domain_id,omitempty"`
+	Status         string     `json:"status"`
+	ChallengeToken string     `json:"-"`
+	CreatedAt      time.Time  `json:"created_at"`
+	ExpiresAt      time.Time  `json:"expires_at"`
+	ResolvedAt     *time.Time `json:"resolved_at,omitempty"`
+}
+
+// IsOpen reports whether the contest can still be won
+func (c *DomainContest) IsOpen() bool {
+	return c.Status == ContestPending
+}
+
+// CreateContestRequest is the request body for contesting a hostname
+type CreateContestRequest struct {
+	Domain string `json:"domain"`
+}
+
+// ContestResponse is a contest with the DNS challenge the challenging tenant
+// must publish, shown to the challenging tenant while the contest is open
+type ContestResponse struct {
+	Contest   *DomainContest    `json:"contest"`
+	Challenge *VerificationInfo `json:"challenge,omitempty"`
+}
+
+// ContestListResponse is the response for listing a tenant's contests
+type ContestListResponse struct {
+	Contests []DomainContest `json:"contests"`
+}
+
+// ResolveContestResponse is the response for resolving a contest. A won
+// contest carries the challenger's new domain and its routing instructions.
+type ResolveContestResponse struct {
+	Won              bool              `json:"won"`
+	Message          string            `json:"message"`
+	Domain           *Domain           `json:"domain,omitempty"`
+	VerificationInfo *VerificationInfo `json:"verification_info,omitempty"`
+}
+
+// DomainContested is the event data for domain.contested, sent to the tenant
+// holding the claim
+type DomainContested struct {
+	Domain
+	ContestID   string    `json:"contest_id"`
+	ContestedBy string    `json:"contested_by"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// DomainDisplaced is the event data for domain.displaced. The displaced tenant
+// gets its removed domain, the challenger its new one.
+type DomainDisplaced struct {
+	Domain
+	ContestID    string `json:"contest_id"`
+	FromTenantID string `json:"from_tenant_id"`
+	ToTenantID   string `json:"to_tenant_id"`
+}
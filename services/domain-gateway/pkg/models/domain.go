@@ -12,21 +12,37 @@ const (
 	DomainTypeCustom    DomainType = "custom"
 )
 
-// Domain represents a domain record in the database
+// Domain represents a domain record in the database. Status is the stored
+// lifecycle state; Verified, SSLIssued, Suspended, Archived, Deleted and
+// VerificationExpired are derived from it by SetStatus. A deleted domain is
+// in the trash until DeletedAt plus the retention period, when it is purged.
 type Domain struct {
-	ID                string     `json:"id"`
-	TenantID          string     `json:"tenant_id"`
-	Domain            string     `json:"domain"`
-	Type              DomainType `json:"type"`
-	Verified          bool       `json:"verified"`
-	VerificationToken string     `json:"verification_token,omitempty"`
-	IsPrimary         bool       `json:"is_primary"`
-	SSLIssued         bool       `json:"ssl_issued"`
-	RedirectURL       string     `json:"redirect_url,omitempty"`
-	Archived          bool       `json:"archived"`
-	CreatedAt         time.Time  `json:"created_at"`
-	UpdatedAt         time.Time  `json:"updated_at"`
-	VerifiedAt        *time.Time `json:"verified_at,omitempty"`
+	ID                   string       `json:"id"`
+	TenantID             string       `json:"tenant_id"`
+	Domain               string       `json:"domain"`
+	Type                 DomainType   `json:"type"`
+	Status               DomainStatus `json:"status"`
+	ResumeStatus         DomainStatus `json:"resume_status,omitempty"`
+	Verified             bool         `json:"verified"`
+	VerificationToken    string       `json:"verification_token,omitempty"`
+	IsPrimary            bool         `json:"is_primary"`
+	SSLIssued            bool         `json:"ssl_issued"`
+	Suspended            bool         `json:"suspended"`
+	RedirectURL          string       `json:"redirect_url,omitempty"`
+	Archived             bool         `json:"archived"`
+	Deleted              bool         `json:"deleted"`
+	DeletedAt            *time.Time   `json:"deleted_at,omitempty"`
+	CreatedAt            time.Time    `json:"created_at"`
+	UpdatedAt            time.Time    `json:"updated_at"`
+	VerifiedAt           *time.Time   `json:"verified_at,omitempty"`
+	VerificationAttempts int          `json:"verification_attempts"`
+	LastCheckedAt        *time.Time   `json:"last_checked_at,omitempty"`
+	NextCheckAt          *time.Time   `json:"next_check_at,omitempty"`
+	LastError            string       `json:"last_error,omitempty"`
+	VerificationExpired  bool         `json:"verification_expired"`
+	PendingSince         *time.Time   `json:"pending_since,omitempty"`
+	RecheckFailures      int          `json:"recheck_failures"`
+	MisconfiguredAt      *time.Time   `json:"misconfigured_at,omitempty"`
 }
 
 // CreateDomainRequest is the request body for creating a domain
@@ -56,10 +72,28 @@ type VerificationInfo struct {
 	Instructions string `json:"instructions"`
 }
 
-// DomainListResponse is the response for listing domains
+// DomainFilter selects a page of a tenant's domains, newest first. Nil
+// filters match any value; Search matches a substring of the domain name.
+// Deleted lists the trash instead of the tenant's live domains.
+type DomainFilter struct {
+	TenantID string
+	Type     DomainType
+	Status   DomainStatus
+	Verified *bool
+	Primary  *bool
+	Archived *bool
+	Deleted  bool
+	Search   string
+	Limit    int
+	Cursor   string
+}
+
+// DomainListResponse is the response for listing domains. Total counts every
+// domain matching the filters; NextCursor is empty on the last page.
 type DomainListResponse struct {
-	Domains []Domain `json:"domains"`
-	Total   int      `json:"total"`
+	Domains    []Domain `json:"domains"`
+	Total      int      `json:"total"`
+	NextCursor string   `json:"next_cursor,omitempty"`
 }
 
 // VerifyDomainResponse is the response for domain verification
@@ -75,6 +109,110 @@ type ErrorResponse struct {
 	Details string `json:"details,omitempty"`
 }
 
+// Host resolution statuses
+const (
+	ResolutionStatusActive    = "active"
+	ResolutionStatusPending   = "pending"
+	ResolutionStatusSuspended = "suspended"
+)
+
+// HostResolution maps a request host to its tenant
+type HostResolution struct {
+	Host          string `json:"host"`
+	TenantID      string `json:"tenant_id"`
+	DomainID      string `json:"domain_id"`
+	PrimaryDomain string `json:"primary_domain"`
+	Status        string `json:"status"`
+}
+
+// TenantSettings holds per-tenant gateway behaviour
+type TenantSettings struct {
+	TenantID         string            `json:"tenant_id"`
+	MaintenanceMode  bool              `json:"maintenance_mode"`
+	MaintenancePage  string            `json:"maintenance_page,omitempty"`
+	ErrorPages       map[string]string `json:"error_pages,omitempty"`
+	Suspended        bool              `json:"suspended"`
+	SuspendedAt      *time.Time        `json:"suspended_at,omitempty"`
+	SuspensionReason string            `json:"suspension_reason,omitempty"`
+	UpdatedAt        time.Time         `json:"updated_at"`
+}
+
+// GlobalSettings holds gateway-wide behaviour
+type GlobalSettings struct {
+	MaintenanceMode bool      `json:"maintenance_mode"`
+	MaintenancePage string    `json:"maintenance_page,omitempty"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// Error page status codes that can be customised
+var CustomErrorPageCodes = []string{"404", "502", "503"}
+
+// MaintenanceRequest is the request body for toggling maintenance mode
+type MaintenanceRequest struct {
+	Enabled bool   `json:"enabled"`
+	Page    string `json:"page,omitempty"`
+}
+
+// ErrorPagesRequest is the request body for setting custom error pages
+type ErrorPagesRequest struct {
+	ErrorPages map[string]string `json:"error_pages"`
+}
+
+// SuspendTenantRequest is the request body for suspending a tenant
+type SuspendTenantRequest struct {
+	Reason string `json:"reason,omitempty"`
+}
+
+// SuspendTenantResponse is the response after suspending or unsuspending a tenant
+type SuspendTenantResponse struct {
+	TenantID  string `json:"tenant_id"`
+	Suspended bool   `json:"suspended"`
+	Domains   int    `json:"domains"`
+}
+
+// ReassignDomainRequest is the request body for moving a domain to another tenant
+type ReassignDomainRequest struct {
+	TenantID string `json:"tenant_id"`
+}
+
+// ResyncResponse is the response after a full Caddy resync
+type ResyncResponse struct {
+	Routes int `json:"routes"`
+}
+
+// WorkerStatus reports the verification worker's recent activity
+type WorkerStatus struct {
+	Running       bool       `json:"running"`
+	Active        bool       `json:"active"`
+	Interval      string     `json:"interval"`
+	Cycles        int64      `json:"cycles"`
+	LastRunAt     *time.Time `json:"last_run_at,omitempty"`
+	LastDuration  string     `json:"last_duration,omitempty"`
+	LastPending   int        `json:"last_pending"`
+	LastVerified  int        `json:"last_verified"`
+	LastRechecked int        `json:"last_rechecked"`
+	LastError     string     `json:"last_error,omitempty"`
+}
+
+// AccessAuditEntry records a request made by platform staff on behalf of a tenant
+type AccessAuditEntry struct {
+	ID           string    `json:"id"`
+	UserID       string    `json:"user_id"`
+	Role         string    `json:"role"`
+	HomeTenantID string    `json:"home_tenant_id,omitempty"`
+	TenantID     string    `json:"tenant_id"`
+	Method       string    `json:"method"`
+	Path         string    `json:"path"`
+	Status       int       `json:"status"` // 0 until the request completes
+	RemoteAddr   string    `json:"remote_addr,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// AccessAuditListResponse is the response for listing access audit entries
+type AccessAuditListResponse struct {
+	Entries []AccessAuditEntry `json:"entries"`
+}
+
 // ProxyTarget represents a backend target for proxying
 type ProxyTarget struct {
 	TenantID string `json:"tenant_id"`
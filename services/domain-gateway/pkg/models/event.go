@@ -0,0 +1,70 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Domain event actions
+const (
+	EventDomainCreated             = "domain.created"
+	EventDomainDeleted             = "domain.deleted"
+	EventDomainRestored            = "domain.restored"
+	EventDomainPurged              = "domain.purged"
+	EventDomainUpdated             = "domain.updated"
+	EventDomainVerified            = "domain.verified"
+	EventDomainVerificationFailed  = "domain.verification_failed"
+	EventDomainVerificationExpired = "domain.verification_expired"
+	EventDomainVerificationStarted = "domain.verification_started"
+	EventDomainMisconfigured       = "domain.misconfigured"
+	EventDomainRecovered           = "domain.recovered"
+	EventDomainUnverified          = "domain.unverified"
+	EventDomainPrimarySet          = "domain.primary_set"
+	EventDomainPrimaryUnset        = "domain.primary_unset"
+	EventDomainReassigned          = "domain.reassigned"
+	EventDomainTransferred         = "domain.transferred"
+	EventDomainTransferCancelled   = "domain.transfer_cancelled"
+	EventDomainContested           = "domain.contested"
+	EventDomainDisplaced           = "domain.displaced"
+	EventDomainSuspended           = "domain.suspended"
+	EventDomainUnsuspended         = "domain.unsuspended"
+	EventCertificateIssued         = "certificate.issued"
+	EventCertificateExpiring       = "certificate.expiring"
+	EventCertificateFailed         = "certificate.failed"
+)
+
+// ActorType identifies who made a change
+type ActorType string
+
+const (
+	ActorUser   ActorType = "user"
+	ActorAPIKey ActorType = "api_key"
+	ActorWorker ActorType = "worker"
+	ActorSystem ActorType = "system"
+)
+
+// Actor is the principal responsible for a change
+type Actor struct {
+	Type ActorType `json:"type"`
+	ID   string    `json:"id,omitempty"`
+}
+
+// DomainEvent is an audit record of a domain mutation
+type DomainEvent struct {
+	ID        string          `json:"id"`
+	DomainID  string          `json:"domain_id"`
+	TenantID  string          `json:"tenant_id"`
+	Domain    string          `json:"domain"`
+	Action    string          `json:"action"`
+	Actor     Actor           `json:"actor"`
+	RequestID string          `json:"request_id,omitempty"`
+	Before    json.RawMessage `json:"before,omitempty"`
+	After     json.RawMessage `json:"after,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// DomainEventListResponse is the response for listing domain events
+type DomainEventListResponse struct {
+	Events []DomainEvent `json:"events"`
+	Total  int           `json:"total"`
+}
@@ -0,0 +1,132 @@
+package models
+
+// DomainStatus is a domain's lifecycle state
+type DomainStatus string
+
+const (
+	StatusPendingDNS          DomainStatus = "pending_dns"
+	StatusVerifying           DomainStatus = "verifying"
+	StatusVerificationExpired DomainStatus = "verification_expired"
+	StatusCertPending         DomainStatus = "cert_pending"
+	StatusCertFailed          DomainStatus = "cert_failed"
+	StatusActive              DomainStatus = "active"
+	StatusMisconfigured       DomainStatus = "misconfigured"
+	StatusSuspended           DomainStatus = "suspended"
+	StatusArchived            DomainStatus = "archived"
+	StatusDeleted             DomainStatus = "deleted"
+)
+
+// VerifiedStatuses are the states of a domain that has passed DNS verification
+var VerifiedStatuses = []DomainStatus{
+	StatusCertPending,
+	StatusCertFailed,
+	StatusActive,
+	StatusMisconfigured,
+}
+
+// statusTransitions lists the legal moves between unheld states. Any state
+// may also be held by suspension, archiving or deletion.
+var statusTransitions = map[DomainStatus][]DomainStatus{
+	StatusPendingDNS:          {StatusVerifying, StatusVerificationExpired, StatusCertPending},
+	StatusVerifying:           {StatusPendingDNS, StatusVerificationExpired, StatusCertPending},
+	StatusVerificationExpired: {StatusPendingDNS, StatusCertPending},
+	StatusCertPending:         {StatusActive, StatusCertFailed, StatusMisconfigured, StatusPendingDNS},
+	StatusCertFailed:          {StatusActive, StatusMisconfigured, StatusPendingDNS},
+	StatusActive:              {StatusCertFailed, StatusMisconfigured, StatusPendingDNS},
+	StatusMisconfigured:       {StatusActive, StatusPendingDNS},
+}
+
+// IsKnown reports whether s is one of the defined statuses
+func (s DomainStatus) IsKnown() bool {
+	switch s {
+	case StatusPendingDNS, StatusVerifying, StatusVerificationExpired, StatusCertPending, StatusCertFailed,
+		StatusActive, StatusMisconfigured, StatusSuspended, StatusArchived, StatusDeleted:
+		return true
+	}
+	return false
+}
+
+// IsHeld reports whether s parks a domain in another state
+func (s DomainStatus) IsHeld() bool {
+	return s == StatusSuspended || s == StatusArchived || s == StatusDeleted
+}
+
+// IsVerified reports whether s is past DNS verification
+func (s DomainStatus) IsVerified() bool {
+	for _, v := range VerifiedStatuses {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
+
+// CanTransition reports whether an unheld domain may move from s to to.
+// Any state may be held; Domain.CanTransition covers leaving a hold.
+func (s DomainStatus) CanTransition(to DomainStatus) bool {
+	if to.IsHeld() {
+		return s != to
+	}
+	for _, next := range statusTransitions[s] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// CanTransition reports whether d may move to status. A held domain may only
+// return to the state it was held in, or move to another hold. A deleted
+// domain is only restored, suspended if its tenant is.
+func (d *Domain) CanTransition(to DomainStatus) bool {
+	if d.Status == StatusDeleted && to.IsHeld() {
+		return to == StatusSuspended
+	}
+	if d.Status.IsHeld() && !to.IsHeld() {
+		return to == d.ResumeTarget()
+	}
+	return d.Status.CanTransition(to)
+}
+
+// ResumeTarget is the state a held domain returns to. A check that was in
+// flight when the domain was held starts over.
+func (d *Domain) ResumeTarget() DomainStatus {
+	switch d.ResumeStatus {
+	case "", StatusVerifying:
+		return StatusPendingDNS
+	}
+	return d.ResumeStatus
+}
+
+// SetStatus moves d to status and refreshes the flags derived from it.
+// resume is the state a held domain returns to.
+func (d *Domain) SetStatus(status, resume DomainStatus) {
+	d.Status = status
+	d.ResumeStatus = ""
+	if status.IsHeld() {
+		d.ResumeStatus = resume
+	}
+
+	effective := d.EffectiveStatus()
+	d.Verified = effective.IsVerified() && status != StatusArchived && status != StatusDeleted
+	d.SSLIssued = effective == StatusActive || effective == StatusMisconfigured
+	d.Suspended = status == StatusSuspended
+	d.Archived = status == StatusArchived
+	d.Deleted = status == StatusDeleted
+	d.VerificationExpired = effective == StatusVerificationExpired
+}
+
+// EffectiveStatus is the state a held domain resumes, or its status otherwise
+func (d *Domain) EffectiveStatus() DomainStatus {
+	if d.Status.IsHeld() && d.ResumeStatus != "" {
+		return d.ResumeStatus
+	}
+	return d.Status
+}
+
+// Contestable reports whether another tenant may displace d's claim by
+// proving control of its DNS. Subdomains of the base domain and domains that
+// have passed verification, even while held, are locked to their tenant.
+func (d *Domain) Contestable() bool {
+	return d.Type == DomainTypeCustom && !d.EffectiveStatus().IsVerified()
+}
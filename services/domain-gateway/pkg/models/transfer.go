@@ -0,0 +1,82 @@
+package models
+
+import "time"
+
+// Domain transfer states. A pending or accepted transfer past its expiry is
+// reported as expired.
+const (
+	TransferPending   = "pending"
+	TransferAccepted  = "accepted"
+	TransferCompleted = "completed"
+	TransferCancelled = "cancelled"
+	TransferExpired   = "expired"
+)
+
+// DomainTransfer moves a domain from one tenant to another. The owner
+// initiates it, the receiving tenant accepts it and then proves control of the
+// domain's DNS with a fresh challenge token, which completes it.
+type DomainTransfer struct {
+	ID             string     `json:"id"`
+	DomainID       string     `json:"domain_id"`
+	Domain         string     `json:"domain"`
+	FromTenantID   string     `json:"from_tenant_id"`
+	ToTenantID     string     `json:"to_tenant_id"`
+	Status         string     `json:"status"`
+	ChallengeToken string     `json:"-"`
+	CreatedAt      time.Time  `json:"created_at"`
+	ExpiresAt      time.Time  `json:"expires_at"`
+	AcceptedAt     *time.Time `json:"accepted_at,omitempty"`
+	CompletedAt    *time.Time `json:"completed_at,omitempty"`
+	CancelledAt    *time.Time `json:"cancelled_at,omitempty"`
+}
+
+// IsOpen reports whether the transfer can still be accepted or completed
+func (t *DomainTransfer) IsOpen() bool {
+	return t.Status == TransferPending || t.Status == TransferAccepted
+}
+
+// CreateTransferRequest is the request body for initiating a domain transfer
+type CreateTransferRequest struct {
+	TenantID string `json:"tenant_id"`
+}
+
+// TransferResponse is a transfer with the DNS challenge the receiving tenant
+// must publish, shown to the receiving tenant once it has accepted
+type TransferResponse struct {
+	Transfer  *DomainTransfer   `json:"transfer"`
+	Challenge *VerificationInfo `json:"challenge,omitempty"`
+}
+
+// TransferListResponse is the response for listing a tenant's transfers
+type TransferListResponse struct {
+	Transfers []DomainTransfer `json:"transfers"`
+}
+
+// CompleteTransferResponse is the response for completing a transfer
+type CompleteTransferResponse struct {
+	Completed bool    `json:"completed"`
+	Message   string  `json:"message"`
+	Domain    *Domain `json:"domain,omitempty"`
+}
+
+// DomainTransferred is the event data for domain.transferred
+type DomainTransferred struct {
+	Domain
+	TransferID   string `json:"transfer_id"`
+	FromTenantID string `json:"from_tenant_id"`
+	ToTenantID   string `json:"to_tenant_id"`
+}
+
+// Reasons an open transfer is cancelled without either tenant asking
+const (
+	TransferCancelledDisplaced = "displaced"
+)
+
+// DomainTransferCancelled is the event data for domain.transfer_cancelled
+type DomainTransferCancelled struct {
+	Domain
+	TransferID   string `json:"transfer_id"`
+	FromTenantID string `json:"from_tenant_id"`
+	ToTenantID   string `json:"to_tenant_id"`
+	Reason       string `json:"reason"`
+}
@@ -0,0 +1,137 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// WebhookEventTypes lists the domain events delivered to webhook endpoints
+var WebhookEventTypes = []string{
+	EventDomainCreated,
+	EventDomainVerified,
+	EventDomainVerificationFailed,
+	EventDomainVerificationExpired,
+	EventDomainMisconfigured,
+	EventDomainRecovered,
+	EventCertificateIssued,
+	EventCertificateExpiring,
+	EventCertificateFailed,
+	EventDomainDeleted,
+	EventDomainRestored,
+	EventDomainPurged,
+	EventDomainTransferred,
+	EventDomainTransferCancelled,
+	EventDomainContested,
+	EventDomainDisplaced,
+}
+
+// IsWebhookEvent reports whether a domain event is delivered to webhooks
+func IsWebhookEvent(action string) bool {
+	for _, t := range WebhookEventTypes {
+		if t == action {
+			return true
+		}
+	}
+	return false
+}
+
+// Webhook delivery states
+const (
+	DeliveryPending   = "pending"
+	DeliverySucceeded = "succeeded"
+	DeliveryFailed    = "failed"
+)
+
+// WebhookEndpoint is a tenant URL that receives signed event notifications.
+// An empty Events list subscribes to every event type.
+type WebhookEndpoint struct {
+	ID        string    `json:"id"`
+	TenantID  string    `json:"tenant_id"`
+	URL       string    `json:"url"`
+	Secret    string    `json:"-"`
+	Events    []string  `json:"events"`
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Subscribed reports whether the endpoint receives an event type
+func (e *WebhookEndpoint) Subscribed(eventType string) bool {
+	if len(e.Events) == 0 {
+		return true
+	}
+	for _, t := range e.Events {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookEvent is the JSON body posted to webhook endpoints
+type WebhookEvent struct {
+	ID        string          `json:"id"`
+	Type      string          `json:"type"`
+	TenantID  string          `json:"tenant_id"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// WebhookDelivery is one attempt series to deliver an event to an endpoint
+type WebhookDelivery struct {
+	ID             string     `json:"id"`
+	EndpointID     string     `json:"endpoint_id"`
+	TenantID       string     `json:"tenant_id"`
+	EventID        string     `json:"event_id"`
+	EventType      string     `json:"event_type"`
+	Payload        string     `json:"-"`
+	Status         string     `json:"status"`
+	Attempts       int        `json:"attempts"`
+	NextAttemptAt  *time.Time `json:"next_attempt_at,omitempty"`
+	LastStatusCode int        `json:"last_status_code,omitempty"`
+	LastError      string     `json:"last_error,omitempty"`
+	ReplayOf       string     `json:"replay_of,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+}
+
+// VerificationFailure is the event data for domain.verification_failed
+type VerificationFailure struct {
+	Domain
+	Reason string `json:"reason"`
+}
+
+// CertificateFailure is the event data for certificate.failed
+type CertificateFailure struct {
+	Domain
+	Reason string `json:"reason"`
+}
+
+// CertificateExpiry is the event data for certificate.expiring
+type CertificateExpiry struct {
+	Domain
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// CreateWebhookRequest is the request body for registering a webhook endpoint
+type CreateWebhookRequest struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+}
+
+// CreateWebhookResponse returns the new endpoint with its signing secret, shown only once
+type CreateWebhookResponse struct {
+	*WebhookEndpoint
+	Secret string `json:"secret"`
+}
+
+// WebhookListResponse is the response for listing webhook endpoints
+type WebhookListResponse struct {
+	Webhooks []WebhookEndpoint `json:"webhooks"`
+}
+
+// WebhookDeliveryListResponse is the response for listing webhook deliveries
+type WebhookDeliveryListResponse struct {
+	Deliveries []WebhookDelivery `json:"deliveries"`
+	Total      int               `json:"total"`
+}